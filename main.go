@@ -1,156 +1,345 @@
 package main
 
 import (
+	"bytes"
+	"chogopy/src/ast"
 	"chogopy/src/backend"
-	"chogopy/src/codegen"
-	"chogopy/src/lexer"
-	"chogopy/src/parser"
-	"chogopy/src/scopes"
-	"chogopy/src/typechecks"
-	"log"
+	"chogopy/src/bytecode"
+	"chogopy/src/compiler"
+	"chogopy/src/diagnostics"
+	"chogopy/src/repl"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/kr/pretty"
-	"tinygo.org/x/go-llvm"
 )
 
+// Exit codes of the compiler
+const (
+	exitOK = iota
+	// exitCompileError is used when errors were found in the source code.
+	exitCompileError
+	// exitUsage is used when the command line arguments are invalid.
+	exitUsage
+	// exitFailure is used when reading the input, writing the output or linking failed.
+	exitFailure
+)
+
+// emitStages maps every output kind that can be emitted to the last stage that is needed to produce it.
+var emitStages = map[string]compiler.Stage{
+	"tokens":    compiler.StageLex,
+	"ast":       compiler.StageParse,
+	"typed-ast": compiler.StageTypes,
+	"chb":       compiler.StageTypes,
+	"llvm-ir":   compiler.StageCodegen,
+	"c":         compiler.StageCodegen,
+	"asm":       compiler.StageBackend,
+	"bc":        compiler.StageBackend,
+	"obj":       compiler.StageBackend,
+	"exe":       compiler.StageBackend,
+}
+
 func main() {
-	filePath := ""
+	arguments := os.Args[1:]
 
-	if len(os.Args) > 1 {
-		filePath = os.Args[len(os.Args)-1]
-	} else {
-		log.Fatal("Please provide a file path.")
+	if len(arguments) == 1 && arguments[0] == "repl" {
+		repl.New(os.Stdin, os.Stdout, os.Stderr).Run()
+		os.Exit(exitOK)
 	}
 
-	llFilePath := replaceFileEnding(filePath, "ll")
-	objectFilePath := replaceFileEnding(filePath, "o")
+	// The run, interp and vm commands execute the program right away instead of emitting any output
+	command := ""
+	if len(arguments) > 0 && (arguments[0] == "run" || arguments[0] == "interp" || arguments[0] == "vm") {
+		command = arguments[0]
+		arguments = arguments[1:]
+	}
 
-	byteStream, err := os.ReadFile(filePath)
+	flags := flag.NewFlagSet("cgp", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: cgp [flags] <file.choc | ->")
+		fmt.Fprintln(flags.Output(), "       cgp run [flags] <file.choc | ->")
+		fmt.Fprintln(flags.Output(), "       cgp interp <file.choc | ->")
+		fmt.Fprintln(flags.Output(), "       cgp vm <file.choc | file.chb | ->")
+		fmt.Fprintln(flags.Output(), "       cgp repl")
+		flags.PrintDefaults()
+	}
+
+	outputPath := flags.String("o", "", "write the output to `path` (- for stdout)")
+	emit := flags.String("emit", "exe", "the `kind` of output to emit: tokens, ast, typed-ast, chb, llvm-ir, c, asm, bc, obj or exe")
+	backendName := flags.String("backend", "llvm", "the `backend` that generates code: llvm or c, which compiles C source code with $CC or cc")
+	linkerName := flags.String("linker", envOr("CGP_LINKER", "cc"), "the `linker` that links executables: cc, clang or ld.lld, also read from $CGP_LINKER")
+	ldflags := flags.String("ldflags", os.Getenv("CGP_LDFLAGS"), "extra `flags` that are passed to the linker, also read from $CGP_LDFLAGS")
+	libraries := []string{}
+	flags.Func("l", "link against the given `library`, can be repeated", func(library string) error {
+		libraries = append(libraries, library)
+		return nil
+	})
+	static := flags.Bool("static", false, "link a static executable")
+	pie := flags.Bool("pie", false, "link a position independent executable")
+	target := backend.Target{}
+	flags.StringVar(&target.Triple, "target", "", "generate code for the given target `triple` (default the host)")
+	flags.StringVar(&target.CPU, "cpu", "", "generate code for the given target `cpu`")
+	flags.StringVar(&target.Features, "features", "", "enable or disable the given comma-separated target `features`, e.g. +neon,-crypto")
+	optLevel := 3
+	for level := range 4 {
+		flags.BoolFunc(fmt.Sprintf("O%d", level), fmt.Sprintf("optimize at level %d", level), func(string) error {
+			optLevel = level
+			return nil
+		})
+	}
+
+	if err := flags.Parse(arguments); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(exitOK)
+		}
+		os.Exit(exitUsage)
+	}
+
+	stage, ok := emitStages[*emit]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown output kind '%s'.\n", *emit)
+		flags.Usage()
+		os.Exit(exitUsage)
+	}
+	codeBackend, err := newBackend(*backendName, *emit)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitUsage)
+	}
+	linker, err := newLinker(*linkerName, *ldflags, libraries, *static, *pie)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitUsage)
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(exitUsage)
+	}
+	filePath := flags.Arg(0)
+
+	source, err := readSource(filePath)
 	if err != nil {
-		pretty.Println(err.Error())
-	}
-	stream := string(byteStream)
-
-	myLexer := lexer.NewLexer(stream)
-	myParser := parser.NewParser(&myLexer)
-
-	if len(os.Args) > 2 {
-		switch os.Args[1] {
-		case "-l":
-			token := myLexer.Consume(false)
-			pretty.Println(token)
-			for token.Kind != lexer.EOF {
-				token = myLexer.Consume(false)
-				pretty.Println(token)
-			}
-		case "-p":
-			program := myParser.ParseProgram()
-			pretty.Println(program)
-		case "-t":
-			program := myParser.ParseProgram()
-			staticTyping := typechecks.StaticTyping{}
-			staticTyping.Analyze(&program)
-		case "-n":
-			program := myParser.ParseProgram()
-			assignTargets := scopes.AssignTargets{}
-			assignTargets.Analyze(&program)
-			scopes := scopes.NameScopes{}
-			scopes.Analyze(&program)
-		case "-c":
-			program := myParser.ParseProgram()
-			assignTargets := scopes.AssignTargets{}
-			assignTargets.Analyze(&program)
-			nameScopes := scopes.NameScopes{}
-			nameScopes.Analyze(&program)
-			staticTyping := typechecks.StaticTyping{}
-			staticTyping.Analyze(&program)
-			codeGenerator := codegen.CodeGenerator{}
-			codeGenerator.Generate(&program)
-
-			// TODO: To keep the test cases working I am only appending .ll to the filePath
-			// here but will have to change that in the future and modify the test cases accordingly.
-			err := os.WriteFile(
-				replaceFileEnding(filePath, "ll"),
-				[]byte(codeGenerator.Module.String()),
-				0o644,
-			)
-			if err != nil {
-				panic(err)
-			}
+		fmt.Fprintln(os.Stderr, "Failed to read the source code:", err)
+		os.Exit(exitFailure)
+	}
+
+	options := compiler.DefaultOptions()
+	options.StopAfter = stage
+	options.OptLevel = optLevel
+	options.Target = target
+	options.Backend = codeBackend
+	switch *emit {
+	case "asm":
+		options.Output = compiler.OutputAssembly
+	case "bc":
+		options.Output = compiler.OutputBitcode
+	}
+
+	switch command {
+	case "run":
+		exitCode, diags := compiler.Run(source, options)
+		exitOnErrors(diags, source)
+		os.Exit(exitCode)
+	case "interp":
+		exitCode, diags := compiler.Interpret(source, os.Stdin, os.Stdout, os.Stderr)
+		exitOnErrors(diags, source)
+		os.Exit(exitCode)
+	case "vm":
+		os.Exit(runBytecode(source))
+	}
+
+	result, diags := compiler.Compile(source, options)
+	exitOnErrors(diags, source)
+
+	if *outputPath == "" {
+		*outputPath = defaultOutputPath(filePath, *emit)
+	}
+	if *outputPath == "-" && *emit == "exe" {
+		fmt.Fprintln(os.Stderr, "Executables cannot be written to stdout.")
+		os.Exit(exitUsage)
+	}
+
+	switch *emit {
+	case "tokens":
+		tokens := strings.Builder{}
+		for _, token := range result.Tokens {
+			tokens.WriteString(pretty.Sprint(token) + "\n")
 		}
-	} else {
-		program := myParser.ParseProgram()
-		assignTargets := scopes.AssignTargets{}
-		assignTargets.Analyze(&program)
-		nameScopes := scopes.NameScopes{}
-		nameScopes.Analyze(&program)
-		staticTyping := typechecks.StaticTyping{}
-		staticTyping.Analyze(&program)
-		codeGenerator := codegen.CodeGenerator{}
-		codeGenerator.Generate(&program)
-
-		err := os.WriteFile(
-			llFilePath,
-			[]byte(codeGenerator.Module.String()),
-			0o644,
-		)
-		if err != nil {
-			log.Fatalln("Failed to create llvm IR file: ", err)
+		err = writeOutput(*outputPath, []byte(tokens.String()))
+	case "ast", "typed-ast":
+		err = writeOutput(*outputPath, []byte(pretty.Sprint(*result.Program)+"\n"))
+	case "chb":
+		err = writeBytecode(*outputPath, result.Program)
+	case "llvm-ir":
+		err = writeOutput(*outputPath, []byte(result.IR))
+	case "c":
+		err = writeOutput(*outputPath, []byte(result.C))
+	case "asm", "bc", "obj":
+		err = writeOutput(*outputPath, result.Output)
+	case "exe":
+		linkDiags := compiler.Link(result.Output, *outputPath, linker)
+		if diagnostics.ErrorCount(linkDiags) > 0 {
+			diagnostics.Print(os.Stderr, linkDiags, source)
+			os.Exit(exitFailure)
 		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitFailure)
+	}
+}
 
-		backend.Init()
-		llvmContext := backend.NewllvmContext()
-		defer llvmContext.Dispose()
+// readSource reads the source code from the given file or from stdin if the path is -.
+func readSource(filePath string) (string, error) {
+	if filePath == "-" {
+		byteStream, err := io.ReadAll(os.Stdin)
+		return string(byteStream), err
+	}
+	byteStream, err := os.ReadFile(filePath)
+	return string(byteStream), err
+}
 
-		module := llvmContext.ParseIRFromFile(llFilePath)
-		os.Remove(llFilePath)
+// defaultOutputPath returns where the given kind of output is written if no path has been specified.
+// Tokens, ASTs, LLVM IR and C source code go to stdout, while bytecode and the output of the backend are placed next to the source code.
+func defaultOutputPath(filePath string, emit string) string {
+	if filePath == "-" {
+		filePath = "a.choc"
+	}
 
-		// TODO: This breaks some of the modules which are not entirely correct yet
-		// (For instance, modules in which functions allocate strings or lists on their call stack
-		// and then return pointers to the now unallocated memory. This still happens when input() or
-		// list/string concatenation is used and the resulting value is returned and then used by the caller.)
-		llvmContext.OptimizeModule(module)
+	switch emit {
+	case "asm":
+		return replaceFileEnding(filePath, "s")
+	case "bc":
+		return replaceFileEnding(filePath, "bc")
+	case "chb":
+		return replaceFileEnding(filePath, "chb")
+	case "obj":
+		return replaceFileEnding(filePath, "o")
+	case "exe":
+		return replaceFileEnding(filePath, "")
+	}
+	return "-"
+}
 
-		objectFile, err := os.Create(objectFilePath)
-		if err != nil {
-			log.Fatalln("Failed to create object file: ", err)
-		}
+// writeOutput writes the output to the given file or to stdout if the path is -.
+func writeOutput(outputPath string, output []byte) error {
+	if outputPath == "-" {
+		_, err := os.Stdout.Write(output)
+		return err
+	}
+	err := os.WriteFile(outputPath, output, 0o644)
+	if err != nil {
+		return fmt.Errorf("Failed to write the output file: %w", err)
+	}
+	return nil
+}
 
-		_, err = llvmContext.CompileModule(module, llvm.CodeGenFileType(llvm.ObjectFile), objectFile)
+// writeBytecode compiles the typed AST into bytecode and writes it to the given file in the .chb format.
+func writeBytecode(outputPath string, program *ast.Program) error {
+	compiled, err := bytecode.Compile(program)
+	if err != nil {
+		return err
+	}
+	encoded := bytes.Buffer{}
+	if err := compiled.Encode(&encoded); err != nil {
+		return err
+	}
+	return writeOutput(outputPath, encoded.Bytes())
+}
+
+// runBytecode executes a program on the virtual machine and returns its exit code.
+// The input is either a .chb file or source code, which is compiled into bytecode first.
+func runBytecode(input string) int {
+	var program *bytecode.Program
+	if strings.HasPrefix(input, bytecode.Magic) {
+		decoded, err := bytecode.Decode(strings.NewReader(input))
 		if err != nil {
-			log.Fatalln("Failed to compile module: ", err)
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
 		}
+		program = decoded
+	} else {
+		compiled, diags := compiler.CompileBytecode(input)
+		exitOnErrors(diags, input)
+		program = compiled
+	}
 
-		fileName := filepath.Base(filePath)
-		outputFile := replaceFileEnding(fileName, "")
+	exitCode, err := compiler.RunBytecode(program, os.Stdin, os.Stdout, os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	return exitCode
+}
 
-		linkerCmd := exec.Command("gcc", "-v", "-Wall", "-Wextra", "-Wwrite-strings", "-g3", "-o"+outputFile, objectFilePath)
-		_, err = linkerCmd.CombinedOutput()
-		if err != nil {
-			log.Fatalln("Failed to link object file: ", err)
+// newBackend returns the backend with the given name and checks that it can emit the given kind of output.
+func newBackend(name string, emit string) (compiler.Backend, error) {
+	switch name {
+	case "llvm":
+		if emit == "c" {
+			return compiler.BackendLLVM, errors.New("C source code can only be emitted by the C backend.")
 		}
+		return compiler.BackendLLVM, nil
+	case "c":
+		if emit == "llvm-ir" || emit == "asm" || emit == "bc" {
+			return compiler.BackendC, fmt.Errorf("Output kind '%s' can only be emitted by the LLVM backend.", emit)
+		}
+		return compiler.BackendC, nil
+	}
+	return compiler.BackendLLVM, fmt.Errorf("Unknown backend '%s', expected llvm or c.", name)
+}
+
+// newLinker creates the linker that is described by the command line flags.
+func newLinker(name string, ldflags string, libraries []string, static bool, pie bool) (backend.Linker, error) {
+	linker, err := backend.NewLinker(name)
+	if err != nil {
+		return linker, err
+	}
+	if static && pie {
+		return linker, errors.New("An executable cannot be both static and position independent.")
+	}
 
-		// TODO: The below should be specifiable with an argument.
-		// Move the output file into the same directory as the source code
-		outputPath := filepath.Join(filepath.Dir(filePath), outputFile)
-		os.Rename(outputFile, outputPath)
+	linker.Flags = strings.Fields(ldflags)
+	linker.Libraries = libraries
+	if static {
+		linker.Mode = backend.LinkStatic
+	}
+	if pie {
+		linker.Mode = backend.LinkPIE
+	}
+	return linker, nil
+}
 
-		os.Remove(objectFilePath)
+// envOr returns the value of the given environment variable or the fallback if it is not set.
+func envOr(key string, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
 	}
+	return fallback
+}
+
+// exitOnErrors prints the given diagnostics and exits with
+// a non-zero exit code if any of them is an error.
+func exitOnErrors(diags []diagnostics.Diagnostic, source string) {
+	errorCount := diagnostics.ErrorCount(diags)
+	if errorCount == 0 {
+		return
+	}
+	diagnostics.Print(os.Stderr, diags, source)
+	fmt.Fprintf(os.Stderr, "%d error(s) found.\n", errorCount)
+	os.Exit(exitCompileError)
 }
 
 func replaceFileEnding(filePath string, newEnding string) string {
-	dotSplit := strings.Split(filePath, ".")
+	withoutEnding := strings.TrimSuffix(filePath, filepath.Ext(filePath))
 
 	if newEnding == "" {
-		dotSplit = dotSplit[:len(dotSplit)-1]
-	} else {
-		dotSplit[len(dotSplit)-1] = newEnding
+		return withoutEnding
 	}
-
-	return strings.Join(dotSplit, ".")
+	return withoutEnding + "." + newEnding
 }
@@ -25,6 +25,10 @@ type ListAttribute struct {
 	ElemType TypeAttr
 }
 
+type ClassAttribute struct {
+	ClassName string
+}
+
 func (ba BasicAttribute) String() string {
 	switch ba {
 	case Integer:
@@ -46,3 +50,34 @@ func (ba BasicAttribute) String() string {
 func (la ListAttribute) String() string {
 	return fmt.Sprintf("List[%s]", la.ElemType.String())
 }
+
+func (ca ClassAttribute) String() string {
+	return ca.ClassName
+}
+
+// TypeHintOf returns the type hint of the given expression node or nil if the node is not an expression.
+func TypeHintOf(node Node) TypeAttr {
+	switch node := node.(type) {
+	case *LiteralExpr:
+		return node.TypeHint
+	case *IdentExpr:
+		return node.TypeHint
+	case *UnaryExpr:
+		return node.TypeHint
+	case *BinaryExpr:
+		return node.TypeHint
+	case *IfExpr:
+		return node.TypeHint
+	case *ListExpr:
+		return node.TypeHint
+	case *CallExpr:
+		return node.TypeHint
+	case *IndexExpr:
+		return node.TypeHint
+	case *MemberExpr:
+		return node.TypeHint
+	case *MethodCallExpr:
+		return node.TypeHint
+	}
+	return nil
+}
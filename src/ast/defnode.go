@@ -1,7 +1,43 @@
 package ast
 
+import "chogopy/src/diagnostics"
+
+type ClassDef struct {
+	name       string
+	span       diagnostics.Span
+	ClassName  string
+	SuperClass string
+	ClassBody  []Node
+	Node
+}
+
+func (cd *ClassDef) Name() string {
+	if cd.name == "" {
+		cd.name = "ClassDef"
+	}
+	return cd.name
+}
+
+func (cd *ClassDef) Span() diagnostics.Span {
+	return cd.span
+}
+
+func (cd *ClassDef) SetSpan(span diagnostics.Span) {
+	cd.span = span
+}
+
+func (cd *ClassDef) Visit(v Visitor) {
+	v.VisitClassDef(cd)
+	if v.Traverse() {
+		for _, bodyNode := range cd.ClassBody {
+			bodyNode.Visit(v)
+		}
+	}
+}
+
 type FuncDef struct {
 	name       string
+	span       diagnostics.Span
 	FuncName   string
 	Parameters []Node
 	FuncBody   []Node
@@ -16,6 +52,14 @@ func (fd *FuncDef) Name() string {
 	return fd.name
 }
 
+func (fd *FuncDef) Span() diagnostics.Span {
+	return fd.span
+}
+
+func (fd *FuncDef) SetSpan(span diagnostics.Span) {
+	fd.span = span
+}
+
 func (fd *FuncDef) Visit(v Visitor) {
 	v.VisitFuncDef(fd)
 	if v.Traverse() {
@@ -31,6 +75,7 @@ func (fd *FuncDef) Visit(v Visitor) {
 
 type TypedVar struct {
 	name    string
+	span    diagnostics.Span
 	VarName string
 	VarType Node
 	Node
@@ -43,6 +88,14 @@ func (tv *TypedVar) Name() string {
 	return tv.name
 }
 
+func (tv *TypedVar) Span() diagnostics.Span {
+	return tv.span
+}
+
+func (tv *TypedVar) SetSpan(span diagnostics.Span) {
+	tv.span = span
+}
+
 func (tv *TypedVar) Visit(v Visitor) {
 	v.VisitTypedVar(tv)
 	if v.Traverse() {
@@ -52,6 +105,7 @@ func (tv *TypedVar) Visit(v Visitor) {
 
 type VarDef struct {
 	name     string
+	span     diagnostics.Span
 	TypedVar Node
 	Literal  Node
 	Node
@@ -64,6 +118,14 @@ func (vd *VarDef) Name() string {
 	return vd.name
 }
 
+func (vd *VarDef) Span() diagnostics.Span {
+	return vd.span
+}
+
+func (vd *VarDef) SetSpan(span diagnostics.Span) {
+	vd.span = span
+}
+
 func (vd *VarDef) Visit(v Visitor) {
 	v.VisitVarDef(vd)
 	if v.Traverse() {
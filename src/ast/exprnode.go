@@ -1,11 +1,14 @@
 package ast
 
+import "chogopy/src/diagnostics"
+
 // TODO: We are currently simply converting Attributes to their string representation
 // and setting this as the TypeHint but it may be better to set the Attribute directly and
 // convert it to its string representation only when needed.
 
 type LiteralExpr struct {
 	name     string
+	span     diagnostics.Span
 	TypeHint TypeAttr
 	Value    any
 	Node
@@ -18,12 +21,21 @@ func (le *LiteralExpr) Name() string {
 	return le.name
 }
 
+func (le *LiteralExpr) Span() diagnostics.Span {
+	return le.span
+}
+
+func (le *LiteralExpr) SetSpan(span diagnostics.Span) {
+	le.span = span
+}
+
 func (le *LiteralExpr) Visit(v Visitor) {
 	v.VisitLiteralExpr(le)
 }
 
 type IdentExpr struct {
 	name       string
+	span       diagnostics.Span
 	TypeHint   TypeAttr
 	Identifier string
 	Node
@@ -36,6 +48,14 @@ func (ie *IdentExpr) Name() string {
 	return ie.name
 }
 
+func (ie *IdentExpr) Span() diagnostics.Span {
+	return ie.span
+}
+
+func (ie *IdentExpr) SetSpan(span diagnostics.Span) {
+	ie.span = span
+}
+
 func (ie *IdentExpr) Visit(v Visitor) {
 	v.VisitIdentExpr(ie)
 	// We do not want to visit the type hint as it does not
@@ -44,6 +64,7 @@ func (ie *IdentExpr) Visit(v Visitor) {
 
 type UnaryExpr struct {
 	name     string
+	span     diagnostics.Span
 	TypeHint TypeAttr
 	Op       string
 	Value    Node
@@ -57,6 +78,14 @@ func (ue *UnaryExpr) Name() string {
 	return ue.name
 }
 
+func (ue *UnaryExpr) Span() diagnostics.Span {
+	return ue.span
+}
+
+func (ue *UnaryExpr) SetSpan(span diagnostics.Span) {
+	ue.span = span
+}
+
 func (ue *UnaryExpr) Visit(v Visitor) {
 	v.VisitUnaryExpr(ue)
 	if v.Traverse() {
@@ -66,6 +95,7 @@ func (ue *UnaryExpr) Visit(v Visitor) {
 
 type BinaryExpr struct {
 	name     string
+	span     diagnostics.Span
 	TypeHint TypeAttr
 	Op       string
 	Lhs      Node
@@ -80,6 +110,14 @@ func (be *BinaryExpr) Name() string {
 	return be.name
 }
 
+func (be *BinaryExpr) Span() diagnostics.Span {
+	return be.span
+}
+
+func (be *BinaryExpr) SetSpan(span diagnostics.Span) {
+	be.span = span
+}
+
 func (be *BinaryExpr) Visit(v Visitor) {
 	v.VisitBinaryExpr(be)
 	if v.Traverse() {
@@ -90,6 +128,7 @@ func (be *BinaryExpr) Visit(v Visitor) {
 
 type IfExpr struct {
 	name      string
+	span      diagnostics.Span
 	TypeHint  TypeAttr
 	Condition Node
 	IfNode    Node
@@ -104,6 +143,14 @@ func (ie *IfExpr) Name() string {
 	return ie.name
 }
 
+func (ie *IfExpr) Span() diagnostics.Span {
+	return ie.span
+}
+
+func (ie *IfExpr) SetSpan(span diagnostics.Span) {
+	ie.span = span
+}
+
 func (ie *IfExpr) Visit(v Visitor) {
 	v.VisitIfExpr(ie)
 	if v.Traverse() {
@@ -115,6 +162,7 @@ func (ie *IfExpr) Visit(v Visitor) {
 
 type ListExpr struct {
 	name     string
+	span     diagnostics.Span
 	TypeHint TypeAttr
 	Elements []Node
 	Node
@@ -127,6 +175,14 @@ func (le *ListExpr) Name() string {
 	return le.name
 }
 
+func (le *ListExpr) Span() diagnostics.Span {
+	return le.span
+}
+
+func (le *ListExpr) SetSpan(span diagnostics.Span) {
+	le.span = span
+}
+
 func (le *ListExpr) Visit(v Visitor) {
 	v.VisitListExpr(le)
 	if v.Traverse() {
@@ -138,6 +194,7 @@ func (le *ListExpr) Visit(v Visitor) {
 
 type CallExpr struct {
 	name      string
+	span      diagnostics.Span
 	TypeHint  TypeAttr
 	FuncName  string
 	Arguments []Node
@@ -151,6 +208,14 @@ func (ce *CallExpr) Name() string {
 	return ce.name
 }
 
+func (ce *CallExpr) Span() diagnostics.Span {
+	return ce.span
+}
+
+func (ce *CallExpr) SetSpan(span diagnostics.Span) {
+	ce.span = span
+}
+
 func (ce *CallExpr) Visit(v Visitor) {
 	v.VisitCallExpr(ce)
 	if v.Traverse() {
@@ -162,6 +227,7 @@ func (ce *CallExpr) Visit(v Visitor) {
 
 type IndexExpr struct {
 	name     string
+	span     diagnostics.Span
 	TypeHint TypeAttr
 	Value    Node
 	Index    Node
@@ -175,6 +241,14 @@ func (ie *IndexExpr) Name() string {
 	return ie.name
 }
 
+func (ie *IndexExpr) Span() diagnostics.Span {
+	return ie.span
+}
+
+func (ie *IndexExpr) SetSpan(span diagnostics.Span) {
+	ie.span = span
+}
+
 func (ie *IndexExpr) Visit(v Visitor) {
 	v.VisitIndexExpr(ie)
 	if v.Traverse() {
@@ -184,3 +258,69 @@ func (ie *IndexExpr) Visit(v Visitor) {
 	// We do not want to visit the type hint as it does not
 	// belong to the AST even though it is an Node!
 }
+
+type MemberExpr struct {
+	name       string
+	span       diagnostics.Span
+	TypeHint   TypeAttr
+	Object     Node
+	MemberName string
+	Node
+}
+
+func (me *MemberExpr) Name() string {
+	if me.name == "" {
+		me.name = "MemberExpr"
+	}
+	return me.name
+}
+
+func (me *MemberExpr) Span() diagnostics.Span {
+	return me.span
+}
+
+func (me *MemberExpr) SetSpan(span diagnostics.Span) {
+	me.span = span
+}
+
+func (me *MemberExpr) Visit(v Visitor) {
+	v.VisitMemberExpr(me)
+	if v.Traverse() {
+		me.Object.Visit(v)
+	}
+}
+
+type MethodCallExpr struct {
+	name       string
+	span       diagnostics.Span
+	TypeHint   TypeAttr
+	Receiver   Node
+	MethodName string
+	Arguments  []Node
+	Node
+}
+
+func (mc *MethodCallExpr) Name() string {
+	if mc.name == "" {
+		mc.name = "MethodCallExpr"
+	}
+	return mc.name
+}
+
+func (mc *MethodCallExpr) Span() diagnostics.Span {
+	return mc.span
+}
+
+func (mc *MethodCallExpr) SetSpan(span diagnostics.Span) {
+	mc.span = span
+}
+
+func (mc *MethodCallExpr) Visit(v Visitor) {
+	v.VisitMethodCallExpr(mc)
+	if v.Traverse() {
+		mc.Receiver.Visit(v)
+		for _, argument := range mc.Arguments {
+			argument.Visit(v)
+		}
+	}
+}
@@ -1,10 +1,17 @@
 // Package ast implements definitions for the AST nodes and the AST visitor
 package ast
 
+import "chogopy/src/diagnostics"
+
 type Node interface {
 	Name() string
 	Visit(v Visitor)
 
+	// Span returns the region of the source code that the node has been parsed from.
+	// Nodes that have not been created by the parser return a span that is not valid.
+	Span() diagnostics.Span
+	SetSpan(span diagnostics.Span)
+
 	// TODO: It would be good to have this but is the effort for validation even beneficial
 	// or will invalid nodes not already be prevented in the parser and in other checks?
 	Validate() bool
@@ -12,6 +19,7 @@ type Node interface {
 
 type Program struct {
 	name        string
+	span        diagnostics.Span
 	Definitions []Node
 	Statements  []Node
 	Node
@@ -24,6 +32,14 @@ func (p *Program) Name() string {
 	return p.name
 }
 
+func (p *Program) Span() diagnostics.Span {
+	return p.span
+}
+
+func (p *Program) SetSpan(span diagnostics.Span) {
+	p.span = span
+}
+
 func (p *Program) Visit(v Visitor) {
 	v.VisitProgram(p)
 	if v.Traverse() {
@@ -35,3 +51,30 @@ func (p *Program) Visit(v Visitor) {
 		}
 	}
 }
+
+// ErrorNode takes the place of a definition or statement that could not be parsed.
+// Its span covers the tokens that the parser skipped while recovering from the syntax error.
+type ErrorNode struct {
+	name string
+	span diagnostics.Span
+	Node
+}
+
+func (en *ErrorNode) Name() string {
+	if en.name == "" {
+		en.name = "ErrorNode"
+	}
+	return en.name
+}
+
+func (en *ErrorNode) Span() diagnostics.Span {
+	return en.span
+}
+
+func (en *ErrorNode) SetSpan(span diagnostics.Span) {
+	en.span = span
+}
+
+func (en *ErrorNode) Visit(v Visitor) {
+	v.VisitErrorNode(en)
+}
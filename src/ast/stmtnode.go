@@ -1,7 +1,10 @@
 package ast
 
+import "chogopy/src/diagnostics"
+
 type GlobalDecl struct {
 	name     string
+	span     diagnostics.Span
 	DeclName string
 	Node
 }
@@ -13,12 +16,21 @@ func (gd *GlobalDecl) Name() string {
 	return gd.name
 }
 
+func (gd *GlobalDecl) Span() diagnostics.Span {
+	return gd.span
+}
+
+func (gd *GlobalDecl) SetSpan(span diagnostics.Span) {
+	gd.span = span
+}
+
 func (gd *GlobalDecl) Visit(v Visitor) {
 	v.VisitGlobalDecl(gd)
 }
 
 type NonLocalDecl struct {
 	name     string
+	span     diagnostics.Span
 	DeclName string
 	Node
 }
@@ -30,12 +42,21 @@ func (nl *NonLocalDecl) Name() string {
 	return nl.name
 }
 
+func (nl *NonLocalDecl) Span() diagnostics.Span {
+	return nl.span
+}
+
+func (nl *NonLocalDecl) SetSpan(span diagnostics.Span) {
+	nl.span = span
+}
+
 func (nl *NonLocalDecl) Visit(v Visitor) {
 	v.VisitNonLocalDecl(nl)
 }
 
 type IfStmt struct {
 	name      string
+	span      diagnostics.Span
 	Condition Node
 	IfBody    []Node
 	ElseBody  []Node
@@ -49,6 +70,14 @@ func (is *IfStmt) Name() string {
 	return is.name
 }
 
+func (is *IfStmt) Span() diagnostics.Span {
+	return is.span
+}
+
+func (is *IfStmt) SetSpan(span diagnostics.Span) {
+	is.span = span
+}
+
 func (is *IfStmt) Visit(v Visitor) {
 	v.VisitIfStmt(is)
 	if v.Traverse() {
@@ -64,6 +93,7 @@ func (is *IfStmt) Visit(v Visitor) {
 
 type WhileStmt struct {
 	name      string
+	span      diagnostics.Span
 	Condition Node
 	Body      []Node
 	Node
@@ -76,6 +106,14 @@ func (ws *WhileStmt) Name() string {
 	return ws.name
 }
 
+func (ws *WhileStmt) Span() diagnostics.Span {
+	return ws.span
+}
+
+func (ws *WhileStmt) SetSpan(span diagnostics.Span) {
+	ws.span = span
+}
+
 func (ws *WhileStmt) Visit(v Visitor) {
 	v.VisitWhileStmt(ws)
 	if v.Traverse() {
@@ -87,6 +125,7 @@ func (ws *WhileStmt) Visit(v Visitor) {
 
 type ForStmt struct {
 	name     string
+	span     diagnostics.Span
 	IterName string
 	Iter     Node
 	Body     []Node
@@ -100,6 +139,14 @@ func (fs *ForStmt) Name() string {
 	return fs.name
 }
 
+func (fs *ForStmt) Span() diagnostics.Span {
+	return fs.span
+}
+
+func (fs *ForStmt) SetSpan(span diagnostics.Span) {
+	fs.span = span
+}
+
 func (fs *ForStmt) Visit(v Visitor) {
 	v.VisitForStmt(fs)
 	if v.Traverse() {
@@ -112,6 +159,7 @@ func (fs *ForStmt) Visit(v Visitor) {
 
 type PassStmt struct {
 	name string
+	span diagnostics.Span
 	Node
 }
 
@@ -122,12 +170,21 @@ func (ps *PassStmt) Name() string {
 	return ps.name
 }
 
+func (ps *PassStmt) Span() diagnostics.Span {
+	return ps.span
+}
+
+func (ps *PassStmt) SetSpan(span diagnostics.Span) {
+	ps.span = span
+}
+
 func (ps *PassStmt) Visit(v Visitor) {
 	v.VisitPassStmt(ps)
 }
 
 type ReturnStmt struct {
 	name      string
+	span      diagnostics.Span
 	ReturnVal Node
 	Node
 }
@@ -139,6 +196,14 @@ func (rs *ReturnStmt) Name() string {
 	return rs.name
 }
 
+func (rs *ReturnStmt) Span() diagnostics.Span {
+	return rs.span
+}
+
+func (rs *ReturnStmt) SetSpan(span diagnostics.Span) {
+	rs.span = span
+}
+
 func (rs *ReturnStmt) Visit(v Visitor) {
 	v.VisitReturnStmt(rs)
 	if v.Traverse() && rs.ReturnVal != nil {
@@ -148,6 +213,7 @@ func (rs *ReturnStmt) Visit(v Visitor) {
 
 type AssignStmt struct {
 	name   string
+	span   diagnostics.Span
 	Target Node
 	Value  Node
 	Node
@@ -160,6 +226,14 @@ func (as *AssignStmt) Name() string {
 	return as.name
 }
 
+func (as *AssignStmt) Span() diagnostics.Span {
+	return as.span
+}
+
+func (as *AssignStmt) SetSpan(span diagnostics.Span) {
+	as.span = span
+}
+
 func (as *AssignStmt) Visit(v Visitor) {
 	v.VisitAssignStmt(as)
 	if v.Traverse() {
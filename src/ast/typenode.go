@@ -1,7 +1,10 @@
 package ast
 
+import "chogopy/src/diagnostics"
+
 type NamedType struct {
 	name     string
+	span     diagnostics.Span
 	TypeName string
 	Node
 }
@@ -13,12 +16,21 @@ func (nt *NamedType) Name() string {
 	return nt.name
 }
 
+func (nt *NamedType) Span() diagnostics.Span {
+	return nt.span
+}
+
+func (nt *NamedType) SetSpan(span diagnostics.Span) {
+	nt.span = span
+}
+
 func (nt *NamedType) Visit(v Visitor) {
 	v.VisitNamedType(nt)
 }
 
 type ListType struct {
 	name     string
+	span     diagnostics.Span
 	ElemType Node
 	Node
 }
@@ -30,6 +42,14 @@ func (lt *ListType) Name() string {
 	return lt.name
 }
 
+func (lt *ListType) Span() diagnostics.Span {
+	return lt.span
+}
+
+func (lt *ListType) SetSpan(span diagnostics.Span) {
+	lt.span = span
+}
+
 func (lt *ListType) Visit(v Visitor) {
 	v.VisitListType(lt)
 
@@ -5,6 +5,7 @@ type Visitor interface {
 	VisitNamedType(nt *NamedType)
 	VisitListType(lt *ListType)
 	VisitProgram(p *Program)
+	VisitClassDef(cd *ClassDef)
 	VisitFuncDef(fd *FuncDef)
 	VisitTypedVar(tv *TypedVar)
 	VisitGlobalDecl(gd *GlobalDecl)
@@ -24,6 +25,9 @@ type Visitor interface {
 	VisitListExpr(le *ListExpr)
 	VisitCallExpr(ce *CallExpr)
 	VisitIndexExpr(ie *IndexExpr)
+	VisitMemberExpr(me *MemberExpr)
+	VisitMethodCallExpr(mc *MethodCallExpr)
+	VisitErrorNode(en *ErrorNode)
 }
 
 type BaseVisitor struct{}
@@ -46,6 +50,9 @@ func (bv *BaseVisitor) VisitListType(lt *ListType) {
 func (bv *BaseVisitor) VisitProgram(p *Program) {
 }
 
+func (bv *BaseVisitor) VisitClassDef(cd *ClassDef) {
+}
+
 func (bv *BaseVisitor) VisitFuncDef(fd *FuncDef) {
 }
 
@@ -102,3 +109,12 @@ func (bv *BaseVisitor) VisitCallExpr(ce *CallExpr) {
 
 func (bv *BaseVisitor) VisitIndexExpr(ie *IndexExpr) {
 }
+
+func (bv *BaseVisitor) VisitMemberExpr(me *MemberExpr) {
+}
+
+func (bv *BaseVisitor) VisitMethodCallExpr(mc *MethodCallExpr) {
+}
+
+func (bv *BaseVisitor) VisitErrorNode(en *ErrorNode) {
+}
@@ -0,0 +1,49 @@
+// Package backend defines methods for converting
+// the LLVM IR generated in codegen into an executable
+//
+// The LLVM libraries are only linked into the compiler if it is built with one of the
+// build tags llvm14 to llvm20, which also select the version of LLVM that go-llvm binds to.
+// Without such a tag, the package needs neither cgo nor LLVM development headers and
+// instead compiles LLVM IR by invoking the opt and llc tools of an installed LLVM toolchain.
+//
+// The code in this package is heavily inspired by:
+//
+// DDP-Projekt/Kompilierer by Hendrik Ziegler
+// https://github.com/DDP-Projekt/Kompilierer
+package backend
+
+import "fmt"
+
+// Target describes the machine that code is generated for.
+// An empty triple stands for the machine that the compiler is running on.
+// The CPU and features are passed to LLVM as they are, e.g. "cortex-a72" and "+neon,-crypto".
+type Target struct {
+	Triple   string
+	CPU      string
+	Features string
+}
+
+// FileType is the kind of file that CompileIR produces.
+type FileType int
+
+const (
+	ObjectFile FileType = iota
+	AssemblyFile
+	// BitcodeFile is the optimized module as LLVM bitcode.
+	BitcodeFile
+)
+
+// ToolError is returned when an external LLVM tool fails. It contains everything the tool wrote to stderr.
+type ToolError struct {
+	Command string
+	Stderr  string
+	Err     error
+}
+
+func (e *ToolError) Error() string {
+	return fmt.Sprintf("running '%s' failed: %s", e.Command, e.Err)
+}
+
+func (e *ToolError) Unwrap() error {
+	return e.Err
+}
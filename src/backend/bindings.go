@@ -1,15 +1,10 @@
-// Package backend defines methods for converting
-// the LLVM IR generated in codegen into an executable
-//
-// The code in this package is heavily inspired by:
-//
-// DDP-Projekt/Kompilierer by Hendrik Ziegler
-// https://github.com/DDP-Projekt/Kompilierer
+//go:build llvm14 || llvm15 || llvm16 || llvm17 || llvm18 || llvm19 || llvm20
+
 package backend
 
 import (
+	"fmt"
 	"io"
-	"log"
 
 	"tinygo.org/x/go-llvm"
 )
@@ -20,6 +15,7 @@ func Init() {
 	llvm.InitializeAllTargetMCs()
 	llvm.InitializeAllAsmParsers()
 	llvm.InitializeAllAsmPrinters()
+	llvm.LinkInMCJIT()
 }
 
 type llvmTarget struct {
@@ -27,16 +23,21 @@ type llvmTarget struct {
 	targetData    llvm.TargetData
 }
 
-func newllvmTarget() llvmTarget {
-	target, err := llvm.GetTargetFromTriple(llvm.DefaultTargetTriple())
+func newllvmTarget(target Target) (llvmTarget, error) {
+	triple := target.Triple
+	if triple == "" {
+		triple = llvm.DefaultTargetTriple()
+	}
+
+	targetInfo, err := llvm.GetTargetFromTriple(triple)
 	if err != nil {
-		log.Fatalln("newllvmTarget: failed to get target from default target triple: ", err)
+		return llvmTarget{}, fmt.Errorf("newllvmTarget: failed to get target from target triple %s: %w", triple, err)
 	}
 
-	targetMachine := target.CreateTargetMachine(
-		llvm.DefaultTargetTriple(),
-		"",
-		"",
+	targetMachine := targetInfo.CreateTargetMachine(
+		triple,
+		target.CPU,
+		target.Features,
 		llvm.CodeGenOptLevel(llvm.CodeGenLevelDefault),
 		llvm.RelocMode(llvm.RelocDefault),
 		llvm.CodeModel(llvm.CodeModelDefault),
@@ -47,7 +48,17 @@ func newllvmTarget() llvmTarget {
 	return llvmTarget{
 		targetMachine: targetMachine,
 		targetData:    targetData,
-	}
+	}, nil
+}
+
+// Triple returns the normalized target triple of the target.
+func (t llvmTarget) Triple() string {
+	return t.targetMachine.Triple()
+}
+
+// DataLayout returns the data layout of the target, which determines the size and alignment of every type.
+func (t llvmTarget) DataLayout() string {
+	return t.targetData.String()
 }
 
 func (t llvmTarget) Dispose() {
@@ -61,13 +72,18 @@ type llvmContext struct {
 	llvmTarget
 }
 
-func NewllvmContext() *llvmContext {
+func NewllvmContext(target Target) (*llvmContext, error) {
+	llvmTarget, err := newllvmTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
 	llvmContext := &llvmContext{}
-	llvmContext.llvmTarget = newllvmTarget()
+	llvmContext.llvmTarget = llvmTarget
 	llvmContext.context = llvm.NewContext()
 	llvmContext.passOptions = llvm.NewPassBuilderOptions()
 
-	return llvmContext
+	return llvmContext, nil
 }
 
 func (c *llvmContext) Dispose() {
@@ -83,36 +99,42 @@ func (c *llvmContext) Dispose() {
 * module.RunPasses("default<03>", targetMachine, passOptions)*/
 func (c *llvmContext) RegisterPasses() {}
 
-func (c *llvmContext) ParseIRFromFile(path string) llvm.Module {
-	memBuffer, err := llvm.NewMemoryBufferFromFile(path)
-	if err != nil {
-		log.Fatalln("parseIrFromFile: failed to create memory buffer from .ll file: ", err)
-	}
+// ParseIR parses the given textual LLVM IR into a module for the target of the context.
+// The IR is handed to LLVM through a memory buffer so that it never has to be written to disk.
+func (c *llvmContext) ParseIR(ir string) (llvm.Module, error) {
+	memBuffer := newMemoryBuffer(ir, "chogopy.ll")
 
+	// The module takes ownership of the memory buffer
 	module, err := c.context.ParseIR(memBuffer)
 	if err != nil {
-		log.Fatalln("parseIrFromFile: failed to create module from memory buffer: ", err)
+		return llvm.Module{}, fmt.Errorf("parseIR: failed to create module from memory buffer: %w", err)
 	}
 
 	module.SetTarget(c.targetMachine.Triple())
 	module.SetDataLayout(c.targetData.String())
 
-	return module
+	return module, nil
 }
 
-/* Uses optimization level O3 by default for now.
-* Maybe add this as a compiler option later. */
-func (c *llvmContext) OptimizeModule(module llvm.Module) {
-	module.RunPasses("default<O3>", c.targetMachine, c.passOptions)
+/* Runs the default optimization pipeline of the given level, which ranges from 0 to 3. */
+func (c *llvmContext) OptimizeModule(module llvm.Module, optLevel int) error {
+	return module.RunPasses(fmt.Sprintf("default<O%d>", optLevel), c.targetMachine, c.passOptions)
 }
 
 func (c *llvmContext) CompileModule(module llvm.Module, codeGenType llvm.CodeGenFileType, w io.Writer) (int, error) {
 	compiledBuffer, err := c.targetMachine.EmitToMemoryBuffer(module, codeGenType)
 	if err != nil {
-		log.Fatalln("compileModule: failed to compile module: ", err)
+		return 0, fmt.Errorf("compileModule: failed to compile module: %w", err)
 	}
 
 	defer compiledBuffer.Dispose()
 
 	return w.Write(compiledBuffer.Bytes())
 }
+
+func (c *llvmContext) WriteBitcode(module llvm.Module, w io.Writer) (int, error) {
+	bitcodeBuffer := llvm.WriteBitcodeToMemoryBuffer(module)
+	defer bitcodeBuffer.Dispose()
+
+	return w.Write(bitcodeBuffer.Bytes())
+}
@@ -0,0 +1,106 @@
+//go:build !(llvm14 || llvm15 || llvm16 || llvm17 || llvm18 || llvm19 || llvm20)
+
+package backend
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Native reports whether the LLVM libraries are linked into the compiler.
+const Native = false
+
+// ErrNoNativeBackend is returned by everything that cannot be done with the external LLVM tools.
+var ErrNoNativeBackend = errors.New("the compiler was built without the native LLVM backend, rebuild it with a build tag such as -tags=llvm18")
+
+// TargetInfo returns the target triple of the given target as it is.
+// The data layout is left empty so that the external tools fill in the default data layout of the target.
+func TargetInfo(target Target) (string, string, error) {
+	return target.Triple, "", nil
+}
+
+// CompileIR optimizes the given LLVM IR with opt at the given optimization level
+// and compiles the optimized module into a file of the given type with llc.
+// The tools are taken from the directory in the CGP_LLVM_BINDIR environment variable and are looked up in the PATH otherwise.
+func CompileIR(ir string, target Target, optLevel int, fileType FileType) ([]byte, error) {
+	tempDir, err := os.MkdirTemp("", "chogopy-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	irPath := filepath.Join(tempDir, "main.ll")
+	bitcodePath := filepath.Join(tempDir, "main.bc")
+	outputPath := filepath.Join(tempDir, "main.out")
+	err = os.WriteFile(irPath, []byte(ir), 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create IR file: %w", err)
+	}
+
+	optArguments := append(targetArguments(target), fmt.Sprintf("-passes=default<O%d>", optLevel), irPath, "-o", bitcodePath)
+	err = runTool("opt", optArguments)
+	if err != nil {
+		return nil, err
+	}
+	if fileType == BitcodeFile {
+		return os.ReadFile(bitcodePath)
+	}
+
+	llcArguments := append(targetArguments(target), fmt.Sprintf("-O%d", optLevel), bitcodePath, "-o", outputPath)
+	if fileType == ObjectFile {
+		llcArguments = append(llcArguments, "-filetype=obj")
+	} else {
+		llcArguments = append(llcArguments, "-filetype=asm")
+	}
+	err = runTool("llc", llcArguments)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(outputPath)
+}
+
+// RunIR always fails since just in time compilation needs the LLVM libraries.
+func RunIR(ir string, target Target, optLevel int) (int, error) {
+	return 0, ErrNoNativeBackend
+}
+
+// targetArguments returns the arguments that make opt and llc generate code for the given target.
+func targetArguments(target Target) []string {
+	arguments := []string{}
+	if target.Triple != "" {
+		arguments = append(arguments, "-mtriple="+target.Triple)
+	}
+	if target.CPU != "" {
+		arguments = append(arguments, "-mcpu="+target.CPU)
+	}
+	if target.Features != "" {
+		arguments = append(arguments, "-mattr="+target.Features)
+	}
+	return arguments
+}
+
+func runTool(name string, arguments []string) error {
+	if binDir := os.Getenv("CGP_LLVM_BINDIR"); binDir != "" {
+		name = filepath.Join(binDir, name)
+	}
+	toolCmd := exec.Command(name, arguments...)
+
+	stderr := bytes.Buffer{}
+	toolCmd.Stderr = &stderr
+
+	err := toolCmd.Run()
+	if err != nil {
+		return &ToolError{
+			Command: toolCmd.String(),
+			Stderr:  strings.TrimSpace(stderr.String()),
+			Err:     err,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+//go:build llvm14 || llvm15 || llvm16 || llvm17 || llvm18 || llvm19 || llvm20
+
+package backend
+
+/*
+#include <setjmp.h>
+#include <stdio.h>
+
+static jmp_buf exitJump;
+static int exitCode;
+
+// runtimeExit replaces exit() in programs that are run with the JIT compiler.
+// Instead of terminating the process of the compiler, it returns to runMain with the given exit code.
+static void runtimeExit(int code) {
+	exitCode = code;
+	longjmp(exitJump, 1);
+}
+
+static void *runtimeExitAddress(void) {
+	return (void *)runtimeExit;
+}
+
+// runMain calls the main function of a program and returns its exit code, even if the program calls exit().
+// The output of the program is buffered by the C standard library, which is why it is flushed afterwards.
+static int runMain(void *mainFunction) {
+	int code;
+
+	if (setjmp(exitJump) != 0) {
+		code = exitCode;
+	} else {
+		code = ((int (*)(void))mainFunction)();
+	}
+	fflush(stdout);
+	return code;
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"tinygo.org/x/go-llvm"
+)
+
+// runMutex serializes Run, since the exit code of a program that calls exit() is passed through global state.
+var runMutex sync.Mutex
+
+// Run executes the main function of the given module with the MCJIT compiler of LLVM and returns its exit code.
+// Functions of the C standard library such as printf and malloc are resolved from the running process,
+// except for exit, which returns from Run with the given exit code instead of terminating the process.
+// The module belongs to the execution engine afterwards and must not be used anymore.
+func (c *llvmContext) Run(module llvm.Module, optLevel int) (int, error) {
+	options := llvm.NewMCJITCompilerOptions()
+	options.SetMCJITOptimizationLevel(uint(optLevel))
+
+	exitFunction := module.NamedFunction("exit")
+
+	executionEngine, err := llvm.NewMCJITCompiler(module, options)
+	if err != nil {
+		return 0, fmt.Errorf("run: failed to create execution engine: %w", err)
+	}
+	defer executionEngine.Dispose()
+
+	mainFunction := executionEngine.FindFunction("main")
+	if mainFunction.IsNil() {
+		return 0, errors.New("run: module does not contain a main function")
+	}
+	if !exitFunction.IsNil() {
+		executionEngine.AddGlobalMapping(exitFunction, C.runtimeExitAddress())
+	}
+
+	runMutex.Lock()
+	defer runMutex.Unlock()
+
+	return int(C.runMain(executionEngine.PointerToGlobal(mainFunction))), nil
+}
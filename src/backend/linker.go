@@ -0,0 +1,106 @@
+package backend
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+type LinkMode int
+
+const (
+	// LinkDefault produces whatever kind of executable the linker produces by default.
+	LinkDefault LinkMode = iota
+	LinkStatic
+	LinkPIE
+)
+
+// Linkers are the linkers that a Linker can invoke.
+var Linkers = []string{"cc", "clang", "ld.lld"}
+
+// Linker links object files into an executable by invoking an external linker.
+type Linker struct {
+	// Name is one of Linkers.
+	Name      string
+	Flags     []string
+	Libraries []string
+	Mode      LinkMode
+}
+
+func NewLinker(name string) (Linker, error) {
+	if !slices.Contains(Linkers, name) {
+		return Linker{}, fmt.Errorf("unknown linker '%s', expected one of %s", name, strings.Join(Linkers, ", "))
+	}
+	return Linker{Name: name, Mode: LinkDefault}, nil
+}
+
+// LinkError is returned when the linker fails. It contains everything the linker wrote to stderr.
+type LinkError struct {
+	Command string
+	Stderr  string
+	Err     error
+}
+
+func (e *LinkError) Error() string {
+	return fmt.Sprintf("linking with '%s' failed: %s", e.Command, e.Err)
+}
+
+func (e *LinkError) Unwrap() error {
+	return e.Err
+}
+
+// Link links the given object files into an executable at the given path.
+func (l Linker) Link(objectFiles []string, outputPath string) error {
+	linkerCmd := exec.Command(l.driver(), l.arguments(objectFiles, outputPath)...)
+
+	stderr := bytes.Buffer{}
+	linkerCmd.Stderr = &stderr
+
+	err := linkerCmd.Run()
+	if err != nil {
+		return &LinkError{
+			Command: linkerCmd.String(),
+			Stderr:  strings.TrimSpace(stderr.String()),
+			Err:     err,
+		}
+	}
+
+	return nil
+}
+
+// driver returns the program that is invoked to link.
+// The generated code relies on libc, which is why ld.lld is driven through the C compiler.
+// Only the C compiler knows where the startup files, libc, and the dynamic loader of the system are located.
+func (l Linker) driver() string {
+	if l.Name == "ld.lld" {
+		return "cc"
+	}
+	return l.Name
+}
+
+func (l Linker) arguments(objectFiles []string, outputPath string) []string {
+	arguments := []string{"-o", outputPath}
+
+	if l.Name == "ld.lld" {
+		arguments = append(arguments, "-fuse-ld=lld")
+	}
+
+	switch l.Mode {
+	case LinkStatic:
+		arguments = append(arguments, "-static")
+	case LinkPIE:
+		arguments = append(arguments, "-pie")
+	}
+
+	arguments = append(arguments, l.Flags...)
+	arguments = append(arguments, objectFiles...)
+
+	// Libraries have to come after the object files that depend on them
+	for _, library := range l.Libraries {
+		arguments = append(arguments, "-l"+library)
+	}
+
+	return arguments
+}
@@ -0,0 +1,39 @@
+//go:build llvm14 || llvm15 || llvm16 || llvm17 || llvm18 || llvm19 || llvm20
+
+package backend
+
+/*
+#include <stdlib.h>
+
+// Declared here instead of including llvm-c/Core.h so that the package does not depend on
+// the include path of a specific LLVM version. The symbol itself is provided by the LLVM
+// libraries that go-llvm links against.
+typedef struct LLVMOpaqueMemoryBuffer *LLVMMemoryBufferRef;
+
+LLVMMemoryBufferRef LLVMCreateMemoryBufferWithMemoryRangeCopy(const char *InputData, size_t InputDataLength, const char *BufferName);
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"tinygo.org/x/go-llvm"
+)
+
+// newMemoryBuffer copies the given data into a new LLVM memory buffer with the given name.
+// go-llvm only supports creating memory buffers from files or stdin.
+func newMemoryBuffer(data string, name string) llvm.MemoryBuffer {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	// LLVM copies the data, which is why it can be read straight from the Go string.
+	cData := (*C.char)(unsafe.Pointer(unsafe.StringData(data)))
+	memBufferRef := C.LLVMCreateMemoryBufferWithMemoryRangeCopy(cData, C.size_t(len(data)), cName)
+
+	// The exported field C of llvm.MemoryBuffer holds the same LLVMMemoryBufferRef, but as a type of
+	// the go-llvm package, which is why the reference is stored through a pointer to the field.
+	// TestNewMemoryBuffer checks that go-llvm reads the buffer back from that field.
+	memBuffer := llvm.MemoryBuffer{}
+	*(*C.LLVMMemoryBufferRef)(unsafe.Pointer(&memBuffer.C)) = memBufferRef
+	return memBuffer
+}
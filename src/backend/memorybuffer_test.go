@@ -0,0 +1,18 @@
+//go:build llvm14 || llvm15 || llvm16 || llvm17 || llvm18 || llvm19 || llvm20
+
+package backend
+
+import (
+	"testing"
+)
+
+func TestNewMemoryBuffer(t *testing.T) {
+	for _, data := range []string{"define i32 @main() {\n  ret i32 0\n}\n", ""} {
+		memBuffer := newMemoryBuffer(data, "test.ll")
+
+		if memBuffer.IsNil() || string(memBuffer.Bytes()) != data {
+			t.Fatalf("Expected a memory buffer holding %q but found %q.", data, memBuffer.Bytes())
+		}
+		memBuffer.Dispose()
+	}
+}
@@ -0,0 +1,91 @@
+//go:build llvm14 || llvm15 || llvm16 || llvm17 || llvm18 || llvm19 || llvm20
+
+package backend
+
+import (
+	"bytes"
+	"sync"
+
+	"tinygo.org/x/go-llvm"
+)
+
+// Native reports whether the LLVM libraries are linked into the compiler.
+const Native = true
+
+var initBackend sync.Once
+
+// TargetInfo returns the normalized target triple and the data layout of the given target.
+func TargetInfo(target Target) (string, string, error) {
+	initBackend.Do(Init)
+
+	llvmTarget, err := newllvmTarget(target)
+	if err != nil {
+		return "", "", err
+	}
+	defer llvmTarget.Dispose()
+
+	return llvmTarget.Triple(), llvmTarget.DataLayout(), nil
+}
+
+// CompileIR optimizes the given LLVM IR at the given optimization level and compiles it into a file of the given type.
+func CompileIR(ir string, target Target, optLevel int, fileType FileType) ([]byte, error) {
+	initBackend.Do(Init)
+
+	llvmContext, err := NewllvmContext(target)
+	if err != nil {
+		return nil, err
+	}
+	defer llvmContext.Dispose()
+
+	module, err := llvmContext.ParseIR(ir)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO: This breaks some of the modules which are not entirely correct yet
+	// (For instance, modules in which functions allocate strings or lists on their call stack
+	// and then return pointers to the now unallocated memory. This still happens when input() or
+	// list/string concatenation is used and the resulting value is returned and then used by the caller.)
+	err = llvmContext.OptimizeModule(module, optLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	output := bytes.Buffer{}
+	switch fileType {
+	case ObjectFile:
+		_, err = llvmContext.CompileModule(module, llvm.CodeGenFileType(llvm.ObjectFile), &output)
+	case AssemblyFile:
+		_, err = llvmContext.CompileModule(module, llvm.CodeGenFileType(llvm.AssemblyFile), &output)
+	case BitcodeFile:
+		_, err = llvmContext.WriteBitcode(module, &output)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return output.Bytes(), nil
+}
+
+// RunIR optimizes the given LLVM IR and executes it with the JIT compiler of LLVM. It returns the exit code of the program.
+func RunIR(ir string, target Target, optLevel int) (int, error) {
+	initBackend.Do(Init)
+
+	llvmContext, err := NewllvmContext(target)
+	if err != nil {
+		return 0, err
+	}
+	defer llvmContext.Dispose()
+
+	module, err := llvmContext.ParseIR(ir)
+	if err != nil {
+		return 0, err
+	}
+
+	err = llvmContext.OptimizeModule(module, optLevel)
+	if err != nil {
+		return 0, err
+	}
+
+	return llvmContext.Run(module, optLevel)
+}
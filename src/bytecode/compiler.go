@@ -0,0 +1,324 @@
+package bytecode
+
+import (
+	"chogopy/src/ast"
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+)
+
+// compileError aborts the compilation of a program that exceeds the limits of the instruction set.
+type compileError struct {
+	err error
+}
+
+// classInfo maps the names of the attributes and methods of a class to their index in its objects and dispatch table.
+type classInfo struct {
+	index      int
+	attributes map[string]int
+	methods    map[string]int
+}
+
+// scope holds the names that are visible in the function whose code is currently generated.
+// The statements at the top level of the program are compiled in a scope of depth 0,
+// whose locals are only used for temporary values. All of its variables are globals.
+type scope struct {
+	parent      *scope
+	depth       int
+	function    int
+	locals      map[string]int
+	globalNames map[string]bool
+	functions   map[string]int
+}
+
+func newScope(parent *scope, depth int, function int) *scope {
+	return &scope{
+		parent:      parent,
+		depth:       depth,
+		function:    function,
+		locals:      map[string]int{},
+		globalNames: map[string]bool{},
+		functions:   map[string]int{},
+	}
+}
+
+type compiler struct {
+	program   *Program
+	constants map[Value]int
+	globals   map[string]int
+	classes   map[string]*classInfo
+	// funcIndices maps every function and method definition to its index in the function table.
+	funcIndices map[*ast.FuncDef]int
+	scope       *scope
+	ast.BaseVisitor
+}
+
+// Compile compiles the given program, which has to have been annotated by typechecks.StaticTyping,
+// into bytecode. An error is only returned if the program exceeds the limits of the instruction set,
+// e.g. if it contains more than 65536 distinct constants.
+func Compile(program *ast.Program) (compiled *Program, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			compileError, ok := recovered.(compileError)
+			if !ok {
+				panic(recovered)
+			}
+			err = compileError.err
+		}
+	}()
+
+	c := &compiler{
+		program:     &Program{},
+		constants:   map[Value]int{},
+		globals:     map[string]int{},
+		classes:     map[string]*classInfo{},
+		funcIndices: map[*ast.FuncDef]int{},
+	}
+
+	c.program.Main = c.newFunction("main", 0)
+	c.scope = newScope(nil, 0, c.program.Main)
+	c.registerObjectClass()
+
+	// Every global definition is registered up front since functions
+	// may refer to globals, functions and classes that are defined further down
+	for _, definition := range program.Definitions {
+		switch definition := definition.(type) {
+		case *ast.VarDef:
+			c.globals[definition.TypedVar.(*ast.TypedVar).VarName] = len(c.globals)
+		case *ast.FuncDef:
+			c.scope.functions[definition.FuncName] = c.registerFunction(definition, definition.FuncName)
+		case *ast.ClassDef:
+			c.registerClass(definition)
+		}
+	}
+	c.program.Globals = len(c.globals)
+	c.checkLimit(c.program.Globals)
+
+	for _, definition := range program.Definitions {
+		definition.Visit(c)
+	}
+	c.compileBody(program.Statements)
+	c.emit(CONST, c.constant(nil))
+	c.emit(RETURN)
+	c.function().Locals = len(c.scope.locals)
+
+	return c.program, nil
+}
+
+// Traverse is disabled since the compiler decides on its own in which order the child nodes of a node are compiled.
+func (c *compiler) Traverse() bool {
+	return false
+}
+
+func (c *compiler) function() *Function {
+	return &c.program.Functions[c.scope.function]
+}
+
+func (c *compiler) newFunction(name string, params int) int {
+	c.program.Functions = append(c.program.Functions, Function{Name: name, Params: params, Locals: params})
+	c.checkLimit(len(c.program.Functions))
+	return len(c.program.Functions) - 1
+}
+
+func (c *compiler) registerFunction(funcDef *ast.FuncDef, name string) int {
+	index := c.newFunction(name, len(funcDef.Parameters))
+	c.funcIndices[funcDef] = index
+	return index
+}
+
+// registerObjectClass adds the object class, whose __init__ method does nothing, as the first class of the program.
+func (c *compiler) registerObjectClass() {
+	init := c.newFunction("object.__init__", 1)
+	code := &c.program.Functions[init].Code
+	*code = append(*code, byte(CONST))
+	*code = binary.LittleEndian.AppendUint16(*code, uint16(c.constant(nil)))
+	*code = append(*code, byte(RETURN))
+
+	c.classes["object"] = &classInfo{
+		index:      len(c.program.Classes),
+		attributes: map[string]int{},
+		methods:    map[string]int{"__init__": 0},
+	}
+	c.program.Classes = append(c.program.Classes, Class{Name: "object", Methods: []int{init}})
+}
+
+// registerClass lays out the attributes and the dispatch table of a class.
+// Both start out as the ones of the superclass. Methods that override an inherited method reuse its slot.
+func (c *compiler) registerClass(classDef *ast.ClassDef) {
+	superInfo := c.classes[classDef.SuperClass]
+	superClass := c.program.Classes[superInfo.index]
+
+	info := &classInfo{
+		index:      len(c.program.Classes),
+		attributes: maps.Clone(superInfo.attributes),
+		methods:    maps.Clone(superInfo.methods),
+	}
+	class := Class{
+		Name:       classDef.ClassName,
+		Attributes: slices.Clone(superClass.Attributes),
+		Methods:    slices.Clone(superClass.Methods),
+	}
+
+	for _, definition := range classDef.ClassBody {
+		switch definition := definition.(type) {
+		case *ast.VarDef:
+			info.attributes[definition.TypedVar.(*ast.TypedVar).VarName] = len(class.Attributes)
+			class.Attributes = append(class.Attributes, c.constant(literalValue(definition.Literal)))
+		case *ast.FuncDef:
+			method := c.registerFunction(definition, classDef.ClassName+"."+definition.FuncName)
+			if slot, ok := info.methods[definition.FuncName]; ok {
+				class.Methods[slot] = method
+			} else {
+				info.methods[definition.FuncName] = len(class.Methods)
+				class.Methods = append(class.Methods, method)
+			}
+		}
+	}
+
+	c.classes[classDef.ClassName] = info
+	c.program.Classes = append(c.program.Classes, class)
+	c.checkLimit(len(c.program.Classes))
+}
+
+// compileFunction compiles the body of a function or method in a new scope that is nested in the current one.
+func (c *compiler) compileFunction(funcDef *ast.FuncDef) {
+	outerScope := c.scope
+	c.scope = newScope(outerScope, outerScope.depth+1, c.funcIndices[funcDef])
+	defer func() { c.scope = outerScope }()
+
+	for _, param := range funcDef.Parameters {
+		c.scope.locals[param.(*ast.TypedVar).VarName] = len(c.scope.locals)
+	}
+	for _, bodyNode := range funcDef.FuncBody {
+		switch bodyNode := bodyNode.(type) {
+		case *ast.VarDef:
+			c.scope.locals[bodyNode.TypedVar.(*ast.TypedVar).VarName] = len(c.scope.locals)
+		case *ast.GlobalDecl:
+			c.scope.globalNames[bodyNode.DeclName] = true
+		case *ast.FuncDef:
+			c.scope.functions[bodyNode.FuncName] = c.registerFunction(bodyNode, c.function().Name+"."+bodyNode.FuncName)
+		}
+	}
+
+	c.compileBody(funcDef.FuncBody)
+	c.emit(CONST, c.constant(nil))
+	c.emit(RETURN)
+	c.function().Locals = len(c.scope.locals)
+}
+
+// newTemp reserves a local that holds a temporary value.
+// Its name can not clash with any identifier of the program.
+func (c *compiler) newTemp() int {
+	slot := len(c.scope.locals)
+	c.scope.locals[fmt.Sprintf("$%d", slot)] = slot
+	c.checkLimit(len(c.scope.locals))
+	return slot
+}
+
+func (c *compiler) checkLimit(count int) {
+	if count > math.MaxUint16+1 {
+		panic(compileError{fmt.Errorf("bytecode: the program exceeds the limit of %d constants, globals, locals, functions or classes", math.MaxUint16+1)})
+	}
+}
+
+// constant returns the index of the given value in the constant pool and adds it to the pool if necessary.
+func (c *compiler) constant(value Value) int {
+	if index, ok := c.constants[value]; ok {
+		return index
+	}
+	c.program.Constants = append(c.program.Constants, value)
+	c.checkLimit(len(c.program.Constants))
+	c.constants[value] = len(c.program.Constants) - 1
+	return len(c.program.Constants) - 1
+}
+
+func literalValue(literal ast.Node) Value {
+	if value, ok := literal.(*ast.LiteralExpr).Value.(int); ok {
+		return int32(value)
+	}
+	return literal.(*ast.LiteralExpr).Value
+}
+
+// emit appends an instruction to the code of the current function and returns its offset.
+func (c *compiler) emit(op Opcode, operands ...int) int {
+	function := c.function()
+	offset := len(function.Code)
+
+	function.Code = append(function.Code, byte(op))
+	for operandIdx, width := range operandWidths[op] {
+		if width == 2 {
+			function.Code = binary.LittleEndian.AppendUint16(function.Code, uint16(operands[operandIdx]))
+		} else {
+			function.Code = binary.LittleEndian.AppendUint32(function.Code, uint32(operands[operandIdx]))
+		}
+	}
+
+	return offset
+}
+
+// patchJump sets the target of the jump instruction at the given offset to the current end of the code.
+func (c *compiler) patchJump(offset int) {
+	function := c.function()
+	binary.LittleEndian.PutUint32(function.Code[offset+1:], uint32(len(function.Code)))
+}
+
+// markLine records that the following instructions stem from the line of the given node.
+func (c *compiler) markLine(node ast.Node) {
+	line := node.Span().Start.Line
+	function := c.function()
+	if line == 0 || (len(function.Lines) > 0 && function.Lines[len(function.Lines)-1].Line == line) {
+		return
+	}
+	function.Lines = append(function.Lines, LineEntry{Offset: len(function.Code), Line: line})
+}
+
+// loadVar and storeVar access the variable with the given name. Variables that are not local to the current function
+// are looked up in the enclosing functions and are accessed through the static links of the frames.
+func (c *compiler) loadVar(name string) {
+	hops, slot, isLocal := c.resolve(name)
+	switch {
+	case !isLocal:
+		c.emit(LOAD_GLOBAL, c.globals[name])
+	case hops == 0:
+		c.emit(LOAD_LOCAL, slot)
+	default:
+		c.emit(LOAD_OUTER, hops, slot)
+	}
+}
+
+func (c *compiler) storeVar(name string) {
+	hops, slot, isLocal := c.resolve(name)
+	switch {
+	case !isLocal:
+		c.emit(STORE_GLOBAL, c.globals[name])
+	case hops == 0:
+		c.emit(STORE_LOCAL, slot)
+	default:
+		c.emit(STORE_OUTER, hops, slot)
+	}
+}
+
+// resolve returns how many scopes away from the current scope the variable with the given name
+// is defined together with its slot. If it is a global variable, isLocal is false.
+func (c *compiler) resolve(name string) (hops int, slot int, isLocal bool) {
+	for scope := c.scope; scope.depth > 0; scope = scope.parent {
+		if scope.globalNames[name] {
+			break
+		}
+		if slot, ok := scope.locals[name]; ok {
+			return c.scope.depth - scope.depth, slot, true
+		}
+	}
+	return 0, 0, false
+}
+
+// classOf returns the class that the type checker inferred for the given expression.
+func (c *compiler) classOf(expression ast.Node) *classInfo {
+	switch typeHint := ast.TypeHintOf(expression).(type) {
+	case ast.ClassAttribute:
+		return c.classes[typeHint.ClassName]
+	}
+	return c.classes["object"]
+}
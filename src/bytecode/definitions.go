@@ -0,0 +1,22 @@
+package bytecode
+
+import "chogopy/src/ast"
+
+func (c *compiler) VisitVarDef(varDef *ast.VarDef) {
+	c.markLine(varDef)
+	c.emit(CONST, c.constant(literalValue(varDef.Literal)))
+	c.storeVar(varDef.TypedVar.(*ast.TypedVar).VarName)
+}
+
+func (c *compiler) VisitFuncDef(funcDef *ast.FuncDef) {
+	c.compileFunction(funcDef)
+}
+
+// VisitClassDef compiles the methods of a class. Its layout has already been registered before any code was generated.
+func (c *compiler) VisitClassDef(classDef *ast.ClassDef) {
+	for _, definition := range classDef.ClassBody {
+		if method, ok := definition.(*ast.FuncDef); ok {
+			c.compileFunction(method)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package bytecode
+
+import "chogopy/src/ast"
+
+var binaryOpcodes = map[string]Opcode{
+	"-":  SUB,
+	"*":  MUL,
+	"//": DIV,
+	"%":  MOD,
+	"<":  LT,
+	"<=": LE,
+	">":  GT,
+	">=": GE,
+	"==": EQ,
+	"!=": NE,
+	"is": IS,
+}
+
+func (c *compiler) VisitLiteralExpr(literalExpr *ast.LiteralExpr) {
+	c.emit(CONST, c.constant(literalValue(literalExpr)))
+}
+
+func (c *compiler) VisitIdentExpr(identExpr *ast.IdentExpr) {
+	c.loadVar(identExpr.Identifier)
+}
+
+func (c *compiler) VisitUnaryExpr(unaryExpr *ast.UnaryExpr) {
+	unaryExpr.Value.Visit(c)
+
+	switch unaryExpr.Op {
+	case "-":
+		c.emit(NEG)
+	case "not":
+		c.emit(NOT)
+	}
+}
+
+// VisitBinaryExpr skips the right operand of "and" and "or" if the left operand already determines the result.
+func (c *compiler) VisitBinaryExpr(binaryExpr *ast.BinaryExpr) {
+	binaryExpr.Lhs.Visit(c)
+
+	switch binaryExpr.Op {
+	case "and", "or":
+		jumpOp := JUMP_IF_FALSE_OR_POP
+		if binaryExpr.Op == "or" {
+			jumpOp = JUMP_IF_TRUE_OR_POP
+		}
+		endJump := c.emit(jumpOp, 0)
+		binaryExpr.Rhs.Visit(c)
+		c.patchJump(endJump)
+		return
+	}
+
+	binaryExpr.Rhs.Visit(c)
+
+	if binaryExpr.Op == "+" {
+		if binaryExpr.TypeHint == ast.Integer {
+			c.emit(ADD)
+		} else {
+			c.emit(CONCAT)
+		}
+		return
+	}
+	c.emit(binaryOpcodes[binaryExpr.Op])
+}
+
+func (c *compiler) VisitIfExpr(ifExpr *ast.IfExpr) {
+	ifExpr.Condition.Visit(c)
+	elseJump := c.emit(JUMP_IF_FALSE, 0)
+	ifExpr.IfNode.Visit(c)
+	endJump := c.emit(JUMP, 0)
+	c.patchJump(elseJump)
+	ifExpr.ElseNode.Visit(c)
+	c.patchJump(endJump)
+}
+
+func (c *compiler) VisitListExpr(listExpr *ast.ListExpr) {
+	for _, element := range listExpr.Elements {
+		element.Visit(c)
+	}
+	c.checkLimit(len(listExpr.Elements))
+	c.emit(LIST, len(listExpr.Elements))
+}
+
+// VisitCallExpr calls a function, constructs an object of a class or calls one of the builtin functions.
+func (c *compiler) VisitCallExpr(callExpr *ast.CallExpr) {
+	for _, argument := range callExpr.Arguments {
+		argument.Visit(c)
+	}
+
+	for scope := c.scope; scope != nil; scope = scope.parent {
+		function, ok := scope.functions[callExpr.FuncName]
+		if !ok {
+			continue
+		}
+		// Functions that are nested in other functions get the frame of the function that defines them as their static link
+		staticLink := 0
+		if scope.depth > 0 {
+			staticLink = c.scope.depth - scope.depth + 1
+		}
+		c.emit(CALL, function, staticLink)
+		return
+	}
+
+	if class, ok := c.classes[callExpr.FuncName]; ok {
+		c.emit(NEW, class.index)
+		c.emit(DUP)
+		c.emit(CALL_METHOD, class.methods["__init__"], 0)
+		c.emit(POP)
+		return
+	}
+
+	switch callExpr.FuncName {
+	case "print":
+		c.emit(PRINT)
+	case "len":
+		c.emit(LEN)
+	case "input":
+		c.emit(INPUT)
+	}
+}
+
+func (c *compiler) VisitIndexExpr(indexExpr *ast.IndexExpr) {
+	indexExpr.Value.Visit(c)
+	indexExpr.Index.Visit(c)
+	c.emit(INDEX)
+}
+
+func (c *compiler) VisitMemberExpr(memberExpr *ast.MemberExpr) {
+	memberExpr.Object.Visit(c)
+	c.emit(GET_ATTR, c.classOf(memberExpr.Object).attributes[memberExpr.MemberName])
+}
+
+// VisitMethodCallExpr looks up the slot of the method in the dispatch table of the static type of the receiver.
+// Since subclasses keep the slots of their superclass, the method of the class of the receiver is called at runtime.
+func (c *compiler) VisitMethodCallExpr(methodCallExpr *ast.MethodCallExpr) {
+	methodCallExpr.Receiver.Visit(c)
+	for _, argument := range methodCallExpr.Arguments {
+		argument.Visit(c)
+	}
+
+	class := c.classOf(methodCallExpr.Receiver)
+	c.emit(CALL_METHOD, class.methods[methodCallExpr.MethodName], len(methodCallExpr.Arguments))
+}
@@ -0,0 +1,137 @@
+package bytecode
+
+import "fmt"
+
+// Opcode is the first byte of every instruction. It is followed by the operands of the instruction,
+// whose widths are given by operandWidths. All operands are unsigned little endian integers.
+//
+// The comments describe the stack before and after an instruction, with the top of the stack on the right.
+type Opcode byte
+
+const (
+	// CONST k: ... -> ..., Constants[k]
+	CONST Opcode = iota
+	// LOAD_LOCAL s: ... -> ..., locals[s]
+	LOAD_LOCAL
+	// STORE_LOCAL s: ..., value -> ...
+	STORE_LOCAL
+	// LOAD_GLOBAL g: ... -> ..., globals[g]
+	LOAD_GLOBAL
+	// STORE_GLOBAL g: ..., value -> ...
+	STORE_GLOBAL
+	// LOAD_OUTER h s loads the local s of the frame that is h static links away from the current one.
+	LOAD_OUTER
+	// STORE_OUTER h s stores into the local s of the frame that is h static links away from the current one.
+	STORE_OUTER
+	// POP: ..., value -> ...
+	POP
+	// DUP: ..., value -> ..., value, value
+	DUP
+
+	// NEG and NOT: ..., value -> ..., result
+	NEG
+	NOT
+	// ADD to IS: ..., lhs, rhs -> ..., result
+	ADD
+	SUB
+	MUL
+	DIV
+	MOD
+	LT
+	LE
+	GT
+	GE
+	EQ
+	NE
+	IS
+	// CONCAT concatenates two strings or two lists.
+	CONCAT
+
+	// JUMP t continues at the offset t of the current function.
+	JUMP
+	// JUMP_IF_FALSE t: ..., condition -> ...
+	JUMP_IF_FALSE
+	// JUMP_IF_FALSE_OR_POP t jumps and keeps the condition on the stack if it is false and pops it otherwise.
+	JUMP_IF_FALSE_OR_POP
+	// JUMP_IF_TRUE_OR_POP t jumps and keeps the condition on the stack if it is true and pops it otherwise.
+	JUMP_IF_TRUE_OR_POP
+
+	// LIST n: ..., element1, ..., elementN -> ..., list
+	LIST
+	// INDEX: ..., list or string, index -> ..., element
+	INDEX
+	// STORE_INDEX: ..., value, list, index -> ...
+	STORE_INDEX
+	// NEW c: ... -> ..., object of the class c with its attributes set to their initial values
+	NEW
+	// GET_ATTR a: ..., object -> ..., attribute a of the object
+	GET_ATTR
+	// SET_ATTR a: ..., value, object -> ...
+	SET_ATTR
+
+	// CALL f l: ..., arg1, ..., argN -> ..., return value
+	// If l is 0, the function has been defined globally. Otherwise, its static link is
+	// the frame that is l-1 static links away from the current one.
+	CALL
+	// CALL_METHOD m n: ..., receiver, arg1, ..., argN -> ..., return value
+	// The method m is looked up in the dispatch table of the class of the receiver.
+	CALL_METHOD
+	// RETURN: ..., value -> the value is returned to the caller
+	RETURN
+
+	// PRINT: ..., value -> ..., None
+	PRINT
+	// LEN: ..., list or string -> ..., length
+	LEN
+	// INPUT: ... -> ..., line read from stdin
+	INPUT
+)
+
+var opcodeNames = [...]string{
+	CONST: "CONST", LOAD_LOCAL: "LOAD_LOCAL", STORE_LOCAL: "STORE_LOCAL", LOAD_GLOBAL: "LOAD_GLOBAL",
+	STORE_GLOBAL: "STORE_GLOBAL", LOAD_OUTER: "LOAD_OUTER", STORE_OUTER: "STORE_OUTER", POP: "POP", DUP: "DUP",
+	NEG: "NEG", NOT: "NOT", ADD: "ADD", SUB: "SUB", MUL: "MUL", DIV: "DIV", MOD: "MOD",
+	LT: "LT", LE: "LE", GT: "GT", GE: "GE", EQ: "EQ", NE: "NE", IS: "IS", CONCAT: "CONCAT",
+	JUMP: "JUMP", JUMP_IF_FALSE: "JUMP_IF_FALSE", JUMP_IF_FALSE_OR_POP: "JUMP_IF_FALSE_OR_POP",
+	JUMP_IF_TRUE_OR_POP: "JUMP_IF_TRUE_OR_POP", LIST: "LIST", INDEX: "INDEX", STORE_INDEX: "STORE_INDEX",
+	NEW: "NEW", GET_ATTR: "GET_ATTR", SET_ATTR: "SET_ATTR", CALL: "CALL", CALL_METHOD: "CALL_METHOD",
+	RETURN: "RETURN", PRINT: "PRINT", LEN: "LEN", INPUT: "INPUT",
+}
+
+func (op Opcode) String() string {
+	if int(op) < len(opcodeNames) {
+		return opcodeNames[op]
+	}
+	return fmt.Sprintf("Opcode(%d)", op)
+}
+
+// operandWidths contains the width in bytes of every operand of the instructions that have operands.
+// Jump targets take up four bytes while all other operands take up two.
+var operandWidths = map[Opcode][]int{
+	CONST:                {2},
+	LOAD_LOCAL:           {2},
+	STORE_LOCAL:          {2},
+	LOAD_GLOBAL:          {2},
+	STORE_GLOBAL:         {2},
+	LOAD_OUTER:           {2, 2},
+	STORE_OUTER:          {2, 2},
+	JUMP:                 {4},
+	JUMP_IF_FALSE:        {4},
+	JUMP_IF_FALSE_OR_POP: {4},
+	JUMP_IF_TRUE_OR_POP:  {4},
+	LIST:                 {2},
+	NEW:                  {2},
+	GET_ATTR:             {2},
+	SET_ATTR:             {2},
+	CALL:                 {2, 2},
+	CALL_METHOD:          {2, 2},
+}
+
+// instructionSize returns the size of an instruction with the given opcode including its operands.
+func instructionSize(op Opcode) int {
+	size := 1
+	for _, width := range operandWidths[op] {
+		size += width
+	}
+	return size
+}
@@ -0,0 +1,90 @@
+// Package bytecode compiles a statically typed AST into a compact stack-based instruction set
+// and executes it on a virtual machine that is written in pure Go.
+//
+// A Program consists of a constant pool, a table of functions and a table of classes.
+// The code of every function refers to constants, globals, locals, functions and classes by their index,
+// and comes with a line table that maps the offsets of its instructions back to the source code.
+// Programs can be serialized into .chb files with Encode and loaded again with Decode.
+package bytecode
+
+import "chogopy/src/codegen"
+
+// Value is a value at runtime. It is either an int32, a bool, a string, a *List, an *Object or nil, which stands for None.
+// Constants are restricted to int32, bool, string and nil.
+type Value any
+
+type List struct {
+	Elements []Value
+}
+
+type Object struct {
+	Class      int
+	Attributes []Value
+}
+
+type Program struct {
+	Constants []Value
+	// Globals is the number of global variables.
+	Globals   int
+	Functions []Function
+	Classes   []Class
+	// Main is the index of the function that contains the statements at the top level of the program.
+	Main int
+}
+
+type Function struct {
+	Name string
+	// Params is the number of parameters, which occupy the first locals of the function.
+	Params int
+	// Locals is the number of local variables including the parameters.
+	Locals int
+	Code   []byte
+	Lines  []LineEntry
+}
+
+// LineEntry states that the instructions starting at the given offset stem from the given line of the source code.
+type LineEntry struct {
+	Offset int
+	Line   int
+}
+
+// Line returns the line of the source code that the instruction at the given offset stems from
+// or 0 if the line is unknown.
+func (f *Function) Line(offset int) int {
+	line := 0
+	for _, entry := range f.Lines {
+		if entry.Offset > offset {
+			break
+		}
+		line = entry.Line
+	}
+	return line
+}
+
+// Class describes the layout of the objects of a class.
+// Inherited attributes and methods come first so that an object of a subclass can be used wherever
+// an object of its superclass is expected.
+type Class struct {
+	Name string
+	// Attributes are the indices of the constants that the attributes of a new object are initialized with.
+	Attributes []int
+	// Methods is the dispatch table of the class. It contains the function index of every method,
+	// starting with __init__.
+	Methods []int
+}
+
+// RuntimeError is an error that the program raised while it was running on the virtual machine.
+type RuntimeError struct {
+	codegen.RuntimeError
+	// Line is the line of the source code at which the error was raised or 0 if it is unknown.
+	Line int
+}
+
+func (re *RuntimeError) Error() string {
+	return re.Message
+}
+
+// ExitCode returns the exit code that a compiled program terminates with when it raises the error.
+func (re *RuntimeError) ExitCode() int {
+	return int(re.Kind)
+}
@@ -0,0 +1,329 @@
+package bytecode
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Magic is the signature at the start of every .chb file.
+const Magic = "CHB"
+
+// Version is the version of the .chb format that Encode writes. Decode rejects files of any other version.
+const Version = 1
+
+// The tags that precede every constant in a .chb file.
+const (
+	tagNone byte = iota
+	tagInt
+	tagBool
+	tagString
+)
+
+// Encode writes the program to w in the .chb format.
+//
+// A .chb file starts with Magic and Version. The rest of the file consists of the constant pool,
+// the function table, the class table, the number of globals and the index of the main function.
+// Integers are stored as varints and every table and string is preceded by its length.
+func (p *Program) Encode(w io.Writer) error {
+	e := &encoder{}
+	e.buffer.WriteString(Magic)
+	e.buffer.WriteByte(Version)
+
+	e.uint(len(p.Constants))
+	for _, constant := range p.Constants {
+		switch constant := constant.(type) {
+		case nil:
+			e.buffer.WriteByte(tagNone)
+		case int32:
+			e.buffer.WriteByte(tagInt)
+			e.buffer.Write(binary.AppendVarint(nil, int64(constant)))
+		case bool:
+			e.buffer.WriteByte(tagBool)
+			if constant {
+				e.buffer.WriteByte(1)
+			} else {
+				e.buffer.WriteByte(0)
+			}
+		case string:
+			e.buffer.WriteByte(tagString)
+			e.string(constant)
+		default:
+			return fmt.Errorf("bytecode: constant of type %T can not be encoded", constant)
+		}
+	}
+
+	e.uint(len(p.Functions))
+	for _, function := range p.Functions {
+		e.string(function.Name)
+		e.uint(function.Params)
+		e.uint(function.Locals)
+		e.uint(len(function.Code))
+		e.buffer.Write(function.Code)
+		e.uint(len(function.Lines))
+		for _, entry := range function.Lines {
+			e.uint(entry.Offset)
+			e.uint(entry.Line)
+		}
+	}
+
+	e.uint(len(p.Classes))
+	for _, class := range p.Classes {
+		e.string(class.Name)
+		e.ints(class.Attributes)
+		e.ints(class.Methods)
+	}
+
+	e.uint(p.Globals)
+	e.uint(p.Main)
+
+	_, err := w.Write(e.buffer.Bytes())
+	return err
+}
+
+type encoder struct {
+	buffer bytes.Buffer
+}
+
+func (e *encoder) uint(value int) {
+	e.buffer.Write(binary.AppendUvarint(nil, uint64(value)))
+}
+
+func (e *encoder) string(value string) {
+	e.uint(len(value))
+	e.buffer.WriteString(value)
+}
+
+func (e *encoder) ints(values []int) {
+	e.uint(len(values))
+	for _, value := range values {
+		e.uint(value)
+	}
+}
+
+// Decode reads a program in the .chb format from r.
+// The program is verified before it is returned so that the virtual machine can rely on
+// every instruction being complete and on every index being in range.
+func Decode(r io.Reader) (*Program, error) {
+	d := &decoder{reader: bufio.NewReader(r)}
+
+	header := make([]byte, len(Magic)+1)
+	if _, err := io.ReadFull(d.reader, header); err != nil || string(header[:len(Magic)]) != Magic {
+		return nil, errors.New("bytecode: not a .chb file")
+	}
+	if header[len(Magic)] != Version {
+		return nil, fmt.Errorf("bytecode: unsupported .chb version %d", header[len(Magic)])
+	}
+
+	program := &Program{}
+
+	program.Constants = make([]Value, d.length())
+	for constantIdx := range program.Constants {
+		switch tag := d.byte(); tag {
+		case tagNone:
+		case tagInt:
+			value, err := binary.ReadVarint(d.reader)
+			d.fail(err)
+			program.Constants[constantIdx] = int32(value)
+		case tagBool:
+			program.Constants[constantIdx] = d.byte() != 0
+		case tagString:
+			program.Constants[constantIdx] = string(d.bytes())
+		default:
+			d.fail(fmt.Errorf("unknown constant tag %d", tag))
+		}
+	}
+
+	program.Functions = make([]Function, d.length())
+	for functionIdx := range program.Functions {
+		function := &program.Functions[functionIdx]
+		function.Name = string(d.bytes())
+		function.Params = d.uint()
+		function.Locals = d.uint()
+		function.Code = d.bytes()
+		function.Lines = make([]LineEntry, d.length())
+		for entryIdx := range function.Lines {
+			function.Lines[entryIdx] = LineEntry{Offset: d.uint(), Line: d.uint()}
+		}
+	}
+
+	program.Classes = make([]Class, d.length())
+	for classIdx := range program.Classes {
+		class := &program.Classes[classIdx]
+		class.Name = string(d.bytes())
+		class.Attributes = d.ints()
+		class.Methods = d.ints()
+	}
+
+	program.Globals = d.uint()
+	program.Main = d.uint()
+
+	if d.err != nil {
+		return nil, fmt.Errorf("bytecode: malformed .chb file: %w", d.err)
+	}
+	if err := program.verify(); err != nil {
+		return nil, fmt.Errorf("bytecode: malformed .chb file: %w", err)
+	}
+	return program, nil
+}
+
+// decoder reads the parts of a .chb file. After the first error, it only returns zero values
+// and the error is kept in err.
+type decoder struct {
+	reader *bufio.Reader
+	err    error
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil && err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		d.err = err
+	}
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+	value, err := d.reader.ReadByte()
+	d.fail(err)
+	return value
+}
+
+func (d *decoder) uint() int {
+	if d.err != nil {
+		return 0
+	}
+	value, err := binary.ReadUvarint(d.reader)
+	d.fail(err)
+	if value > 1<<31 {
+		d.fail(fmt.Errorf("value %d is out of range", value))
+		return 0
+	}
+	return int(value)
+}
+
+// length reads the length of a table or string. It is limited so that a corrupt file
+// can not make the decoder allocate huge tables.
+func (d *decoder) length() int {
+	length := d.uint()
+	if length > 1<<24 {
+		d.fail(fmt.Errorf("table of length %d is too long", length))
+		return 0
+	}
+	return length
+}
+
+func (d *decoder) bytes() []byte {
+	value := make([]byte, d.length())
+	if d.err != nil {
+		return nil
+	}
+	_, err := io.ReadFull(d.reader, value)
+	d.fail(err)
+	return value
+}
+
+func (d *decoder) ints() []int {
+	values := make([]int, d.length())
+	for valueIdx := range values {
+		values[valueIdx] = d.uint()
+	}
+	return values
+}
+
+// verify checks that the code of every function consists of complete instructions with known opcodes,
+// that every operand refers to an existing constant, global, local, function or class,
+// and that every jump targets the start of an instruction.
+func (p *Program) verify() error {
+	if p.Main >= len(p.Functions) {
+		return fmt.Errorf("main function %d does not exist", p.Main)
+	}
+	for _, class := range p.Classes {
+		for _, constant := range class.Attributes {
+			if constant >= len(p.Constants) {
+				return fmt.Errorf("class %s refers to constant %d, which does not exist", class.Name, constant)
+			}
+		}
+		if len(class.Methods) == 0 {
+			return fmt.Errorf("class %s has no __init__ method", class.Name)
+		}
+		for _, method := range class.Methods {
+			if method >= len(p.Functions) || p.Functions[method].Params == 0 {
+				return fmt.Errorf("class %s refers to method %d, which does not exist", class.Name, method)
+			}
+		}
+	}
+
+	for _, function := range p.Functions {
+		if function.Params > function.Locals {
+			return fmt.Errorf("function %s has more parameters than locals", function.Name)
+		}
+
+		starts := map[int]bool{}
+		jumps := []int{}
+		last := Opcode(0)
+		for offset := 0; offset < len(function.Code); {
+			op := Opcode(function.Code[offset])
+			if int(op) >= len(opcodeNames) {
+				return fmt.Errorf("unknown opcode %d at offset %d of function %s", op, offset, function.Name)
+			}
+			if offset+instructionSize(op) > len(function.Code) {
+				return fmt.Errorf("incomplete %s instruction at offset %d of function %s", op, offset, function.Name)
+			}
+
+			starts[offset] = true
+			operands := []int{}
+			operandOffset := offset + 1
+			for _, width := range operandWidths[op] {
+				if width == 2 {
+					operands = append(operands, int(binary.LittleEndian.Uint16(function.Code[operandOffset:])))
+				} else {
+					operands = append(operands, int(binary.LittleEndian.Uint32(function.Code[operandOffset:])))
+				}
+				operandOffset += width
+			}
+
+			limit, checked := 0, true
+			switch op {
+			case CONST:
+				limit = len(p.Constants)
+			case LOAD_LOCAL, STORE_LOCAL:
+				limit = function.Locals
+			case LOAD_GLOBAL, STORE_GLOBAL:
+				limit = p.Globals
+			case NEW:
+				limit = len(p.Classes)
+			case CALL:
+				limit = len(p.Functions)
+			case JUMP, JUMP_IF_FALSE, JUMP_IF_FALSE_OR_POP, JUMP_IF_TRUE_OR_POP:
+				limit = len(function.Code)
+				jumps = append(jumps, operands[0])
+			default:
+				checked = false
+			}
+			if checked && operands[0] >= limit {
+				return fmt.Errorf("%s %d at offset %d of function %s is out of range", op, operands[0], offset, function.Name)
+			}
+
+			last = op
+			offset += instructionSize(op)
+		}
+
+		for _, target := range jumps {
+			if !starts[target] {
+				return fmt.Errorf("jump to offset %d of function %s is not the start of an instruction", target, function.Name)
+			}
+		}
+		if last != RETURN {
+			return fmt.Errorf("function %s does not end with RETURN", function.Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package bytecode
+
+import "chogopy/src/ast"
+
+// compileBody compiles the given statements. The values of expressions that are used as statements are discarded.
+func (c *compiler) compileBody(statements []ast.Node) {
+	for _, statement := range statements {
+		c.markLine(statement)
+		statement.Visit(c)
+		if ast.TypeHintOf(statement) != nil {
+			c.emit(POP)
+		}
+	}
+}
+
+func (c *compiler) VisitIfStmt(ifStmt *ast.IfStmt) {
+	ifStmt.Condition.Visit(c)
+	elseJump := c.emit(JUMP_IF_FALSE, 0)
+
+	c.compileBody(ifStmt.IfBody)
+
+	if len(ifStmt.ElseBody) == 0 {
+		c.patchJump(elseJump)
+		return
+	}
+
+	endJump := c.emit(JUMP, 0)
+	c.patchJump(elseJump)
+	c.compileBody(ifStmt.ElseBody)
+	c.patchJump(endJump)
+}
+
+func (c *compiler) VisitWhileStmt(whileStmt *ast.WhileStmt) {
+	condition := len(c.function().Code)
+	whileStmt.Condition.Visit(c)
+	exitJump := c.emit(JUMP_IF_FALSE, 0)
+
+	c.compileBody(whileStmt.Body)
+	c.emit(JUMP, condition)
+	c.patchJump(exitJump)
+}
+
+// VisitForStmt compiles a for loop into a loop over the indices of the iterable.
+// The iterable and the current index are kept in temporary locals.
+//
+//	iterable = <iter>; index = 0
+//	while index < len(iterable):
+//	    <name> = iterable[index]
+//	    <body>
+//	    index = index + 1
+func (c *compiler) VisitForStmt(forStmt *ast.ForStmt) {
+	iterable := c.newTemp()
+	index := c.newTemp()
+
+	forStmt.Iter.Visit(c)
+	c.emit(STORE_LOCAL, iterable)
+	c.emit(CONST, c.constant(int32(0)))
+	c.emit(STORE_LOCAL, index)
+
+	condition := len(c.function().Code)
+	c.emit(LOAD_LOCAL, index)
+	c.emit(LOAD_LOCAL, iterable)
+	c.emit(LEN)
+	c.emit(LT)
+	exitJump := c.emit(JUMP_IF_FALSE, 0)
+
+	c.emit(LOAD_LOCAL, iterable)
+	c.emit(LOAD_LOCAL, index)
+	c.emit(INDEX)
+	c.storeVar(forStmt.IterName)
+
+	c.compileBody(forStmt.Body)
+
+	c.emit(LOAD_LOCAL, index)
+	c.emit(CONST, c.constant(int32(1)))
+	c.emit(ADD)
+	c.emit(STORE_LOCAL, index)
+	c.emit(JUMP, condition)
+	c.patchJump(exitJump)
+}
+
+func (c *compiler) VisitPassStmt(passStmt *ast.PassStmt) {
+}
+
+func (c *compiler) VisitReturnStmt(returnStmt *ast.ReturnStmt) {
+	if returnStmt.ReturnVal != nil {
+		returnStmt.ReturnVal.Visit(c)
+	} else {
+		c.emit(CONST, c.constant(nil))
+	}
+	c.emit(RETURN)
+}
+
+// VisitAssignStmt evaluates the value of an assignment once and then assigns it to every target from left to right.
+// The targets of a multiple assignment such as a = b = 1 are parsed as nested assignments.
+func (c *compiler) VisitAssignStmt(assignStmt *ast.AssignStmt) {
+	targets := []ast.Node{assignStmt.Target}
+	value := assignStmt.Value
+	for {
+		nestedAssign, ok := value.(*ast.AssignStmt)
+		if !ok {
+			break
+		}
+		targets = append(targets, nestedAssign.Target)
+		value = nestedAssign.Value
+	}
+
+	value.Visit(c)
+	for targetIdx, target := range targets {
+		if targetIdx < len(targets)-1 {
+			c.emit(DUP)
+		}
+
+		switch target := target.(type) {
+		case *ast.IdentExpr:
+			c.storeVar(target.Identifier)
+		case *ast.IndexExpr:
+			target.Value.Visit(c)
+			target.Index.Visit(c)
+			c.emit(STORE_INDEX)
+		case *ast.MemberExpr:
+			target.Object.Visit(c)
+			c.emit(SET_ATTR, c.classOf(target.Object).attributes[target.MemberName])
+		}
+	}
+}
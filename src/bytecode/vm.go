@@ -0,0 +1,345 @@
+package bytecode
+
+import (
+	"bufio"
+	"chogopy/src/codegen"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// frame is the activation of a function.
+// Its static link is the frame of the function that the function is nested in.
+type frame struct {
+	function   *Function
+	locals     []Value
+	staticLink *frame
+	pc         int
+}
+
+func (f *frame) operand16() int {
+	operand := binary.LittleEndian.Uint16(f.function.Code[f.pc:])
+	f.pc += 2
+	return int(operand)
+}
+
+func (f *frame) operand32() int {
+	operand := binary.LittleEndian.Uint32(f.function.Code[f.pc:])
+	f.pc += 4
+	return int(operand)
+}
+
+// outer returns the frame that is the given number of static links away.
+func (f *frame) outer(hops int) *frame {
+	outer := f
+	for range hops {
+		outer = outer.staticLink
+	}
+	return outer
+}
+
+// VM is a stack-based virtual machine that executes bytecode programs.
+type VM struct {
+	program *Program
+	stdin   *bufio.Reader
+	stdout  io.Writer
+
+	globals []Value
+	stack   []Value
+	frames  []*frame
+}
+
+// NewVM creates a virtual machine that executes the given program.
+// The program reads from stdin when it calls input() and writes to stdout when it calls print().
+func NewVM(program *Program, stdin io.Reader, stdout io.Writer) *VM {
+	return &VM{
+		program: program,
+		stdin:   bufio.NewReader(stdin),
+		stdout:  stdout,
+	}
+}
+
+// raise aborts the execution of the program with the runtime error of the given name from codegen.RuntimeErrors.
+func raise(errorName string) {
+	panic(&RuntimeError{RuntimeError: codegen.RuntimeErrors[errorName]})
+}
+
+// Run executes the program from the start.
+// If the program raises a runtime error, execution stops and a *RuntimeError is returned.
+// Any other error means that the program is malformed.
+func (vm *VM) Run() (err error) {
+	vm.globals = make([]Value, vm.program.Globals)
+	vm.stack = []Value{}
+	vm.frames = []*frame{}
+
+	instruction := 0
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			runtimeError, ok := recovered.(*RuntimeError)
+			if !ok {
+				err = fmt.Errorf("bytecode: malformed program: %v", recovered)
+				return
+			}
+			if len(vm.frames) > 0 {
+				runtimeError.Line = vm.frames[len(vm.frames)-1].function.Line(instruction)
+			}
+			err = runtimeError
+		}
+	}()
+
+	vm.call(vm.program.Main, nil)
+
+	for len(vm.frames) > 0 {
+		frame := vm.frames[len(vm.frames)-1]
+		instruction = frame.pc
+		op := Opcode(frame.function.Code[frame.pc])
+		frame.pc++
+
+		switch op {
+		case CONST:
+			vm.push(vm.program.Constants[frame.operand16()])
+		case LOAD_LOCAL:
+			vm.push(frame.locals[frame.operand16()])
+		case STORE_LOCAL:
+			frame.locals[frame.operand16()] = vm.pop()
+		case LOAD_GLOBAL:
+			vm.push(vm.globals[frame.operand16()])
+		case STORE_GLOBAL:
+			vm.globals[frame.operand16()] = vm.pop()
+		case LOAD_OUTER:
+			outer := frame.outer(frame.operand16())
+			vm.push(outer.locals[frame.operand16()])
+		case STORE_OUTER:
+			outer := frame.outer(frame.operand16())
+			outer.locals[frame.operand16()] = vm.pop()
+		case POP:
+			vm.pop()
+		case DUP:
+			vm.push(vm.stack[len(vm.stack)-1])
+
+		case NEG:
+			vm.push(-vm.pop().(int32))
+		case NOT:
+			vm.push(!vm.pop().(bool))
+		case ADD, SUB, MUL, DIV, MOD, LT, LE, GT, GE:
+			rhs := vm.pop().(int32)
+			lhs := vm.pop().(int32)
+			vm.push(arithmetic(op, lhs, rhs))
+		case EQ, IS:
+			rhs := vm.pop()
+			vm.push(vm.pop() == rhs)
+		case NE:
+			rhs := vm.pop()
+			vm.push(vm.pop() != rhs)
+		case CONCAT:
+			rhs := vm.pop()
+			vm.push(concat(vm.pop(), rhs))
+
+		case JUMP:
+			frame.pc = frame.operand32()
+		case JUMP_IF_FALSE:
+			target := frame.operand32()
+			if !vm.pop().(bool) {
+				frame.pc = target
+			}
+		case JUMP_IF_FALSE_OR_POP, JUMP_IF_TRUE_OR_POP:
+			target := frame.operand32()
+			if vm.stack[len(vm.stack)-1].(bool) == (op == JUMP_IF_TRUE_OR_POP) {
+				frame.pc = target
+			} else {
+				vm.pop()
+			}
+
+		case LIST:
+			length := frame.operand16()
+			elements := make([]Value, length)
+			copy(elements, vm.stack[len(vm.stack)-length:])
+			vm.stack = vm.stack[:len(vm.stack)-length]
+			vm.push(&List{Elements: elements})
+		case INDEX:
+			index := vm.pop().(int32)
+			vm.push(elementAt(vm.pop(), index))
+		case STORE_INDEX:
+			index := vm.pop().(int32)
+			list := vm.pop()
+			value := vm.pop()
+			if list == nil {
+				raise("error_index_none")
+			}
+			checkIndex(index, len(list.(*List).Elements))
+			list.(*List).Elements[index] = value
+		case NEW:
+			classIdx := frame.operand16()
+			class := vm.program.Classes[classIdx]
+			object := &Object{Class: classIdx, Attributes: make([]Value, len(class.Attributes))}
+			for attrIdx, constant := range class.Attributes {
+				object.Attributes[attrIdx] = vm.program.Constants[constant]
+			}
+			vm.push(object)
+		case GET_ATTR:
+			object := vm.pop()
+			if object == nil {
+				raise("error_member_none")
+			}
+			vm.push(object.(*Object).Attributes[frame.operand16()])
+		case SET_ATTR:
+			object := vm.pop()
+			value := vm.pop()
+			if object == nil {
+				raise("error_member_none")
+			}
+			object.(*Object).Attributes[frame.operand16()] = value
+
+		case CALL:
+			function := frame.operand16()
+			staticLink := frame.operand16()
+			if staticLink == 0 {
+				vm.call(function, nil)
+			} else {
+				vm.call(function, frame.outer(staticLink-1))
+			}
+		case CALL_METHOD:
+			slot := frame.operand16()
+			receiver := vm.stack[len(vm.stack)-frame.operand16()-1]
+			if receiver == nil {
+				raise("error_member_none")
+			}
+			vm.call(vm.program.Classes[receiver.(*Object).Class].Methods[slot], nil)
+		case RETURN:
+			vm.frames = vm.frames[:len(vm.frames)-1]
+
+		case PRINT:
+			// Just like in compiled programs, only integers, booleans and strings can be printed
+			switch value := vm.pop().(type) {
+			case int32, string:
+				fmt.Fprintln(vm.stdout, value)
+			case bool:
+				if value {
+					fmt.Fprintln(vm.stdout, "True")
+				} else {
+					fmt.Fprintln(vm.stdout, "False")
+				}
+			default:
+				raise("error_invalid_argument")
+			}
+			vm.push(nil)
+		case LEN:
+			vm.push(vm.length(vm.pop()))
+		case INPUT:
+			line, _ := vm.stdin.ReadString('\n')
+			line = strings.TrimSuffix(line, "\n")
+			vm.push(strings.TrimSuffix(line, "\r"))
+
+		default:
+			panic(fmt.Sprintf("unknown opcode %s", op))
+		}
+	}
+
+	return nil
+}
+
+// call pushes a new frame for the given function whose arguments are taken from the stack.
+// The return value of the function is left on the stack of the caller by RETURN.
+func (vm *VM) call(functionIdx int, staticLink *frame) {
+	function := &vm.program.Functions[functionIdx]
+
+	locals := make([]Value, function.Locals)
+	copy(locals, vm.stack[len(vm.stack)-function.Params:])
+	vm.stack = vm.stack[:len(vm.stack)-function.Params]
+
+	vm.frames = append(vm.frames, &frame{function: function, locals: locals, staticLink: staticLink})
+}
+
+func (vm *VM) push(value Value) {
+	vm.stack = append(vm.stack, value)
+}
+
+func (vm *VM) pop() Value {
+	value := vm.stack[len(vm.stack)-1]
+	vm.stack = vm.stack[:len(vm.stack)-1]
+	return value
+}
+
+// arithmetic applies an integer operation to the given operands. Integers wrap around on overflow
+// and integer division and modulo round towards negative infinity like Python does.
+func arithmetic(op Opcode, lhs int32, rhs int32) Value {
+	switch op {
+	case ADD:
+		return lhs + rhs
+	case SUB:
+		return lhs - rhs
+	case MUL:
+		return lhs * rhs
+	case DIV, MOD:
+		if rhs == 0 {
+			raise("error_div_zero")
+		}
+		quotient, remainder := lhs/rhs, lhs%rhs
+		if remainder != 0 && (remainder < 0) != (rhs < 0) {
+			quotient--
+			remainder += rhs
+		}
+		if op == DIV {
+			return quotient
+		}
+		return remainder
+	case LT:
+		return lhs < rhs
+	case LE:
+		return lhs <= rhs
+	case GT:
+		return lhs > rhs
+	}
+	return lhs >= rhs
+}
+
+func concat(lhs Value, rhs Value) Value {
+	if lhs, ok := lhs.(string); ok {
+		return lhs + rhs.(string)
+	}
+
+	if lhs == nil || rhs == nil {
+		raise("error_len_none")
+	}
+	elements := append([]Value{}, lhs.(*List).Elements...)
+	elements = append(elements, rhs.(*List).Elements...)
+	return &List{Elements: elements}
+}
+
+// elementAt returns the element of a list or the character of a string at the given index.
+func elementAt(value Value, index int32) Value {
+	switch value := value.(type) {
+	case string:
+		checkIndex(index, len(value))
+		return value[index : index+1]
+	case *List:
+		checkIndex(index, len(value.Elements))
+		return value.Elements[index]
+	}
+	raise("error_index_none")
+	return nil
+}
+
+// checkIndex raises an error if the given index is out of the bounds of a list or string with the given length.
+func checkIndex(index int32, length int) {
+	if index < 0 {
+		raise("error_index_neg")
+	}
+	if int(index) >= length {
+		raise("error_index_oob")
+	}
+}
+
+func (vm *VM) length(value Value) int32 {
+	switch value := value.(type) {
+	case string:
+		return int32(len(value))
+	case *List:
+		return int32(len(value.Elements))
+	case nil:
+		raise("error_len_none")
+	}
+	raise("error_invalid_argument")
+	return 0
+}
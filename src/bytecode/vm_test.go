@@ -0,0 +1,174 @@
+package bytecode_test
+
+import (
+	"bytes"
+	"chogopy/src/bytecode"
+	"chogopy/src/codegen"
+	"chogopy/src/compiler"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// compile checks the given program and compiles it into bytecode.
+func compile(t *testing.T, stream string) *bytecode.Program {
+	result, diags := compiler.Compile(stream, compiler.Options{StopAfter: compiler.StageTypes})
+	if len(diags) != 0 {
+		t.Fatalf("Expected a valid program but found %v.", diags)
+	}
+
+	compiled, err := bytecode.Compile(result.Program)
+	if err != nil {
+		t.Fatalf("Expected the program to compile but found %v.", err)
+	}
+	return compiled
+}
+
+// run runs the given program with the given input and returns what it printed.
+func run(program *bytecode.Program, input string) (string, error) {
+	output := strings.Builder{}
+	vm := bytecode.NewVM(program, strings.NewReader(input), &output)
+	err := vm.Run()
+	return output.String(), err
+}
+
+func TestRunArithmetic(t *testing.T) {
+	stream := `print(-7 // 2)
+print(-7 % 2)
+print(7 % -2)
+print(2147483647 + 1)
+print(-2147483648 - 1)
+print(65536 * 65536)
+print(--2147483648)
+print(-2147483648 // -1)
+print(1 < 2 and not False)
+print(False and 1 // 0 == 0)
+print("ab" + "c" == "abc")`
+
+	output, err := run(compile(t, stream), "")
+
+	expected := "-4\n1\n-1\n-2147483648\n2147483647\n0\n-2147483648\n-2147483648\nTrue\nFalse\nTrue\n"
+	if err != nil || output != expected {
+		t.Fatalf("Expected %q but found %q (%v).", expected, output, err)
+	}
+}
+
+func TestRunFunctionsAndClasses(t *testing.T) {
+	stream := `class A(object):
+    x:int = 1
+    def get(self:"A") -> int:
+        return self.x
+class B(A):
+    def __init__(self:"B"):
+        self.x = 2
+    def get(self:"B") -> int:
+        return self.x * 10
+def counter() -> int:
+    count:int = 0
+    def increment() -> int:
+        nonlocal count
+        def add(n:int) -> int:
+            nonlocal count
+            count = count + n
+            return count
+        return add(1)
+    increment()
+    return increment()
+def fib(n:int) -> int:
+    if n < 2:
+        return n
+    return fib(n - 1) + fib(n - 2)
+a:A = None
+a = B()
+print(a.get())
+print(A().get())
+print(counter())
+print(fib(15))
+print(a.x)`
+
+	output, err := run(compile(t, stream), "")
+
+	expected := "20\n1\n2\n610\n2\n"
+	if err != nil || output != expected {
+		t.Fatalf("Expected %q but found %q (%v).", expected, output, err)
+	}
+}
+
+func TestRunListsAndInput(t *testing.T) {
+	stream := `x:[int] = None
+y:[int] = None
+s:str = ""
+i:int = 0
+x = y = [1, 2]
+y[0] = 3
+for i in x + [4]:
+    print(i)
+for s in "ab":
+    print(s)
+s = input()
+print(s[1])
+print(len(s))
+print(["a", "b"][1])`
+
+	output, err := run(compile(t, stream), "hello\n")
+
+	expected := "3\n2\n4\na\nb\ne\n5\nb\n"
+	if err != nil || output != expected {
+		t.Fatalf("Expected %q but found %q (%v).", expected, output, err)
+	}
+}
+
+func TestRunRuntimeErrorLine(t *testing.T) {
+	stream := `x:[int] = None
+print(0)
+print(x[0])
+print(1)`
+
+	output, err := run(compile(t, stream), "")
+
+	var runtimeError *bytecode.RuntimeError
+	if !errors.As(err, &runtimeError) || runtimeError.RuntimeError != codegen.RuntimeErrors["error_index_none"] {
+		t.Fatalf("Expected error_index_none to be raised but found %v.", err)
+	}
+	if runtimeError.Line != 3 || output != "0\n" {
+		t.Fatalf("Expected the error to be raised in line 3 after printing 0 but found line %d and %q.", runtimeError.Line, output)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	stream := `class A(object):
+    s:str = "a"
+    b:bool = True
+def f(x:int) -> int:
+    return -x
+a:A = None
+a = A()
+print(f(2147483647))
+print(a.s)
+print(a.b)`
+
+	encoded := bytes.Buffer{}
+	if err := compile(t, stream).Encode(&encoded); err != nil {
+		t.Fatalf("Expected the program to be encoded but found %v.", err)
+	}
+	if !bytes.HasPrefix(encoded.Bytes(), []byte(bytecode.Magic)) {
+		t.Fatalf("Expected the encoded program to start with %q.", bytecode.Magic)
+	}
+
+	decoded, err := bytecode.Decode(bytes.NewReader(encoded.Bytes()))
+	if err != nil {
+		t.Fatalf("Expected the program to be decoded but found %v.", err)
+	}
+	output, err := run(decoded, "")
+
+	expected := "-2147483647\na\nTrue\n"
+	if err != nil || output != expected {
+		t.Fatalf("Expected %q but found %q (%v).", expected, output, err)
+	}
+
+	for length := range encoded.Len() {
+		if _, err := bytecode.Decode(bytes.NewReader(encoded.Bytes()[:length])); err == nil {
+			t.Fatalf("Expected a program that is truncated to %d bytes to be rejected.", length)
+		}
+	}
+}
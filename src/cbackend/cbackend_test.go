@@ -0,0 +1,138 @@
+package cbackend_test
+
+import (
+	"chogopy/src/cbackend"
+	"chogopy/src/compiler"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// run checks the given program, compiles the generated C code with the system C compiler
+// and runs it with the given input. It returns what the program printed to stdout and stderr and its exit code.
+func run(t *testing.T, stream string, input string) (string, string, int) {
+	if _, err := exec.LookPath(cbackend.CCompiler()); err != nil {
+		t.Skipf("The C compiler %s is not available.", cbackend.CCompiler())
+	}
+
+	options := compiler.Options{StopAfter: compiler.StageCodegen, Backend: compiler.BackendC}
+	result, diags := compiler.Compile(stream, options)
+	if len(diags) != 0 {
+		t.Fatalf("Expected a valid program but found %v.", diags)
+	}
+
+	tempDir := t.TempDir()
+	sourcePath := filepath.Join(tempDir, "main.c")
+	executablePath := filepath.Join(tempDir, "main")
+	if err := os.WriteFile(sourcePath, []byte(result.C), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	output, err := exec.Command(cbackend.CCompiler(), "-std=c99", "-pedantic-errors", sourcePath, "-o", executablePath).CombinedOutput()
+	if err != nil {
+		t.Fatalf("Expected the generated code to compile but found %v:\n%s", err, output)
+	}
+
+	programCmd := exec.Command(executablePath)
+	programCmd.Stdin = strings.NewReader(input)
+	stdout := strings.Builder{}
+	stderr := strings.Builder{}
+	programCmd.Stdout = &stdout
+	programCmd.Stderr = &stderr
+	err = programCmd.Run()
+
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		return stdout.String(), stderr.String(), exitError.ExitCode()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stdout.String(), stderr.String(), 0
+}
+
+func TestGenerateArithmetic(t *testing.T) {
+	stream := `print(-7 // 2)
+print(-7 % 2)
+print(7 % -2)
+print(2147483647 + 1)
+print(-2147483648 // -1)
+print(1 < 2 and not False)
+print(False and 1 // 0 == 0)
+print("ab" + "c" == "abc")`
+
+	stdout, stderr, exitCode := run(t, stream, "")
+
+	expected := "-4\n1\n-1\n-2147483648\n-2147483648\nTrue\nFalse\nTrue\n"
+	if exitCode != 0 || stdout != expected {
+		t.Fatalf("Expected %q but found %q (exit code %d, stderr %q).", expected, stdout, exitCode, stderr)
+	}
+}
+
+func TestGenerateFunctionsAndClasses(t *testing.T) {
+	stream := `class A(object):
+    x:int = 1
+    y:object = 2
+    def get(self:"A") -> int:
+        return self.x
+class B(A):
+    def __init__(self:"B"):
+        self.x = 2
+    def get(self:"B") -> int:
+        return self.x * 10
+def counter() -> int:
+    count:int = 0
+    def increment() -> int:
+        nonlocal count
+        def add(n:int) -> int:
+            nonlocal count
+            count = count + n
+            return count
+        return add(1)
+    increment()
+    return increment()
+a:A = None
+a = B()
+print(a.get())
+print(A().get())
+print(a.y)
+print(counter())
+print(a.x)`
+
+	stdout, stderr, exitCode := run(t, stream, "")
+
+	expected := "20\n1\n2\n2\n2\n"
+	if exitCode != 0 || stdout != expected {
+		t.Fatalf("Expected %q but found %q (exit code %d, stderr %q).", expected, stdout, exitCode, stderr)
+	}
+}
+
+func TestGenerateListsAndInput(t *testing.T) {
+	stream := `x:[int] = None
+y:[int] = None
+s:str = ""
+i:int = 0
+z:[object] = None
+x = y = [1, 2]
+y[0] = 3
+for i in x + [4]:
+    print(i)
+for s in "ab":
+    print(s)
+s = input()
+print(s[1])
+print(len(s))
+z = [1, True] + [["a?"]]
+print(z[0])
+print(z[1])
+print(len(z[2]))`
+
+	stdout, stderr, exitCode := run(t, stream, "hello\n")
+
+	expected := "3\n2\n4\na\nb\ne\n5\n1\nTrue\n1\n"
+	if exitCode != 0 || stdout != expected {
+		t.Fatalf("Expected %q but found %q (exit code %d, stderr %q).", expected, stdout, exitCode, stderr)
+	}
+}
@@ -0,0 +1,66 @@
+package cbackend
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// CompileError is returned when the C compiler fails. It contains everything the C compiler wrote to stderr.
+type CompileError struct {
+	Command string
+	Stderr  string
+	Err     error
+}
+
+func (e *CompileError) Error() string {
+	return fmt.Sprintf("compiling with '%s' failed: %s", e.Command, e.Err)
+}
+
+func (e *CompileError) Unwrap() error {
+	return e.Err
+}
+
+// CCompiler returns the C compiler that generated code is compiled with.
+// It is taken from the CC environment variable and defaults to cc.
+func CCompiler() string {
+	if cc := os.Getenv("CC"); cc != "" {
+		return cc
+	}
+	return "cc"
+}
+
+// CompileObject compiles the given C source code into an object file at the given optimization level.
+func CompileObject(source string, optLevel int) ([]byte, error) {
+	tempDir, err := os.MkdirTemp("", "chogopy-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	sourcePath := filepath.Join(tempDir, "main.c")
+	objectPath := filepath.Join(tempDir, "main.o")
+	err = os.WriteFile(sourcePath, []byte(source), 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create source file: %w", err)
+	}
+
+	ccCmd := exec.Command(CCompiler(), "-std=c99", fmt.Sprintf("-O%d", optLevel), "-c", sourcePath, "-o", objectPath)
+
+	stderr := bytes.Buffer{}
+	ccCmd.Stderr = &stderr
+
+	err = ccCmd.Run()
+	if err != nil {
+		return nil, &CompileError{
+			Command: ccCmd.String(),
+			Stderr:  strings.TrimSpace(stderr.String()),
+			Err:     err,
+		}
+	}
+
+	return os.ReadFile(objectPath)
+}
@@ -0,0 +1,64 @@
+package cbackend
+
+import (
+	"chogopy/src/ast"
+	"fmt"
+	"strings"
+)
+
+func (g *Generator) VisitVarDef(varDef *ast.VarDef) {
+	typedVar := varDef.TypedVar.(*ast.TypedVar)
+	literalExpr := varDef.Literal.(*ast.LiteralExpr)
+	variable, _ := g.variable(typedVar.VarName)
+	g.line("%s = %s;", variable, convert(g.literal(literalExpr), literalType(literalExpr), cType(typedVar.VarType)))
+}
+
+func (g *Generator) VisitFuncDef(funcDef *ast.FuncDef) {
+	g.generateFunction(funcDef)
+}
+
+// VisitClassDef generates the methods, the dispatch table and the constructor of a class.
+// The constructor initializes every attribute, including the inherited ones, and then calls __init__.
+func (g *Generator) VisitClassDef(classDef *ast.ClassDef) {
+	class := g.classes[classDef.ClassName]
+
+	for _, definition := range classDef.ClassBody {
+		if method, ok := definition.(*ast.FuncDef); ok {
+			g.generateFunction(method)
+		}
+	}
+
+	methods := []string{}
+	for _, method := range class.methods {
+		methods = append(methods, "(cgp_method)"+method.cName)
+	}
+	fmt.Fprintf(&g.definitions, "static const cgp_method methods_%s[] = {%s};\n", class.name, strings.Join(methods, ", "))
+	fmt.Fprintf(&g.definitions, "static const cgp_class class_%s = {%s, CGP_OBJECT, CGP_OBJECT, methods_%s};\n\n",
+		class.name, cString(class.name), class.name)
+
+	constructor := strings.Builder{}
+	fmt.Fprintf(&constructor, "\tstruct object_%s *object = cgp_alloc(sizeof(struct object_%s));\n", class.name, class.name)
+	fmt.Fprintf(&constructor, "\t((cgp_object *)object)->type = &class_%s;\n", class.name)
+	for _, attribute := range class.attributes {
+		typedVar := attribute.varDef.TypedVar.(*ast.TypedVar)
+		literalExpr := attribute.varDef.Literal.(*ast.LiteralExpr)
+		fmt.Fprintf(&constructor, "\t((struct object_%s *)object)->%s = %s;\n", attribute.class.name, varName(typedVar.VarName),
+			convert(g.literal(literalExpr), literalType(literalExpr), cType(typedVar.VarType)))
+	}
+	fmt.Fprintf(&constructor, "\t((cgp_object *(*)(cgp_object *))methods_%s[0])((cgp_object *)object);\n", class.name)
+	constructor.WriteString("\treturn (cgp_object *)object;\n")
+	fmt.Fprintf(&g.definitions, "static cgp_object *new_%s(void) {\n%s}\n\n", class.name, constructor.String())
+}
+
+// literalType returns the type of a literal in a variable definition.
+func literalType(literalExpr *ast.LiteralExpr) ast.TypeAttr {
+	switch literalExpr.Value.(type) {
+	case int:
+		return ast.Integer
+	case bool:
+		return ast.Boolean
+	case string:
+		return ast.String
+	}
+	return ast.None
+}
@@ -0,0 +1,239 @@
+package cbackend
+
+import (
+	"chogopy/src/ast"
+	"fmt"
+	"strings"
+)
+
+var arithmeticFunctions = map[string]string{
+	"+":  "cgp_add",
+	"-":  "cgp_sub",
+	"*":  "cgp_mul",
+	"//": "cgp_div",
+	"%":  "cgp_mod",
+}
+
+func (g *Generator) VisitLiteralExpr(literalExpr *ast.LiteralExpr) {
+	g.lastValue = g.literal(literalExpr)
+}
+
+// VisitIdentExpr copies the value of a variable into a temporary so that later side effects
+// within the same expression do not change it.
+func (g *Generator) VisitIdentExpr(identExpr *ast.IdentExpr) {
+	variable, variableType := g.variable(identExpr.Identifier)
+	g.lastValue = g.newTemp(variableType, variable)
+}
+
+func (g *Generator) VisitUnaryExpr(unaryExpr *ast.UnaryExpr) {
+	value := g.evaluate(unaryExpr.Value)
+
+	switch unaryExpr.Op {
+	case "-":
+		g.lastValue = g.newTemp(intType, "cgp_neg("+value+")")
+	case "not":
+		g.lastValue = g.newTemp(boolType, "!"+value)
+	}
+}
+
+// VisitBinaryExpr only evaluates the right operand of "and" and "or" if the left operand does not determine the result.
+func (g *Generator) VisitBinaryExpr(binaryExpr *ast.BinaryExpr) {
+	lhs := g.evaluate(binaryExpr.Lhs)
+
+	switch binaryExpr.Op {
+	case "and", "or":
+		result := g.newTemp(boolType, lhs)
+		if binaryExpr.Op == "and" {
+			g.line("if (%s) {", result)
+		} else {
+			g.line("if (!%s) {", result)
+		}
+		g.block(func() {
+			rhs := g.evaluate(binaryExpr.Rhs)
+			g.line("%s = %s;", result, rhs)
+		})
+		g.line("}")
+		g.lastValue = result
+		return
+	}
+
+	rhs := g.evaluate(binaryExpr.Rhs)
+	operandType := ast.TypeHintOf(binaryExpr.Lhs)
+
+	var value string
+	switch binaryExpr.Op {
+	case "+":
+		switch binaryExpr.TypeHint.(type) {
+		case ast.ListAttribute:
+			value = fmt.Sprintf("cgp_list_concat(%s, %s, %s)", listClass(elementType(binaryExpr.TypeHint)), lhs, rhs)
+		default:
+			if binaryExpr.TypeHint == ast.String {
+				value = fmt.Sprintf("cgp_str_concat(%s, %s)", lhs, rhs)
+			} else {
+				value = fmt.Sprintf("cgp_add(%s, %s)", lhs, rhs)
+			}
+		}
+	case "-", "*", "//", "%":
+		value = fmt.Sprintf("%s(%s, %s)", arithmeticFunctions[binaryExpr.Op], lhs, rhs)
+	case "==", "!=":
+		if operandType == ast.String {
+			value = fmt.Sprintf("cgp_str_eq(%s, %s)", lhs, rhs)
+			if binaryExpr.Op == "!=" {
+				value = "!" + value
+			}
+		} else {
+			value = fmt.Sprintf("%s %s %s", lhs, binaryExpr.Op, rhs)
+		}
+	case "is":
+		value = fmt.Sprintf("%s == %s", lhs, rhs)
+	default:
+		value = fmt.Sprintf("%s %s %s", lhs, binaryExpr.Op, rhs)
+	}
+	g.lastValue = g.newTemp(cTypeOf(binaryExpr.TypeHint), value)
+}
+
+func (g *Generator) VisitIfExpr(ifExpr *ast.IfExpr) {
+	resultType := cTypeOf(ifExpr.TypeHint)
+	condition := g.evaluate(ifExpr.Condition)
+	result := g.newTemp(resultType, "")
+
+	g.line("if (%s) {", condition)
+	g.block(func() {
+		value := g.evaluate(ifExpr.IfNode)
+		g.line("%s = %s;", result, convert(value, ast.TypeHintOf(ifExpr.IfNode), resultType))
+	})
+	g.line("} else {")
+	g.block(func() {
+		value := g.evaluate(ifExpr.ElseNode)
+		g.line("%s = %s;", result, convert(value, ast.TypeHintOf(ifExpr.ElseNode), resultType))
+	})
+	g.line("}")
+	g.lastValue = result
+}
+
+func (g *Generator) VisitListExpr(listExpr *ast.ListExpr) {
+	elemType := elementType(listExpr.TypeHint)
+	list := g.newTemp(objectType, fmt.Sprintf("cgp_list_new(%s, %d)", listClass(elemType), len(listExpr.Elements)))
+
+	for elementIdx, element := range listExpr.Elements {
+		value := g.evaluate(element)
+		g.line("((cgp_list *)%s)->elements[%d].%s = %s;", list, elementIdx, elementField(elemType),
+			convert(value, ast.TypeHintOf(element), cTypeOf(elemType)))
+	}
+	g.lastValue = list
+}
+
+// VisitCallExpr calls a function, constructs an object of a class or calls one of the builtin functions.
+func (g *Generator) VisitCallExpr(callExpr *ast.CallExpr) {
+	arguments := []string{}
+	for _, argument := range callExpr.Arguments {
+		arguments = append(arguments, g.evaluate(argument))
+	}
+
+	hops := 0
+	for scope := g.scope; scope != nil; scope = scope.parent {
+		function, ok := scope.functions[callExpr.FuncName]
+		if !ok {
+			hops++
+			continue
+		}
+
+		for argumentIdx, argument := range callExpr.Arguments {
+			arguments[argumentIdx] = convert(arguments[argumentIdx], ast.TypeHintOf(argument), function.paramTypes[argumentIdx])
+		}
+		// Functions that are nested in other functions get the frame of the function that defines them as their static link
+		if function.depth > 1 {
+			arguments = append([]string{g.frame(hops)}, arguments...)
+		}
+		g.lastValue = g.newTemp(function.returnType, function.cName+"("+strings.Join(arguments, ", ")+")")
+		return
+	}
+
+	if _, ok := g.classes[callExpr.FuncName]; ok {
+		if callExpr.FuncName == "object" {
+			g.lastValue = g.newTemp(objectType, "cgp_new_object()")
+		} else {
+			g.lastValue = g.newTemp(objectType, "new_"+callExpr.FuncName+"()")
+		}
+		return
+	}
+
+	switch callExpr.FuncName {
+	case "print":
+		switch argumentType := ast.TypeHintOf(callExpr.Arguments[0]); argumentType {
+		case ast.Integer:
+			g.line("cgp_print_int(%s);", arguments[0])
+		case ast.Boolean:
+			g.line("cgp_print_bool(%s);", arguments[0])
+		default:
+			g.line("cgp_print(%s);", arguments[0])
+		}
+		g.lastValue = "NULL"
+	case "len":
+		argument := convert(arguments[0], ast.TypeHintOf(callExpr.Arguments[0]), objectType)
+		g.lastValue = g.newTemp(intType, "cgp_len("+argument+")")
+	case "input":
+		g.lastValue = g.newTemp(objectType, "cgp_input()")
+	}
+}
+
+// VisitIndexExpr indexes a list or returns the character of a string at the given index as a new string.
+func (g *Generator) VisitIndexExpr(indexExpr *ast.IndexExpr) {
+	valueType := ast.TypeHintOf(indexExpr.Value)
+	value := g.evaluate(indexExpr.Value)
+	index := g.evaluate(indexExpr.Index)
+
+	if valueType == ast.String {
+		g.lastValue = g.newTemp(objectType, fmt.Sprintf("cgp_str_index(%s, %s)", value, index))
+		return
+	}
+	elemType := elementType(valueType)
+	g.lastValue = g.newTemp(cTypeOf(elemType), fmt.Sprintf("cgp_list_at(%s, %s)->%s", value, index, elementField(elemType)))
+}
+
+func (g *Generator) VisitMemberExpr(memberExpr *ast.MemberExpr) {
+	object := g.evaluate(memberExpr.Object)
+	member, memberType := g.member(memberExpr, object)
+	g.lastValue = g.newTemp(memberType, member)
+}
+
+// member returns the C expression that refers to the attribute of the given object together with its C type.
+// The object is cast to the class that declares the attribute since inherited attributes are part of the
+// struct of the superclass, which is the first member of the struct of every subclass.
+func (g *Generator) member(memberExpr *ast.MemberExpr, object string) (string, string) {
+	for _, attribute := range g.classOf(memberExpr.Object).attributes {
+		typedVar := attribute.varDef.TypedVar.(*ast.TypedVar)
+		if typedVar.VarName == memberExpr.MemberName {
+			member := fmt.Sprintf("((struct object_%s *)cgp_check_member(%s))->%s", attribute.class.name, object, varName(typedVar.VarName))
+			return member, cType(typedVar.VarType)
+		}
+	}
+	return "", ""
+}
+
+// VisitMethodCallExpr looks up the method in the dispatch table of the class of the receiver.
+// The method is looked up before the arguments are evaluated so that calling a method of None fails right away.
+func (g *Generator) VisitMethodCallExpr(methodCallExpr *ast.MethodCallExpr) {
+	receiver := g.evaluate(methodCallExpr.Receiver)
+	class := g.classOf(methodCallExpr.Receiver)
+	slot := class.methodSlots[methodCallExpr.MethodName]
+	method := class.methods[slot]
+	pointer := g.newTemp("cgp_method", fmt.Sprintf("cgp_method_of(%s, %d)", receiver, slot))
+
+	arguments := []string{receiver}
+	for argumentIdx, argument := range methodCallExpr.Arguments {
+		value := g.evaluate(argument)
+		arguments = append(arguments, convert(value, ast.TypeHintOf(argument), method.paramTypes[argumentIdx+1]))
+	}
+
+	functionType := declaration(method.returnType, "(*)("+strings.Join(method.paramTypes, ", ")+")")
+	g.lastValue = g.newTemp(method.returnType, fmt.Sprintf("((%s)%s)(%s)", functionType, pointer, strings.Join(arguments, ", ")))
+}
+
+// classOf returns the class that the type checker inferred for the given expression.
+func (g *Generator) classOf(expression ast.Node) *classInfo {
+	if classType, ok := ast.TypeHintOf(expression).(ast.ClassAttribute); ok {
+		return g.classes[classType.ClassName]
+	}
+	return g.classes["object"]
+}
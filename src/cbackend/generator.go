@@ -0,0 +1,519 @@
+// Package cbackend generates portable C99 from a statically typed AST.
+// It is an alternative to the LLVM code generator for machines that only have a C toolchain.
+//
+// The generated translation unit contains a small runtime (runtime/runtime.c) for strings, lists,
+// objects and runtime errors, followed by the program itself. Every ChocoPy function becomes a C function.
+// Nested functions receive a pointer to the frame of their enclosing function as their first parameter,
+// through which they access the variables of the enclosing functions.
+package cbackend
+
+import (
+	"chogopy/src/ast"
+	"chogopy/src/codegen"
+	_ "embed"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+)
+
+//go:embed runtime/runtime.c
+var runtime string
+
+// The C types that values of ChocoPy types are represented with.
+const (
+	intType    = "int32_t"
+	boolType   = "bool"
+	objectType = "cgp_object *"
+)
+
+// funcInfo describes the C function that a function or method is compiled into.
+type funcInfo struct {
+	cName      string
+	returnType string
+	paramTypes []string
+	// signature is the declaration of the C function without the storage class.
+	signature string
+	// depth is 1 for global functions and methods and increases by one for every level of nesting.
+	depth int
+	// hasFrame is set if the function defines nested functions. Its variables are then stored in a frame
+	// that the nested functions can access.
+	hasFrame bool
+}
+
+// attribute is an attribute of a class together with the class that declares it.
+type attribute struct {
+	varDef *ast.VarDef
+	class  *classInfo
+}
+
+// classInfo describes how the objects of a class are laid out.
+// Inherited attributes and methods come first so that an object of a subclass can be used wherever
+// an object of its superclass is expected.
+type classInfo struct {
+	name       string
+	superClass *classInfo
+	attributes []attribute
+	methods    []*funcInfo
+	// methodSlots maps the name of every method to its slot in the dispatch table.
+	methodSlots map[string]int
+}
+
+// scope holds the names that are visible in the function whose code is currently generated.
+type scope struct {
+	parent   *scope
+	function *funcInfo
+	// locals maps the names of the parameters and local variables to their C types.
+	locals      map[string]string
+	globalNames map[string]bool
+	functions   map[string]*funcInfo
+}
+
+func newScope(parent *scope, function *funcInfo) *scope {
+	return &scope{
+		parent:      parent,
+		function:    function,
+		locals:      map[string]string{},
+		globalNames: map[string]bool{},
+		functions:   map[string]*funcInfo{},
+	}
+}
+
+// Generator generates C source code from a program that has been annotated by typechecks.StaticTyping.
+// The generated code can be found in Source once Generate has been called.
+type Generator struct {
+	Source string
+
+	declarations strings.Builder
+	definitions  strings.Builder
+
+	globals   map[string]string
+	classes   map[string]*classInfo
+	funcInfos map[*ast.FuncDef]*funcInfo
+	strings   map[string]string
+	functions int
+	temps     int
+
+	scope  *scope
+	body   *strings.Builder
+	indent int
+
+	lastValue string
+	ast.BaseVisitor
+}
+
+func (g *Generator) Generate(program *ast.Program) {
+	g.globals = map[string]string{}
+	g.classes = map[string]*classInfo{}
+	g.funcInfos = map[*ast.FuncDef]*funcInfo{}
+	g.strings = map[string]string{}
+
+	main := &funcInfo{cName: "main", returnType: "int"}
+	g.scope = newScope(nil, main)
+	g.classes["object"] = &classInfo{
+		name:        "object",
+		methods:     []*funcInfo{{cName: "cgp_object_init", returnType: objectType, paramTypes: []string{objectType}}},
+		methodSlots: map[string]int{"__init__": 0},
+	}
+
+	// Every global definition is declared up front since functions
+	// may refer to globals, functions and classes that are defined further down
+	for _, definition := range program.Definitions {
+		switch definition := definition.(type) {
+		case *ast.VarDef:
+			typedVar := definition.TypedVar.(*ast.TypedVar)
+			g.globals[typedVar.VarName] = cType(typedVar.VarType)
+			fmt.Fprintf(&g.declarations, "static %s;\n", declaration(cType(typedVar.VarType), varName(typedVar.VarName)))
+		case *ast.FuncDef:
+			g.scope.functions[definition.FuncName] = g.declareFunction(definition, definition.FuncName, 1)
+		case *ast.ClassDef:
+			g.declareClass(definition)
+		}
+	}
+
+	body := strings.Builder{}
+	g.body = &body
+	g.indent = 1
+	for _, definition := range program.Definitions {
+		definition.Visit(g)
+	}
+	g.generateBody(program.Statements)
+
+	source := strings.Builder{}
+	source.WriteString(runtimeErrors())
+	source.WriteString(runtime)
+	source.WriteString("\n/* Program */\n\n")
+	source.WriteString(g.declarations.String())
+	source.WriteString("\n")
+	source.WriteString(g.definitions.String())
+	source.WriteString("int main(void) {\n")
+	source.WriteString(body.String())
+	source.WriteString("\treturn 0;\n}\n")
+	g.Source = source.String()
+}
+
+// Traverse is disabled since the generator decides on its own in which order the child nodes of a node are visited.
+func (g *Generator) Traverse() bool {
+	return false
+}
+
+// runtimeErrors defines a macro for every runtime error that expands to the arguments of cgp_raise.
+func runtimeErrors() string {
+	macros := strings.Builder{}
+	for _, errorName := range slices.Sorted(maps.Keys(codegen.RuntimeErrors)) {
+		runtimeError := codegen.RuntimeErrors[errorName]
+		macroName := "CGP_" + strings.ToUpper(errorName)
+		fmt.Fprintf(&macros, "#define %s %d, %s\n", macroName, runtimeError.Kind, cString(runtimeError.Message))
+	}
+	return macros.String() + "\n"
+}
+
+// declareFunction declares the C function that the given function or method is compiled into.
+// Its code is generated once its definition is visited.
+func (g *Generator) declareFunction(funcDef *ast.FuncDef, name string, depth int) *funcInfo {
+	info := &funcInfo{
+		cName:      fmt.Sprintf("f%d_%s", g.functions, name),
+		returnType: cType(funcDef.ReturnType),
+		depth:      depth,
+	}
+	g.functions++
+
+	for _, bodyNode := range funcDef.FuncBody {
+		if _, ok := bodyNode.(*ast.FuncDef); ok {
+			info.hasFrame = true
+		}
+	}
+
+	params := []string{}
+	if depth > 1 {
+		fmt.Fprintf(&g.declarations, "struct frame_%s;\n", g.scope.function.cName)
+		params = append(params, fmt.Sprintf("struct frame_%s *up", g.scope.function.cName))
+	}
+	for _, param := range funcDef.Parameters {
+		typedVar := param.(*ast.TypedVar)
+		info.paramTypes = append(info.paramTypes, cType(typedVar.VarType))
+		params = append(params, declaration(cType(typedVar.VarType), varName(typedVar.VarName)))
+	}
+	if len(params) == 0 {
+		params = append(params, "void")
+	}
+	info.signature = declaration(info.returnType, info.cName+"("+strings.Join(params, ", ")+")")
+	fmt.Fprintf(&g.declarations, "static %s;\n", info.signature)
+
+	g.funcInfos[funcDef] = info
+	return info
+}
+
+// declareClass lays out the objects and the dispatch table of a class
+// and declares the function that constructs its objects.
+func (g *Generator) declareClass(classDef *ast.ClassDef) {
+	superClass := g.classes[classDef.SuperClass]
+	class := &classInfo{
+		name:        classDef.ClassName,
+		superClass:  superClass,
+		attributes:  slices.Clone(superClass.attributes),
+		methods:     slices.Clone(superClass.methods),
+		methodSlots: maps.Clone(superClass.methodSlots),
+	}
+
+	fields := strings.Builder{}
+	if superClass.name == "object" {
+		fields.WriteString("\tcgp_object header;\n")
+	} else {
+		fmt.Fprintf(&fields, "\tstruct object_%s base;\n", superClass.name)
+	}
+
+	for _, definition := range classDef.ClassBody {
+		switch definition := definition.(type) {
+		case *ast.VarDef:
+			typedVar := definition.TypedVar.(*ast.TypedVar)
+			class.attributes = append(class.attributes, attribute{varDef: definition, class: class})
+			fmt.Fprintf(&fields, "\t%s;\n", declaration(cType(typedVar.VarType), varName(typedVar.VarName)))
+		case *ast.FuncDef:
+			method := g.declareFunction(definition, classDef.ClassName+"_"+definition.FuncName, 1)
+			if slot, ok := class.methodSlots[definition.FuncName]; ok {
+				class.methods[slot] = method
+			} else {
+				class.methodSlots[definition.FuncName] = len(class.methods)
+				class.methods = append(class.methods, method)
+			}
+		}
+	}
+
+	fmt.Fprintf(&g.declarations, "struct object_%s {\n%s};\n", class.name, fields.String())
+	fmt.Fprintf(&g.declarations, "static cgp_object *new_%s(void);\n", class.name)
+	g.classes[class.name] = class
+}
+
+// generateFunction generates the C function that a function or method is compiled into.
+// Nested functions are declared before the body is generated so that they can call each other.
+func (g *Generator) generateFunction(funcDef *ast.FuncDef) {
+	info := g.funcInfos[funcDef]
+	outerScope := g.scope
+	outerBody := g.body
+	outerIndent := g.indent
+	g.scope = newScope(outerScope, info)
+	defer func() {
+		g.scope = outerScope
+		g.body = outerBody
+		g.indent = outerIndent
+	}()
+
+	body := strings.Builder{}
+	g.body = &body
+	g.indent = 1
+
+	frame := strings.Builder{}
+	if info.depth > 1 {
+		fmt.Fprintf(&frame, "\tstruct frame_%s *up;\n", outerScope.function.cName)
+	}
+	for _, param := range funcDef.Parameters {
+		typedVar := param.(*ast.TypedVar)
+		g.scope.locals[typedVar.VarName] = cType(typedVar.VarType)
+		fmt.Fprintf(&frame, "\t%s;\n", declaration(cType(typedVar.VarType), varName(typedVar.VarName)))
+	}
+	for _, bodyNode := range funcDef.FuncBody {
+		switch bodyNode := bodyNode.(type) {
+		case *ast.VarDef:
+			typedVar := bodyNode.TypedVar.(*ast.TypedVar)
+			g.scope.locals[typedVar.VarName] = cType(typedVar.VarType)
+			fmt.Fprintf(&frame, "\t%s;\n", declaration(cType(typedVar.VarType), varName(typedVar.VarName)))
+			if !info.hasFrame {
+				g.line("%s;", declaration(cType(typedVar.VarType), varName(typedVar.VarName)))
+			}
+		case *ast.GlobalDecl:
+			g.scope.globalNames[bodyNode.DeclName] = true
+		case *ast.FuncDef:
+			g.scope.functions[bodyNode.FuncName] = g.declareFunction(bodyNode, bodyNode.FuncName, info.depth+1)
+		}
+	}
+
+	// The parameters are copied into the frame so that nested functions can access them
+	if info.hasFrame {
+		fmt.Fprintf(&g.declarations, "struct frame_%s {\n%s};\n", info.cName, frame.String())
+		g.line("struct frame_%s frame;", info.cName)
+		if info.depth > 1 {
+			g.line("frame.up = up;")
+		}
+		for _, param := range funcDef.Parameters {
+			name := varName(param.(*ast.TypedVar).VarName)
+			g.line("frame.%s = %s;", name, name)
+		}
+	}
+
+	g.generateBody(funcDef.FuncBody)
+	if len(funcDef.FuncBody) == 0 {
+		g.line("return %s;", zeroValue(info.returnType))
+	} else if _, ok := funcDef.FuncBody[len(funcDef.FuncBody)-1].(*ast.ReturnStmt); !ok {
+		g.line("return %s;", zeroValue(info.returnType))
+	}
+
+	fmt.Fprintf(&g.definitions, "static %s {\n%s}\n\n", info.signature, body.String())
+}
+
+// line appends a line of code to the function whose code is currently generated.
+func (g *Generator) line(format string, args ...any) {
+	g.body.WriteString(strings.Repeat("\t", g.indent))
+	fmt.Fprintf(g.body, format, args...)
+	g.body.WriteString("\n")
+}
+
+// block generates a block of code, e.g. the body of an if statement, with one more level of indentation.
+func (g *Generator) block(generate func()) {
+	g.indent++
+	generate()
+	g.indent--
+}
+
+// evaluate generates the code of an expression and returns the C expression that holds its value.
+func (g *Generator) evaluate(expression ast.Node) string {
+	expression.Visit(g)
+	return g.lastValue
+}
+
+// newTemp stores the given C expression in a new temporary variable.
+// Expressions are evaluated into temporaries so that their side effects happen from left to right.
+func (g *Generator) newTemp(cType string, value string) string {
+	temp := fmt.Sprintf("t%d", g.temps)
+	g.temps++
+	if value == "" {
+		g.line("%s;", declaration(cType, temp))
+	} else {
+		g.line("%s = %s;", declaration(cType, temp), value)
+	}
+	return temp
+}
+
+// variable returns the C expression that refers to the variable with the given name together with its C type.
+// Variables of enclosing functions are found by following the static links of the frames.
+func (g *Generator) variable(name string) (string, string) {
+	hops := 0
+	for scope := g.scope; scope.parent != nil; scope = scope.parent {
+		if scope.globalNames[name] {
+			break
+		}
+		if cType, ok := scope.locals[name]; ok {
+			switch {
+			case hops > 0:
+				return g.frame(hops) + "->" + varName(name), cType
+			case scope.function.hasFrame:
+				return "frame." + varName(name), cType
+			}
+			return varName(name), cType
+		}
+		hops++
+	}
+	return varName(name), g.globals[name]
+}
+
+// frame returns a pointer to the frame of the function that is the given number of scopes away from the current one.
+func (g *Generator) frame(hops int) string {
+	if hops == 0 {
+		return "&frame"
+	}
+	return "up" + strings.Repeat("->up", hops-1)
+}
+
+// stringConstant returns a pointer to a statically allocated string with the given value.
+func (g *Generator) stringConstant(value string) string {
+	if name, ok := g.strings[value]; ok {
+		return name
+	}
+	name := fmt.Sprintf("string%d", len(g.strings))
+	fmt.Fprintf(&g.declarations, "static cgp_str %s = {{&cgp_str_class}, %d, %s};\n", name, len(value), cString(value))
+	g.strings[value] = "&" + name + ".header"
+	return g.strings[value]
+}
+
+// literal returns the C expression of a literal.
+func (g *Generator) literal(literalExpr *ast.LiteralExpr) string {
+	switch value := literalExpr.Value.(type) {
+	case int:
+		if int32(value) == math.MinInt32 {
+			return "INT32_MIN"
+		}
+		return fmt.Sprint(int32(value))
+	case bool:
+		return fmt.Sprint(value)
+	case string:
+		return g.stringConstant(value)
+	}
+	return "NULL"
+}
+
+// convert boxes integers and booleans that are used where an object is expected.
+func convert(value string, typeHint ast.TypeAttr, cType string) string {
+	if cType != objectType {
+		return value
+	}
+	switch typeHint {
+	case ast.Integer:
+		return "cgp_box_int(" + value + ")"
+	case ast.Boolean:
+		return "cgp_box_bool(" + value + ")"
+	}
+	return value
+}
+
+// cType returns the C type that values of the type given by a type annotation are represented with.
+func cType(typeNode ast.Node) string {
+	if namedType, ok := typeNode.(*ast.NamedType); ok {
+		switch namedType.TypeName {
+		case "int":
+			return intType
+		case "bool":
+			return boolType
+		}
+	}
+	return objectType
+}
+
+// cTypeOf returns the C type that values of the given type are represented with.
+func cTypeOf(typeHint ast.TypeAttr) string {
+	switch typeHint {
+	case ast.Integer:
+		return intType
+	case ast.Boolean:
+		return boolType
+	}
+	return objectType
+}
+
+// listClass returns the class of lists whose elements are of the given type.
+func listClass(elemType ast.TypeAttr) string {
+	switch elemType {
+	case ast.Integer:
+		return "&cgp_int_list_class"
+	case ast.Boolean:
+		return "&cgp_bool_list_class"
+	}
+	return "&cgp_list_class"
+}
+
+// elementField returns the field of cgp_value that holds the elements of lists whose elements are of the given type.
+func elementField(elemType ast.TypeAttr) string {
+	switch elemType {
+	case ast.Integer:
+		return "i"
+	case ast.Boolean:
+		return "b"
+	}
+	return "p"
+}
+
+// elementType returns the type of the elements of a list of the given type.
+func elementType(listType ast.TypeAttr) ast.TypeAttr {
+	if listType, ok := listType.(ast.ListAttribute); ok {
+		return listType.ElemType
+	}
+	return ast.Object
+}
+
+// zeroValue returns the value that a function with the given return type returns if it ends without a return statement.
+func zeroValue(cType string) string {
+	switch cType {
+	case intType:
+		return "0"
+	case boolType:
+		return "false"
+	}
+	return "NULL"
+}
+
+// declaration declares a variable of the given C type.
+func declaration(cType string, name string) string {
+	if strings.HasSuffix(cType, "*") {
+		return cType + name
+	}
+	return cType + " " + name
+}
+
+// varName prefixes the names of variables so that they can not clash with keywords or the runtime.
+func varName(name string) string {
+	return "v_" + name
+}
+
+// cString returns a C string literal of the given string.
+// Question marks are escaped as well so that they can not form trigraphs.
+func cString(value string) string {
+	literal := strings.Builder{}
+	literal.WriteByte('"')
+	for _, character := range []byte(value) {
+		switch {
+		case character == '"' || character == '\\' || character == '?':
+			literal.WriteByte('\\')
+			literal.WriteByte(character)
+		case character == '\n':
+			literal.WriteString("\\n")
+		case character == '\t':
+			literal.WriteString("\\t")
+		case character < ' ' || character > '~':
+			fmt.Fprintf(&literal, "\\%03o", character)
+		default:
+			literal.WriteByte(character)
+		}
+	}
+	literal.WriteByte('"')
+	return literal.String()
+}
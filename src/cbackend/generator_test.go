@@ -0,0 +1,14 @@
+package cbackend
+
+import (
+	"testing"
+)
+
+func TestCString(t *testing.T) {
+	literal := cString("a\"b\\c??=\n\x01")
+
+	expected := `"a\"b\\c\?\?=\n\001"`
+	if literal != expected {
+		t.Fatalf("Expected %s but found %s.", expected, literal)
+	}
+}
@@ -0,0 +1,351 @@
+/*
+ * Runtime of programs that are compiled by the C backend of chogopy.
+ *
+ * Integers and booleans are stored as int32_t and bool. Every other value is a pointer to a
+ * cgp_object, where NULL stands for None. The header of every object points to the class of the object,
+ * which tells how the object is laid out. Integers and booleans are boxed when they are used as objects.
+ *
+ * The kinds and messages of the runtime errors are defined by the generated code in front of this file.
+ */
+
+#include <stdbool.h>
+#include <stdint.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+typedef struct cgp_class cgp_class;
+typedef struct cgp_object cgp_object;
+
+/* Methods are stored in dispatch tables as generic function pointers
+ * and are cast back to their actual type before they are called. */
+typedef void (*cgp_method)(void);
+
+enum cgp_kind { CGP_OBJECT, CGP_INT, CGP_BOOL, CGP_STR, CGP_LIST };
+
+struct cgp_class {
+	const char *name;
+	enum cgp_kind kind;
+	/* elements is the representation of the elements of lists. */
+	enum cgp_kind elements;
+	/* methods is the dispatch table of the class, which starts with __init__. */
+	const cgp_method *methods;
+};
+
+struct cgp_object {
+	const cgp_class *type;
+};
+
+typedef struct cgp_int {
+	cgp_object header;
+	int32_t value;
+} cgp_int;
+
+typedef struct cgp_bool {
+	cgp_object header;
+	bool value;
+} cgp_bool;
+
+typedef struct cgp_str {
+	cgp_object header;
+	int32_t length;
+	const char *data;
+} cgp_str;
+
+typedef union cgp_value {
+	int32_t i;
+	bool b;
+	cgp_object *p;
+} cgp_value;
+
+typedef struct cgp_list {
+	cgp_object header;
+	int32_t length;
+	cgp_value *elements;
+} cgp_list;
+
+static cgp_object *cgp_object_init(cgp_object *self) {
+	(void)self;
+	return NULL;
+}
+
+static const cgp_method cgp_object_methods[] = {(cgp_method)cgp_object_init};
+
+static const cgp_class cgp_object_class = {"object", CGP_OBJECT, CGP_OBJECT, cgp_object_methods};
+static const cgp_class cgp_int_class = {"int", CGP_INT, CGP_OBJECT, cgp_object_methods};
+static const cgp_class cgp_bool_class = {"bool", CGP_BOOL, CGP_OBJECT, cgp_object_methods};
+static const cgp_class cgp_str_class = {"str", CGP_STR, CGP_OBJECT, cgp_object_methods};
+static const cgp_class cgp_list_class = {"list", CGP_LIST, CGP_OBJECT, cgp_object_methods};
+static const cgp_class cgp_int_list_class = {"list", CGP_LIST, CGP_INT, cgp_object_methods};
+static const cgp_class cgp_bool_list_class = {"list", CGP_LIST, CGP_BOOL, cgp_object_methods};
+
+static cgp_bool cgp_true = {{&cgp_bool_class}, true};
+static cgp_bool cgp_false = {{&cgp_bool_class}, false};
+
+/* cgp_raise writes the message of a runtime error to stderr after any output of the program and exits. */
+static void cgp_raise(int kind, const char *message) {
+	fflush(stdout);
+	fprintf(stderr, "%s\n", message);
+	exit(kind);
+}
+
+static void *cgp_alloc(size_t size) {
+	void *memory = calloc(1, size);
+	if (memory == NULL) {
+		cgp_raise(CGP_ERROR_OUT_OF_MEMORY);
+	}
+	return memory;
+}
+
+static cgp_object *cgp_new_object(void) {
+	cgp_object *object = cgp_alloc(sizeof(cgp_object));
+	object->type = &cgp_object_class;
+	return object;
+}
+
+static cgp_object *cgp_check_member(cgp_object *object) {
+	if (object == NULL) {
+		cgp_raise(CGP_ERROR_MEMBER_NONE);
+	}
+	return object;
+}
+
+static cgp_method cgp_method_of(cgp_object *object, int slot) {
+	return cgp_check_member(object)->type->methods[slot];
+}
+
+/* Integers wrap around on overflow, which is why the arithmetic is carried out on unsigned integers. */
+static int32_t cgp_add(int32_t lhs, int32_t rhs) {
+	return (int32_t)((uint32_t)lhs + (uint32_t)rhs);
+}
+
+static int32_t cgp_sub(int32_t lhs, int32_t rhs) {
+	return (int32_t)((uint32_t)lhs - (uint32_t)rhs);
+}
+
+static int32_t cgp_mul(int32_t lhs, int32_t rhs) {
+	return (int32_t)((uint32_t)lhs * (uint32_t)rhs);
+}
+
+static int32_t cgp_neg(int32_t value) {
+	return (int32_t)(0u - (uint32_t)value);
+}
+
+/* cgp_div and cgp_mod round towards negative infinity like Python does. */
+static int32_t cgp_div(int32_t lhs, int32_t rhs) {
+	int32_t quotient;
+	if (rhs == 0) {
+		cgp_raise(CGP_ERROR_DIV_ZERO);
+	}
+	if (rhs == -1) {
+		return cgp_neg(lhs);
+	}
+	quotient = lhs / rhs;
+	if (lhs % rhs != 0 && (lhs < 0) != (rhs < 0)) {
+		quotient--;
+	}
+	return quotient;
+}
+
+static int32_t cgp_mod(int32_t lhs, int32_t rhs) {
+	int32_t remainder;
+	if (rhs == 0) {
+		cgp_raise(CGP_ERROR_DIV_ZERO);
+	}
+	if (rhs == -1) {
+		return 0;
+	}
+	remainder = lhs % rhs;
+	if (remainder != 0 && (remainder < 0) != (rhs < 0)) {
+		remainder += rhs;
+	}
+	return remainder;
+}
+
+static cgp_object *cgp_box_int(int32_t value) {
+	cgp_int *box = cgp_alloc(sizeof(cgp_int));
+	box->header.type = &cgp_int_class;
+	box->value = value;
+	return &box->header;
+}
+
+static cgp_object *cgp_box_bool(bool value) {
+	return value ? &cgp_true.header : &cgp_false.header;
+}
+
+/* cgp_str_alloc allocates a string of the given length whose characters are stored right behind it. */
+static cgp_str *cgp_str_alloc(int32_t length, char **data) {
+	cgp_str *str = cgp_alloc(sizeof(cgp_str) + (size_t)length + 1);
+	*data = (char *)(str + 1);
+	str->header.type = &cgp_str_class;
+	str->length = length;
+	str->data = *data;
+	return str;
+}
+
+/* cgp_str_new copies the given characters into a new string. */
+static cgp_object *cgp_str_new(const char *characters, int32_t length) {
+	char *data;
+	cgp_str *str = cgp_str_alloc(length, &data);
+	memcpy(data, characters, (size_t)length);
+	return &str->header;
+}
+
+static cgp_object *cgp_str_concat(cgp_object *lhs, cgp_object *rhs) {
+	cgp_str *lhsStr = (cgp_str *)lhs;
+	cgp_str *rhsStr = (cgp_str *)rhs;
+	char *data;
+	cgp_str *str = cgp_str_alloc(lhsStr->length + rhsStr->length, &data);
+	memcpy(data, lhsStr->data, (size_t)lhsStr->length);
+	memcpy(data + lhsStr->length, rhsStr->data, (size_t)rhsStr->length);
+	return &str->header;
+}
+
+static bool cgp_str_eq(cgp_object *lhs, cgp_object *rhs) {
+	cgp_str *lhsStr = (cgp_str *)lhs;
+	cgp_str *rhsStr = (cgp_str *)rhs;
+	return lhsStr->length == rhsStr->length && memcmp(lhsStr->data, rhsStr->data, (size_t)lhsStr->length) == 0;
+}
+
+static void cgp_check_index(int32_t index, int32_t length) {
+	if (index < 0) {
+		cgp_raise(CGP_ERROR_INDEX_NEG);
+	}
+	if (index >= length) {
+		cgp_raise(CGP_ERROR_INDEX_OOB);
+	}
+}
+
+/* cgp_str_index returns the character of a string at the given index as a new string. */
+static cgp_object *cgp_str_index(cgp_object *str, int32_t index) {
+	if (str == NULL) {
+		cgp_raise(CGP_ERROR_INDEX_NONE);
+	}
+	cgp_check_index(index, ((cgp_str *)str)->length);
+	return cgp_str_new(((cgp_str *)str)->data + index, 1);
+}
+
+static cgp_object *cgp_list_new(const cgp_class *type, int32_t length) {
+	cgp_list *list = cgp_alloc(sizeof(cgp_list) + (size_t)length * sizeof(cgp_value));
+	list->header.type = type;
+	list->length = length;
+	list->elements = (cgp_value *)(list + 1);
+	return &list->header;
+}
+
+/* cgp_list_at returns the element of a list at the given index so that it can be read or written. */
+static cgp_value *cgp_list_at(cgp_object *list, int32_t index) {
+	if (list == NULL) {
+		cgp_raise(CGP_ERROR_INDEX_NONE);
+	}
+	cgp_check_index(index, ((cgp_list *)list)->length);
+	return &((cgp_list *)list)->elements[index];
+}
+
+/* cgp_box_element returns the element of a list as an object. */
+static cgp_object *cgp_box_element(cgp_list *list, int32_t index) {
+	switch (list->header.type->elements) {
+	case CGP_INT:
+		return cgp_box_int(list->elements[index].i);
+	case CGP_BOOL:
+		return cgp_box_bool(list->elements[index].b);
+	default:
+		return list->elements[index].p;
+	}
+}
+
+/* cgp_list_concat concatenates two lists into a new list of the given type.
+ * Integers and booleans are boxed if the new list holds objects. */
+static cgp_object *cgp_list_concat(const cgp_class *type, cgp_object *lhs, cgp_object *rhs) {
+	cgp_list *lists[2];
+	cgp_list *result;
+	int32_t length = 0;
+	int listIdx, index;
+
+	if (lhs == NULL || rhs == NULL) {
+		cgp_raise(CGP_ERROR_LEN_NONE);
+	}
+	lists[0] = (cgp_list *)lhs;
+	lists[1] = (cgp_list *)rhs;
+
+	result = (cgp_list *)cgp_list_new(type, lists[0]->length + lists[1]->length);
+	for (listIdx = 0; listIdx < 2; listIdx++) {
+		for (index = 0; index < lists[listIdx]->length; index++) {
+			if (type->elements == CGP_OBJECT) {
+				result->elements[length++].p = cgp_box_element(lists[listIdx], index);
+			} else {
+				result->elements[length++] = lists[listIdx]->elements[index];
+			}
+		}
+	}
+	return &result->header;
+}
+
+static int32_t cgp_len(cgp_object *object) {
+	if (object == NULL) {
+		cgp_raise(CGP_ERROR_LEN_NONE);
+	}
+	switch (object->type->kind) {
+	case CGP_STR:
+		return ((cgp_str *)object)->length;
+	case CGP_LIST:
+		return ((cgp_list *)object)->length;
+	default:
+		cgp_raise(CGP_ERROR_INVALID_ARGUMENT);
+		return 0;
+	}
+}
+
+/* cgp_input reads a line from stdin without the trailing newline.
+ * An empty string is returned once the end of the input has been reached. */
+static cgp_object *cgp_input(void) {
+	size_t capacity = 64;
+	size_t length = 0;
+	char *line = cgp_alloc(capacity);
+	int c;
+
+	while ((c = getchar()) != EOF && c != '\n') {
+		if (length == capacity) {
+			capacity *= 2;
+			line = realloc(line, capacity);
+			if (line == NULL) {
+				cgp_raise(CGP_ERROR_OUT_OF_MEMORY);
+			}
+		}
+		line[length++] = (char)c;
+	}
+	if (length > 0 && line[length - 1] == '\r') {
+		length--;
+	}
+	return cgp_str_new(line, (int32_t)length);
+}
+
+/* cgp_print prints a value on its own line. Just like in compiled programs, only integers, booleans and strings
+ * can be printed, which are boxed if they are used as objects. */
+static void cgp_print(cgp_object *object) {
+	if (object == NULL) {
+		cgp_raise(CGP_ERROR_INVALID_ARGUMENT);
+	}
+	switch (object->type->kind) {
+	case CGP_INT:
+		printf("%d\n", (int)((cgp_int *)object)->value);
+		break;
+	case CGP_BOOL:
+		puts(((cgp_bool *)object)->value ? "True" : "False");
+		break;
+	case CGP_STR:
+		fwrite(((cgp_str *)object)->data, 1, (size_t)((cgp_str *)object)->length, stdout);
+		putchar('\n');
+		break;
+	default:
+		cgp_raise(CGP_ERROR_INVALID_ARGUMENT);
+	}
+}
+
+static void cgp_print_int(int32_t value) {
+	printf("%d\n", (int)value);
+}
+
+static void cgp_print_bool(bool value) {
+	puts(value ? "True" : "False");
+}
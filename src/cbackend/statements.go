@@ -0,0 +1,107 @@
+package cbackend
+
+import "chogopy/src/ast"
+
+// generateBody generates the code of the given statements.
+// The values of expressions that are used as statements are discarded.
+func (g *Generator) generateBody(statements []ast.Node) {
+	for _, statement := range statements {
+		statement.Visit(g)
+		if ast.TypeHintOf(statement) != nil && g.lastValue != "NULL" {
+			g.line("(void)%s;", g.lastValue)
+		}
+	}
+}
+
+func (g *Generator) VisitIfStmt(ifStmt *ast.IfStmt) {
+	condition := g.evaluate(ifStmt.Condition)
+	g.line("if (%s) {", condition)
+	g.block(func() { g.generateBody(ifStmt.IfBody) })
+
+	if len(ifStmt.ElseBody) > 0 {
+		g.line("} else {")
+		g.block(func() { g.generateBody(ifStmt.ElseBody) })
+	}
+	g.line("}")
+}
+
+// VisitWhileStmt evaluates the condition inside of the loop since its evaluation may take several statements.
+func (g *Generator) VisitWhileStmt(whileStmt *ast.WhileStmt) {
+	g.line("for (;;) {")
+	g.block(func() {
+		condition := g.evaluate(whileStmt.Condition)
+		g.line("if (!%s) {", condition)
+		g.block(func() { g.line("break;") })
+		g.line("}")
+		g.generateBody(whileStmt.Body)
+	})
+	g.line("}")
+}
+
+// VisitForStmt loops over the indices of the iterable. Its length is checked before every iteration
+// since the body of the loop may change the length of a list.
+func (g *Generator) VisitForStmt(forStmt *ast.ForStmt) {
+	iterType := ast.TypeHintOf(forStmt.Iter)
+	iterable := g.evaluate(forStmt.Iter)
+	index := g.newTemp(intType, "0")
+	target, targetType := g.variable(forStmt.IterName)
+
+	g.line("for (; %s < cgp_len(%s); %s++) {", index, iterable, index)
+	g.block(func() {
+		if iterType == ast.String {
+			g.line("%s = cgp_str_index(%s, %s);", target, iterable, index)
+		} else {
+			elemType := elementType(iterType)
+			element := "cgp_list_at(" + iterable + ", " + index + ")->" + elementField(elemType)
+			g.line("%s = %s;", target, convert(element, elemType, targetType))
+		}
+		g.generateBody(forStmt.Body)
+	})
+	g.line("}")
+}
+
+func (g *Generator) VisitPassStmt(passStmt *ast.PassStmt) {}
+
+func (g *Generator) VisitReturnStmt(returnStmt *ast.ReturnStmt) {
+	if returnStmt.ReturnVal == nil {
+		g.line("return NULL;")
+		return
+	}
+	value := g.evaluate(returnStmt.ReturnVal)
+	g.line("return %s;", convert(value, ast.TypeHintOf(returnStmt.ReturnVal), g.scope.function.returnType))
+}
+
+// VisitAssignStmt evaluates the value of an assignment once and then assigns it to every target from left to right.
+// The targets of a multiple assignment such as a = b = 1 are parsed as nested assignments.
+func (g *Generator) VisitAssignStmt(assignStmt *ast.AssignStmt) {
+	targets := []ast.Node{assignStmt.Target}
+	valueNode := assignStmt.Value
+	for {
+		nestedAssign, ok := valueNode.(*ast.AssignStmt)
+		if !ok {
+			break
+		}
+		targets = append(targets, nestedAssign.Target)
+		valueNode = nestedAssign.Value
+	}
+
+	valueType := ast.TypeHintOf(valueNode)
+	value := g.evaluate(valueNode)
+
+	for _, target := range targets {
+		switch target := target.(type) {
+		case *ast.IdentExpr:
+			variable, variableType := g.variable(target.Identifier)
+			g.line("%s = %s;", variable, convert(value, valueType, variableType))
+		case *ast.IndexExpr:
+			elemType := elementType(ast.TypeHintOf(target.Value))
+			list := g.evaluate(target.Value)
+			index := g.evaluate(target.Index)
+			g.line("cgp_list_at(%s, %s)->%s = %s;", list, index, elementField(elemType), convert(value, valueType, cTypeOf(elemType)))
+		case *ast.MemberExpr:
+			object := g.evaluate(target.Object)
+			member, memberType := g.member(target, object)
+			g.line("%s = %s;", member, convert(value, valueType, memberType))
+		}
+	}
+}
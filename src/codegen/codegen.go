@@ -1,5 +1,10 @@
 // Package codegen implements methods for converting
 // an AST into a flattened series of LLVM IR instructions.
+//
+// Integers are represented as i32 values. Addition, subtraction, multiplication and negation
+// wrap around on overflow in two's complement, so 2147483647 + 1 is -2147483648.
+// Integer division and modulo round towards negative infinity, and -2147483648 // -1 wraps around
+// to -2147483648 as well. The interpreter, the bytecode VM and the C backend implement the same semantics.
 package codegen
 
 import (
@@ -32,25 +37,62 @@ type VarInfo struct {
 	init     constant.Constant
 }
 
+// ClassInfo contains everything needed to construct objects of a class and to dispatch its methods.
+// Objects are laid out as a pointer to the dispatch table of their class followed by their attributes,
+// where the attributes and methods inherited from the superclass always come first.
+// This way, an object of a subclass can be used wherever an object of its superclass is expected.
+//
+//	class.A  -->  {vtable.A*, attr0, attr1, ...}
+//	vtable.A -->  {__init__*, method0*, method1*, ...}
+type ClassInfo struct {
+	name       string
+	superClass *ClassInfo
+
+	structType *types.StructType
+	vtableType *types.StructType
+
+	attributes  []*ast.VarDef
+	methodNames []string
+	methodDefs  []*ast.FuncDef
+	slotTypes   []*types.FuncType
+
+	vtable      *ir.Global
+	methods     []*ir.Func
+	constructor *ir.Func
+}
+
 type (
 	Strings   map[string]*ir.Global
 	Functions map[string]*ir.Func
 	VarCtx    map[*ir.Func]Variables
 	Variables map[string]VarInfo
 	Types     map[string]types.Type
+	Classes   map[string]*ClassInfo
+	FuncInfos map[*ir.Func]*FuncInfo
 )
 
 type CodeGenerator struct {
-	Module      *ir.Module
+	Module *ir.Module
+
+	// TargetTriple and DataLayout describe the target that the module is generated for.
+	// The sizes of the types that are allocated or copied at runtime are derived from the data layout
+	// so that the module is correct for any target. Both are left out of the module if they are empty.
+	TargetTriple string
+	DataLayout   string
+
 	uniqueNames UniqueNames
 
 	types     Types
+	classes   Classes
 	strings   Strings
 	functions Functions
 
 	varContext VarCtx
 	heapAllocs []value.Value
 
+	funcInfos          FuncInfos
+	enclosingFunctions map[*ir.Func]*ir.Func
+
 	mainFunction *ir.Func
 	mainBlock    *ir.Block
 
@@ -66,8 +108,11 @@ func (cg *CodeGenerator) Generate(program *ast.Program) {
 	typeEnvBuilder.Build(program)
 
 	cg.Module = typeEnvBuilder.Module
+	cg.Module.TargetTriple = cg.TargetTriple
+	cg.Module.DataLayout = cg.DataLayout
 	cg.uniqueNames = typeEnvBuilder.uniqueNames
 	cg.types = typeEnvBuilder.types
+	cg.classes = typeEnvBuilder.classes
 
 	cg.strings = Strings{}
 	cg.functions = Functions{}
@@ -76,18 +121,24 @@ func (cg *CodeGenerator) Generate(program *ast.Program) {
 	cg.varContext = VarCtx{}
 	cg.heapAllocs = []value.Value{}
 
+	cg.funcInfos = FuncInfos{}
+	cg.enclosingFunctions = map[*ir.Func]*ir.Func{}
+
 	cg.mainFunction = cg.Module.NewFunc("main", types.I32)
 	cg.mainBlock = cg.mainFunction.NewBlock(cg.uniqueNames.get("entry"))
 
+	cg.declareClasses(program)
+
 	cg.currentFunction = cg.mainFunction
 	cg.currentBlock = cg.mainBlock
 
 	for _, definition := range program.Definitions {
-		if _, ok := definition.(*ast.FuncDef); ok {
+		switch definition.(type) {
+		case *ast.FuncDef, *ast.ClassDef:
 			definition.Visit(cg)
 			cg.currentFunction = cg.mainFunction
 			cg.currentBlock = cg.mainBlock
-		} else {
+		default:
 			definition.Visit(cg)
 		}
 	}
@@ -134,13 +185,18 @@ func (cg *CodeGenerator) getVar(name string) (VarInfo, error) {
 
 	default:
 		// If we are not in the main function but rather inside
-		// of a local scope, we want to first check whether the variable is defined locally
+		// of a local scope, we want to first check whether the variable is defined locally,
+		// then whether it is defined by one of the enclosing functions,
 		// and only if it isn't we want to check the global context for the variable.
 		localVars := cg.varCtx(false)
 		if _, ok := localVars[name]; ok {
 			return localVars[name], nil
 		}
 
+		if enclosingVar, ok := cg.getEnclosingVar(name); ok {
+			return enclosingVar, nil
+		}
+
 		globalVars := cg.varCtx(true)
 		if _, ok := globalVars[name]; ok {
 			return globalVars[name], nil
@@ -156,6 +212,7 @@ func (cg *CodeGenerator) setVar(varInfo VarInfo) {
 	} else {
 		localVars := cg.varCtx(false)
 		localVars[varInfo.name] = varInfo
+		cg.storeInFrame(varInfo)
 	}
 }
 
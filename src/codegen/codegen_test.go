@@ -0,0 +1,52 @@
+package codegen_test
+
+import (
+	"chogopy/src/compiler"
+	"strings"
+	"testing"
+)
+
+// generate checks the given program and returns the LLVM IR that is generated for it.
+// Rendering the module fails if any of its basic blocks is left without a terminator,
+// which Compile reports as an internal compiler error.
+func generate(t *testing.T, stream string) string {
+	result, diags := compiler.Compile(stream, compiler.Options{StopAfter: compiler.StageCodegen})
+	if len(diags) != 0 {
+		t.Fatalf("Expected valid IR to be generated but found %v.", diags)
+	}
+	return result.IR
+}
+
+func TestMemberAccessInsideBlocks(t *testing.T) {
+	definitions := `class A(object):
+    x:int = 1
+    def f(self:"A") -> int:
+        return self.x
+a:A = None
+i:int = 0
+`
+	statements := []string{
+		"print(a.x)",
+		"a.x = 2",
+		"a.f()",
+	}
+	blocks := []string{
+		"if True:\n    %s\n",
+		"if False:\n    pass\nelse:\n    %s\n",
+		"while i < 1:\n    %s\n    i = i + 1\n",
+		"for i in [1, 2]:\n    %s\n",
+		"def g() -> int:\n    if True:\n        %s\n    return 0\n",
+	}
+
+	for _, block := range blocks {
+		for _, statement := range statements {
+			stream := definitions + strings.ReplaceAll(block, "%s", statement)
+
+			ir := generate(t, stream)
+
+			if !strings.Contains(ir, "call void @checkobject(") {
+				t.Fatalf("Expected the object to be checked for None in:\n%s", ir)
+			}
+		}
+	}
+}
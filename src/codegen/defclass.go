@@ -0,0 +1,202 @@
+package codegen
+
+import (
+	"chogopy/src/ast"
+	"slices"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// registerClasses creates the object layout and dispatch table type of every class in the program.
+// The named struct types of all classes are created up front so that attributes and methods
+// are able to refer to classes that are defined further down in the program.
+func (tb *TypeEnvBuilder) registerClasses(program *ast.Program) {
+	classDefs := []*ast.ClassDef{}
+	for _, definition := range program.Definitions {
+		if classDef, ok := definition.(*ast.ClassDef); ok {
+			classDefs = append(classDefs, classDef)
+		}
+	}
+
+	for _, classDef := range classDefs {
+		tb.classes[classDef.ClassName] = &ClassInfo{
+			name:       classDef.ClassName,
+			superClass: tb.classes[classDef.SuperClass],
+			structType: tb.Module.NewTypeDef("class."+classDef.ClassName, &types.StructType{}).(*types.StructType),
+			vtableType: tb.Module.NewTypeDef("vtable."+classDef.ClassName, &types.StructType{}).(*types.StructType),
+		}
+	}
+
+	// The slots of the dispatch table start out as the ones of the superclass.
+	// Methods that override an inherited method reuse its slot and new methods are appended.
+	// Every class inherits the __init__ method of the object class in its first slot.
+	objectInitType := types.NewFunc(types.NewPointer(tb.types["none"]), types.NewPointer(tb.types["object"]))
+
+	for _, classDef := range classDefs {
+		classInfo := tb.classes[classDef.ClassName]
+
+		if classInfo.superClass != nil {
+			classInfo.attributes = slices.Clone(classInfo.superClass.attributes)
+			classInfo.methodNames = slices.Clone(classInfo.superClass.methodNames)
+			classInfo.methodDefs = slices.Clone(classInfo.superClass.methodDefs)
+			classInfo.slotTypes = slices.Clone(classInfo.superClass.slotTypes)
+		} else {
+			classInfo.attributes = []*ast.VarDef{}
+			classInfo.methodNames = []string{"__init__"}
+			classInfo.methodDefs = []*ast.FuncDef{nil}
+			classInfo.slotTypes = []*types.FuncType{objectInitType}
+		}
+
+		for _, classBodyNode := range classDef.ClassBody {
+			switch classBodyNode := classBodyNode.(type) {
+			case *ast.VarDef:
+				classInfo.attributes = append(classInfo.attributes, classBodyNode)
+
+			case *ast.FuncDef:
+				if slot := slices.Index(classInfo.methodNames, classBodyNode.FuncName); slot >= 0 {
+					classInfo.methodDefs[slot] = classBodyNode
+					continue
+				}
+				classInfo.methodNames = append(classInfo.methodNames, classBodyNode.FuncName)
+				classInfo.methodDefs = append(classInfo.methodDefs, classBodyNode)
+				classInfo.slotTypes = append(classInfo.slotTypes, tb.methodType(classBodyNode))
+			}
+		}
+
+		classInfo.structType.Fields = []types.Type{types.NewPointer(classInfo.vtableType)}
+		for _, attribute := range classInfo.attributes {
+			attrType := tb.astTypeToType(attribute.TypedVar.(*ast.TypedVar).VarType)
+			classInfo.structType.Fields = append(classInfo.structType.Fields, attrType)
+		}
+
+		classInfo.vtableType.Fields = []types.Type{}
+		for _, slotType := range classInfo.slotTypes {
+			classInfo.vtableType.Fields = append(classInfo.vtableType.Fields, types.NewPointer(slotType))
+		}
+	}
+}
+
+func (tb *TypeEnvBuilder) methodType(funcDef *ast.FuncDef) *types.FuncType {
+	paramTypes := []types.Type{}
+	for _, paramNode := range funcDef.Parameters {
+		paramTypes = append(paramTypes, tb.astTypeToType(paramNode.(*ast.TypedVar).VarType))
+	}
+	return types.NewFunc(tb.astTypeToType(funcDef.ReturnType), paramTypes...)
+}
+
+// declareClasses declares the methods, the dispatch table, and the constructor of every class in the program.
+// This has to happen before any code is generated since objects may be constructed before their class definition is visited.
+func (cg *CodeGenerator) declareClasses(program *ast.Program) {
+	objectInit := cg.Module.NewFunc(
+		"object.__init__",
+		types.NewPointer(cg.types["none"]),
+		ir.NewParam("self", types.NewPointer(cg.types["object"])),
+	)
+	objectInit.NewBlock(cg.uniqueNames.get("entry")).NewRet(constant.NewNull(types.NewPointer(cg.types["none"])))
+
+	for _, definition := range program.Definitions {
+		classDef, ok := definition.(*ast.ClassDef)
+		if !ok {
+			continue
+		}
+		classInfo := cg.classes[classDef.ClassName]
+
+		if classInfo.superClass != nil {
+			classInfo.methods = slices.Clone(classInfo.superClass.methods)
+		} else {
+			classInfo.methods = []*ir.Func{objectInit}
+		}
+
+		for _, classBodyNode := range classDef.ClassBody {
+			if methodDef, ok := classBodyNode.(*ast.FuncDef); ok {
+				methodName := classDef.ClassName + "." + methodDef.FuncName
+				method := cg.declareFunc(methodName, methodDef, nil)
+				cg.functions[methodName] = method
+
+				slot := slices.Index(classInfo.methodNames, methodDef.FuncName)
+				if slot < len(classInfo.methods) {
+					classInfo.methods[slot] = method
+				} else {
+					classInfo.methods = append(classInfo.methods, method)
+				}
+			}
+		}
+
+		// Methods that override an inherited method take a pointer to their own class as the self parameter
+		// and therefore need to be cast into the type of the slot they are stored in.
+		slots := []constant.Constant{}
+		for slot, method := range classInfo.methods {
+			slotPtrType := types.NewPointer(classInfo.slotTypes[slot])
+			if method.Type().Equal(slotPtrType) {
+				slots = append(slots, method)
+			} else {
+				slots = append(slots, constant.NewBitCast(method, slotPtrType))
+			}
+		}
+		classInfo.vtable = cg.Module.NewGlobalDef("vtable."+classDef.ClassName, constant.NewStruct(classInfo.vtableType, slots...))
+
+		classInfo.constructor = cg.defineConstructor(classInfo)
+		cg.functions[classDef.ClassName] = classInfo.constructor
+	}
+}
+
+// defineConstructor creates the function that is called whenever a class is called in order to construct a new object.
+// It allocates the object, sets its dispatch table, initializes its attributes, and finally calls its __init__ method.
+func (cg *CodeGenerator) defineConstructor(classInfo *ClassInfo) *ir.Func {
+	constructor := cg.Module.NewFunc("new."+classInfo.name, types.NewPointer(classInfo.structType))
+	cg.currentFunction = constructor
+	cg.currentBlock = constructor.NewBlock(cg.uniqueNames.get("entry"))
+
+	object := cg.NewMalloc(classInfo.structType, constant.NewInt(types.I32, 1))
+
+	vtablePtr := cg.fieldPtr(object, classInfo, 0)
+	cg.currentBlock.NewStore(classInfo.vtable, vtablePtr)
+
+	for attrIdx, attribute := range classInfo.attributes {
+		attrPtr := cg.fieldPtr(object, classInfo, attrIdx+1)
+		cg.NewStore(cg.getLiteralConst(attribute), attrPtr)
+	}
+
+	initMethod := cg.methodPtr(object, classInfo, 0)
+	initCall := cg.currentBlock.NewCall(initMethod, cg.castPtr(object, classInfo.slotTypes[0].Params[0]))
+	initCall.LocalName = cg.uniqueNames.get("init_res")
+
+	cg.currentBlock.NewRet(object)
+	return constructor
+}
+
+func (cg *CodeGenerator) VisitClassDef(classDef *ast.ClassDef) {
+	for _, classBodyNode := range classDef.ClassBody {
+		if methodDef, ok := classBodyNode.(*ast.FuncDef); ok {
+			method := cg.functions[classDef.ClassName+"."+methodDef.FuncName]
+			cg.defineFunc(method, methodDef)
+		}
+	}
+}
+
+// fieldPtr returns a pointer to the field of an object at the given index.
+// The first field of every object holds its dispatch table, which is followed by its attributes.
+func (cg *CodeGenerator) fieldPtr(object value.Value, classInfo *ClassInfo, fieldIdx int) value.Value {
+	zero := constant.NewInt(types.I32, 0)
+	fieldPtr := cg.currentBlock.NewGetElementPtr(classInfo.structType, object, zero, constant.NewInt(types.I32, int64(fieldIdx)))
+	fieldPtr.LocalName = cg.uniqueNames.get("field_ptr")
+	return fieldPtr
+}
+
+// methodPtr looks up the method in the given slot of the dispatch table of an object.
+func (cg *CodeGenerator) methodPtr(object value.Value, classInfo *ClassInfo, slot int) value.Value {
+	vtablePtr := cg.fieldPtr(object, classInfo, 0)
+	vtable := cg.currentBlock.NewLoad(types.NewPointer(classInfo.vtableType), vtablePtr)
+	vtable.LocalName = cg.uniqueNames.get("vtable")
+
+	zero := constant.NewInt(types.I32, 0)
+	slotPtr := cg.currentBlock.NewGetElementPtr(classInfo.vtableType, vtable, zero, constant.NewInt(types.I32, int64(slot)))
+	slotPtr.LocalName = cg.uniqueNames.get("slot_ptr")
+
+	method := cg.currentBlock.NewLoad(types.NewPointer(classInfo.slotTypes[slot]), slotPtr)
+	method.LocalName = cg.uniqueNames.get("method")
+	return method
+}
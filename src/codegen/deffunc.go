@@ -8,7 +8,35 @@ import (
 )
 
 func (cg *CodeGenerator) VisitFuncDef(funcDef *ast.FuncDef) {
+	// Nested functions have already been declared by their enclosing function
+	// and only their body remains to be generated.
+	if enclosingInfo, ok := cg.funcInfos[cg.currentFunction]; ok {
+		enclosingFunction := cg.currentFunction
+		enclosingBlock := cg.currentBlock
+
+		cg.defineFunc(enclosingInfo.nested[funcDef.FuncName], funcDef)
+
+		cg.currentFunction = enclosingFunction
+		cg.currentBlock = enclosingBlock
+		return
+	}
+
+	newFunction := cg.declareFunc(funcDef.FuncName, funcDef, nil)
+	cg.functions[funcDef.FuncName] = newFunction
+	cg.defineFunc(newFunction, funcDef)
+}
+
+// declareFunc adds a function with the signature of the given function definition to the module.
+// The body of the function can be generated afterwards via defineFunc().
+//
+// Nested functions receive a pointer to the frame of their enclosing function as their first parameter.
+// This static link is what allows them to access the variables of their enclosing functions.
+func (cg *CodeGenerator) declareFunc(funcName string, funcDef *ast.FuncDef, enclosingFrame types.Type) *ir.Func {
 	params := []*ir.Param{}
+	if enclosingFrame != nil {
+		params = append(params, ir.NewParam(staticLinkName, types.NewPointer(enclosingFrame)))
+	}
+
 	for _, paramNode := range funcDef.Parameters {
 		paramName := paramNode.(*ast.TypedVar).VarName
 		paramType := cg.astTypeToType(paramNode.(*ast.TypedVar).VarType)
@@ -18,18 +46,47 @@ func (cg *CodeGenerator) VisitFuncDef(funcDef *ast.FuncDef) {
 
 	returnType := cg.astTypeToType(funcDef.ReturnType)
 
-	newFunction := cg.Module.NewFunc(funcDef.FuncName, returnType, params...)
-	newBlock := newFunction.NewBlock(cg.uniqueNames.get("entry"))
+	return cg.Module.NewFunc(funcName, returnType, params...)
+}
+
+func (cg *CodeGenerator) defineFunc(function *ir.Func, funcDef *ast.FuncDef) {
+	newBlock := function.NewBlock(cg.uniqueNames.get("entry"))
 
-	cg.functions[funcDef.FuncName] = newFunction
-	cg.currentFunction = newFunction
+	cg.currentFunction = function
 	cg.currentBlock = newBlock
 
+	funcInfo := cg.newFuncInfo(function, funcDef)
+
+	// Parameters are moved onto the stack so that they can be treated just like local variables.
+	// This way, an identifier always refers to the address of a variable no matter whether it is a parameter or not.
+	for _, param := range function.Params {
+		if param.LocalName == staticLinkName {
+			continue
+		}
+
+		paramPtr := cg.currentBlock.NewAlloca(param.Type())
+		paramPtr.LocalName = cg.uniqueNames.get("param_ptr")
+		cg.currentBlock.NewStore(param, paramPtr)
+
+		cg.setVar(VarInfo{name: param.LocalName, elemType: param.Type(), value: paramPtr})
+	}
+
+	// All nested functions are declared up front so that they can call each other
+	// regardless of the order in which they have been defined.
+	for _, bodyNode := range funcDef.FuncBody {
+		if nestedFuncDef, ok := bodyNode.(*ast.FuncDef); ok {
+			nestedName := function.Name() + "." + nestedFuncDef.FuncName
+			nestedFunction := cg.declareFunc(nestedName, nestedFuncDef, funcInfo.frameType)
+			funcInfo.nested[nestedFuncDef.FuncName] = nestedFunction
+			cg.enclosingFunctions[nestedFunction] = function
+		}
+	}
+
 	for _, bodyNode := range funcDef.FuncBody {
 		bodyNode.Visit(cg)
 	}
 
-	if returnType.Equal(types.NewPointer(cg.types["none"])) {
+	if function.Sig.RetType.Equal(types.NewPointer(cg.types["none"])) {
 		cg.currentBlock.NewRet(cg.NewLiteral(nil))
 	}
 }
@@ -10,10 +10,6 @@ func (cg *CodeGenerator) VisitTypedVar(typedVar *ast.TypedVar) {
 	/* no op */
 }
 
-func (cg *CodeGenerator) VisitGlobalDecl(globalDecl *ast.GlobalDecl) {
-	/* no op */
-}
-
 func (cg *CodeGenerator) VisitNonLocalDecl(nonLocalDecl *ast.NonLocalDecl) {
 	/* no op */
 }
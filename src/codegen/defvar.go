@@ -2,7 +2,6 @@ package codegen
 
 import (
 	"chogopy/src/ast"
-	"strings"
 
 	"github.com/llir/llvm/ir/constant"
 	"github.com/llir/llvm/ir/types"
@@ -46,6 +45,15 @@ func (cg *CodeGenerator) VisitVarDef(varDef *ast.VarDef) {
 	}
 }
 
+// VisitGlobalDecl makes the global variable visible in the local context of the current function.
+// This ensures that the global variable takes precedence over variables of enclosing functions with the same name.
+func (cg *CodeGenerator) VisitGlobalDecl(globalDecl *ast.GlobalDecl) {
+	if globalVar, ok := cg.varCtx(true)[globalDecl.DeclName]; ok {
+		localVars := cg.varCtx(false)
+		localVars[globalDecl.DeclName] = globalVar
+	}
+}
+
 func (cg *CodeGenerator) getLiteralConst(varDef *ast.VarDef) constant.Constant {
 	varType := cg.astTypeToType(varDef.TypedVar.(*ast.TypedVar).VarType)
 	literalVal := varDef.Literal.(*ast.LiteralExpr).Value
@@ -70,7 +78,7 @@ func (cg *CodeGenerator) getLiteralConst(varDef *ast.VarDef) constant.Constant {
 		switch varType := varType.(type) {
 		case *types.PointerType:
 			/* None init for list* */
-			if strings.Contains(varType.String(), "list") {
+			if isListType(varType.ElemType) {
 				listContentType := varType.ElemType.(*types.StructType).Fields[0]
 				listContentNone := constant.NewNull(listContentType.(*types.PointerType))
 				listLenNone := constant.NewInt(types.I32, 0)
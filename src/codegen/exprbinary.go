@@ -8,10 +8,12 @@ import (
 	"github.com/llir/llvm/ir/value"
 )
 
+// VisitBinaryExpr generates the instructions of a binary expression.
+// The integer operations are emitted without the nsw flag, since integer overflow wraps around.
 func (cg *CodeGenerator) VisitBinaryExpr(binaryExpr *ast.BinaryExpr) {
 	binaryExpr.Lhs.Visit(cg)
 	lhsValue := cg.lastGenerated
-	if isIdentOrIndex(binaryExpr.Lhs) {
+	if isAddress(binaryExpr.Lhs) {
 		lhsValue = cg.LoadVal(lhsValue)
 	}
 
@@ -21,7 +23,7 @@ func (cg *CodeGenerator) VisitBinaryExpr(binaryExpr *ast.BinaryExpr) {
 
 	binaryExpr.Rhs.Visit(cg)
 	rhsValue := cg.lastGenerated
-	if isIdentOrIndex(binaryExpr.Rhs) {
+	if isAddress(binaryExpr.Rhs) {
 		rhsValue = cg.LoadVal(rhsValue)
 	}
 
@@ -33,7 +35,7 @@ func (cg *CodeGenerator) VisitBinaryExpr(binaryExpr *ast.BinaryExpr) {
 	case "or":
 		resVal = cg.currentBlock.NewOr(lhsValue, rhsValue)
 	case "%":
-		resVal = cg.floorRem(lhsValue, rhsValue)
+		resVal = cg.currentBlock.NewCall(cg.functions["floormod"], lhsValue, rhsValue)
 	case "*":
 		resVal = cg.currentBlock.NewMul(lhsValue, rhsValue)
 	case "//":
@@ -79,16 +81,6 @@ func (cg *CodeGenerator) VisitBinaryExpr(binaryExpr *ast.BinaryExpr) {
 	cg.lastGenerated = resVal
 }
 
-func (cg *CodeGenerator) floorRem(lhs value.Value, rhs value.Value) value.Value {
-	/* rem = lhs - rhs * floorDiv(lhs, rhs) */
-
-	floorDiv := cg.currentBlock.NewCall(cg.functions["floordiv"], lhs, rhs)
-	rhsMult := cg.currentBlock.NewMul(rhs, floorDiv)
-	floorRem := cg.currentBlock.NewSub(lhs, rhsMult)
-
-	return floorRem
-}
-
 func (cg *CodeGenerator) shortCircuit(binaryExpr *ast.BinaryExpr) bool {
 	if _, ok := binaryExpr.Lhs.(*ast.LiteralExpr); ok {
 		literalVal := binaryExpr.Lhs.(*ast.LiteralExpr).Value
@@ -124,7 +116,7 @@ func (cg *CodeGenerator) concat(binaryExpr *ast.BinaryExpr, lhs value.Value, rhs
 }
 
 // func (cg CodeGenerator) getStrLiteral(node ast.Node) string {
-// 	if isIdentOrIndex(node) {
+// 	if isAddress(node) {
 // 		varInfo, _ := cg.getVar(node.(*ast.IdentExpr).Identifier)
 // 		initConst := varInfo.init.(*constant.ExprGetElementPtr)
 // 		charArr := initConst.Src.(*ir.Global).Init.(*constant.CharArray).X
@@ -2,7 +2,6 @@ package codegen
 
 import (
 	"chogopy/src/ast"
-	"log"
 
 	"github.com/llir/llvm/ir"
 	"github.com/llir/llvm/ir/types"
@@ -14,7 +13,7 @@ func (cg *CodeGenerator) VisitCallExpr(callExpr *ast.CallExpr) {
 	for _, arg := range callExpr.Arguments {
 		arg.Visit(cg)
 		argVal := cg.lastGenerated
-		if isIdentOrIndex(arg) {
+		if isAddress(arg) {
 			argVal = cg.LoadVal(cg.lastGenerated)
 		}
 		args = append(args, argVal)
@@ -33,7 +32,14 @@ func (cg *CodeGenerator) VisitCallExpr(callExpr *ast.CallExpr) {
 		return
 	}
 
-	callee := cg.functions[callExpr.FuncName]
+	callee, staticLink := cg.getFunc(callExpr.FuncName)
+	if staticLink != nil {
+		args = append([]value.Value{staticLink}, args...)
+	}
+	for argIdx, param := range callee.Params {
+		args[argIdx] = cg.castPtr(args[argIdx], param.Type())
+	}
+
 	callRes := cg.currentBlock.NewCall(callee, args...)
 	callRes.LocalName = cg.uniqueNames.get("call_res")
 
@@ -44,17 +50,21 @@ func (cg *CodeGenerator) VisitCallExpr(callExpr *ast.CallExpr) {
 	cg.lastGenerated = callRes
 }
 
+// getLen generates a call to len(). Since len() accepts any object, the arguments
+// that are neither strings nor lists raise an invalid argument error at runtime.
 func (cg *CodeGenerator) getLen(arg value.Value) value.Value {
 	if isString(arg) {
 		return cg.getStringLen(arg)
 	} else if isList(arg) {
 		return cg.getListLen(arg)
+	} else if isPtrTo(arg, cg.types["none"]) {
+		return cg.currentBlock.NewCall(cg.functions["raiselennone"])
 	}
-
-	log.Fatalln("Code Generation: len() expected an argument of type str or list")
-	return nil
+	return cg.currentBlock.NewCall(cg.functions["raiseinvalidarg"])
 }
 
+// printGeneric generates a call to print(). Since print() accepts any object, but only
+// integers, booleans and strings can be printed, any other argument raises an invalid argument error at runtime.
 func (cg *CodeGenerator) printGeneric(arg value.Value) value.Value {
 	if hasType(arg, types.I32) {
 		return cg.currentBlock.NewCall(cg.functions["printint"], arg)
@@ -63,6 +73,5 @@ func (cg *CodeGenerator) printGeneric(arg value.Value) value.Value {
 	} else if isString(arg) {
 		return cg.currentBlock.NewCall(cg.functions["printstr"], arg)
 	}
-	log.Fatalln("Code Generation: print() expected an argument of type int, bool, or str but got:", arg.Type().String())
-	return nil
+	return cg.currentBlock.NewCall(cg.functions["raiseinvalidarg"])
 }
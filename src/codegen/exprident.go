@@ -7,17 +7,9 @@ import (
 func (cg *CodeGenerator) VisitIdentExpr(identExpr *ast.IdentExpr) {
 	identName := identExpr.Identifier
 
-	// Case 1: The identifier refers to a variable definition.
-
+	// The identifier refers to a variable definition or a parameter of the current function.
+	// Parameters are stored as local variables of the function and therefore take precedence over global definitions.
 	if identVarInfo, err := cg.getVar(identName); err == nil {
 		cg.lastGenerated = identVarInfo.value
 	}
-
-	// Case 2: The identifier refers to the name of a parameter of the current function. (overwrites definitions)
-
-	for _, param := range cg.currentFunction.Params {
-		if identName == param.LocalName {
-			cg.lastGenerated = param
-		}
-	}
 }
@@ -22,7 +22,7 @@ func (cg *CodeGenerator) VisitIfExpr(ifExpr *ast.IfExpr) {
 	ifExpr.IfNode.Visit(cg)
 
 	ifBlockRes := cg.lastGenerated
-	if isIdentOrIndex(ifExpr.IfNode) {
+	if isAddress(ifExpr.IfNode) {
 		ifBlockRes = cg.LoadVal(ifBlockRes)
 	}
 
@@ -34,7 +34,7 @@ func (cg *CodeGenerator) VisitIfExpr(ifExpr *ast.IfExpr) {
 	ifExpr.ElseNode.Visit(cg)
 
 	elseBlockRes := cg.lastGenerated
-	if isIdentOrIndex(ifExpr.ElseNode) {
+	if isAddress(ifExpr.ElseNode) {
 		elseBlockRes = cg.LoadVal(elseBlockRes)
 	}
 
@@ -12,14 +12,14 @@ func (cg *CodeGenerator) VisitIndexExpr(indexExpr *ast.IndexExpr) {
 	indexExpr.Value.Visit(cg)
 	val := cg.lastGenerated
 
-	if isIdentOrIndex(indexExpr.Value) {
+	if isAddress(indexExpr.Value) {
 		val = cg.LoadVal(cg.lastGenerated)
 	}
 
 	indexExpr.Index.Visit(cg)
 	index := cg.lastGenerated
 
-	if isIdentOrIndex(indexExpr.Index) {
+	if isAddress(indexExpr.Index) {
 		index = cg.LoadVal(index)
 	}
 
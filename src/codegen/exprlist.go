@@ -112,7 +112,7 @@ func (cg *CodeGenerator) newDynamicList(listExpr *ast.ListExpr) value.Value {
 		elem.Visit(cg)
 		elemVal := cg.lastGenerated
 
-		if isIdentOrIndex(elem) {
+		if isAddress(elem) {
 			elemVal = cg.LoadVal(elemVal)
 		}
 
@@ -0,0 +1,68 @@
+package codegen
+
+import (
+	"chogopy/src/ast"
+	"slices"
+	"strings"
+
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+func (cg *CodeGenerator) VisitMemberExpr(memberExpr *ast.MemberExpr) {
+	object := cg.genObject(memberExpr.Object)
+	classInfo := cg.classOf(object)
+
+	attrIdx := slices.IndexFunc(classInfo.attributes, func(attribute *ast.VarDef) bool {
+		return attribute.TypedVar.(*ast.TypedVar).VarName == memberExpr.MemberName
+	})
+
+	// The first field of an object holds its dispatch table which is why attributes start at index 1
+	cg.lastGenerated = cg.fieldPtr(object, classInfo, attrIdx+1)
+}
+
+func (cg *CodeGenerator) VisitMethodCallExpr(methodCallExpr *ast.MethodCallExpr) {
+	object := cg.genObject(methodCallExpr.Receiver)
+	classInfo := cg.classOf(object)
+
+	slot := slices.Index(classInfo.methodNames, methodCallExpr.MethodName)
+	method := cg.methodPtr(object, classInfo, slot)
+	methodType := classInfo.slotTypes[slot]
+
+	args := []value.Value{cg.castPtr(object, methodType.Params[0])}
+	for argIdx, arg := range methodCallExpr.Arguments {
+		arg.Visit(cg)
+		argVal := cg.lastGenerated
+		if isAddress(arg) {
+			argVal = cg.LoadVal(cg.lastGenerated)
+		}
+		args = append(args, cg.castPtr(argVal, methodType.Params[argIdx+1]))
+	}
+
+	callRes := cg.currentBlock.NewCall(method, args...)
+	callRes.LocalName = cg.uniqueNames.get("method_res")
+
+	cg.lastGenerated = callRes
+}
+
+// genObject generates the object whose attribute is accessed or whose method is called
+// and raises a runtime exception if that object turns out to be None.
+func (cg *CodeGenerator) genObject(objectNode ast.Node) value.Value {
+	objectNode.Visit(cg)
+	object := cg.lastGenerated
+	if isAddress(objectNode) {
+		object = cg.LoadVal(object)
+	}
+
+	// The check is done by a separate function so that the current block is not split up,
+	// since statements such as if and while have already terminated the block of their body
+	cg.currentBlock.NewCall(cg.functions["checkobject"], cg.castPtr(object, types.I8Ptr))
+	return object
+}
+
+// classOf returns the class of an object based on its type.
+// Objects are always pointers to the struct type of their class.
+func (cg *CodeGenerator) classOf(object value.Value) *ClassInfo {
+	structType := object.Type().(*types.PointerType).ElemType
+	return cg.classes[strings.TrimPrefix(structType.Name(), "class.")]
+}
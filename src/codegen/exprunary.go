@@ -10,7 +10,7 @@ func (cg *CodeGenerator) VisitUnaryExpr(unaryExpr *ast.UnaryExpr) {
 	unaryExpr.Value.Visit(cg)
 	unaryVal := cg.lastGenerated
 
-	if isIdentOrIndex(unaryExpr.Value) {
+	if isAddress(unaryExpr.Value) {
 		unaryVal = cg.LoadVal(unaryVal)
 	}
 
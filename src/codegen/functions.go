@@ -1,14 +1,49 @@
 package codegen
 
 import (
+	"maps"
+	"slices"
+
 	"github.com/llir/llvm/ir"
 	"github.com/llir/llvm/ir/constant"
 	"github.com/llir/llvm/ir/enum"
 	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
 )
 
 var MaxBufferSize = int64(1000)
 
+// RuntimeErrorKind is the category of an error that occurs while a compiled program is running.
+// The value of each category is the exit code that a program terminates with once it encounters such an error.
+type RuntimeErrorKind int
+
+const (
+	InvalidArgument RuntimeErrorKind = iota + 1
+	DivisionByZero
+	IndexOutOfBounds
+	OperationOnNone
+	OutOfMemory
+)
+
+type RuntimeError struct {
+	Kind    RuntimeErrorKind
+	Message string
+}
+
+// RuntimeErrors contains every error that a compiled program may raise at runtime.
+// The message of an error is written to stderr before the program exits with the exit code of its category.
+// New runtime checks should add their error here and raise it via exceptionHelper().
+var RuntimeErrors = map[string]RuntimeError{
+	"error_len_none":         {InvalidArgument, "TypeError: object of type 'NoneType' has no len()"},
+	"error_invalid_argument": {InvalidArgument, "TypeError: invalid argument"},
+	"error_index_none":       {OperationOnNone, "TypeError: 'NoneType' object is not subscriptable"},
+	"error_index_neg":        {IndexOutOfBounds, "IndexError: list index out of range"},
+	"error_index_oob":        {IndexOutOfBounds, "IndexError: list index out of range"},
+	"error_member_none":      {OperationOnNone, "AttributeError: 'NoneType' object has no attribute"},
+	"error_div_zero":         {DivisionByZero, "ZeroDivisionError: integer division or modulo by zero"},
+	"error_out_of_memory":    {OutOfMemory, "MemoryError: out of memory"},
+}
+
 func (cg *CodeGenerator) registerFuncs() {
 	cg.addStringConstants()
 	cg.registerExternal()
@@ -25,10 +60,9 @@ func (cg *CodeGenerator) addStringConstants() {
 	cg.strings["true_newline"] = cg.globalStringDef("true_newline", "True\n\x00")
 	cg.strings["false_newline"] = cg.globalStringDef("false_newline", "False\n\x00")
 
-	cg.strings["error_len_none"] = cg.globalStringDef("error_len_none", "TypeError: object of type 'NoneType' has no len()\n\x00")
-	cg.strings["error_index_none"] = cg.globalStringDef("error_index_none", "TypeError: 'NoneType' object is not subscriptable\n\x00")
-	cg.strings["error_index_neg"] = cg.globalStringDef("error_index_neg", "IndexError: list index out of range\n\x00")
-	cg.strings["error_index_oob"] = cg.globalStringDef("error_index_oob", "IndexError: list index out of range\n\x00")
+	for _, errorName := range slices.Sorted(maps.Keys(RuntimeErrors)) {
+		cg.strings[errorName] = cg.globalStringDef(errorName, RuntimeErrors[errorName].Message+"\n\x00")
+	}
 }
 
 func (cg *CodeGenerator) globalStringDef(defName string, strLiteral string) *ir.Global {
@@ -58,12 +92,10 @@ func (cg *CodeGenerator) registerExternal() {
 		ir.NewParam("", types.I8Ptr),
 	)
 
-	scanf := cg.Module.NewFunc(
-		"scanf",
+	getchar := cg.Module.NewFunc(
+		"getchar",
 		types.I32,
-		ir.NewParam("", types.I8Ptr),
 	)
-	scanf.Sig.Variadic = true
 
 	strcpy := cg.Module.NewFunc(
 		"strcpy",
@@ -114,6 +146,20 @@ func (cg *CodeGenerator) registerExternal() {
 	)
 	printf.Sig.Variadic = true
 
+	dprintf := cg.Module.NewFunc(
+		"dprintf",
+		types.I32,
+		ir.NewParam("", types.I32),
+		ir.NewParam("", types.I8Ptr),
+	)
+	dprintf.Sig.Variadic = true
+
+	fflush := cg.Module.NewFunc(
+		"fflush",
+		types.I32,
+		ir.NewParam("", types.I8Ptr),
+	)
+
 	malloc := cg.Module.NewFunc(
 		"malloc",
 		types.I8Ptr,
@@ -126,23 +172,8 @@ func (cg *CodeGenerator) registerExternal() {
 		ir.NewParam("", types.I8Ptr),
 	)
 
-	//fgets := cg.Module.NewFunc(
-	//	"fgets",
-	//	types.I8Ptr,
-	//	ir.NewParam("", types.I8Ptr),
-	//	ir.NewParam("", types.I32),
-	//	ir.NewParam("", types.I8Ptr),
-	//)
-
-	//fdopen := cg.Module.NewFunc(
-	//	"fdopen",
-	//	cg.types["FILE"],
-	//	ir.NewParam("", types.I32),
-	//	ir.NewParam("", types.I8Ptr),
-	//)
-
 	cg.functions["strcat"] = strcat
-	cg.functions["scanf"] = scanf
+	cg.functions["getchar"] = getchar
 	cg.functions["strcpy"] = strcpy
 	cg.functions["strcmp"] = strcmp
 	cg.functions["strlen"] = strlen
@@ -150,10 +181,10 @@ func (cg *CodeGenerator) registerExternal() {
 	cg.functions["memcpy"] = memcpy
 	cg.functions["sprintf"] = sprintf
 	cg.functions["printf"] = printf
+	cg.functions["dprintf"] = dprintf
+	cg.functions["fflush"] = fflush
 	cg.functions["malloc"] = malloc
 	cg.functions["free"] = free
-	// cg.functions["fgets"] = fgets
-	// cg.functions["fdopen"] = fdopen
 }
 
 func (cg *CodeGenerator) registerBuiltin() {
@@ -170,39 +201,92 @@ func (cg *CodeGenerator) registerBuiltin() {
 	)
 
 	cg.functions["print"] = print_
-	cg.functions["input"] = cg.defineInput()
 	cg.functions["len"] = len_
 }
 
-// TODO: The memory this function allocates for the input string is allocated on the functions'
-// local stack frame, which will be freed after the function returns.
-// Therefore, we are returning a pointer to unallocated memory.
-// We should instead create a global string constant or use malloc to allocate memory on the heap.
-// The same applies to any non-predefined functions defined by the user. If they somehow return a string,
-// the way we are currently generating the code, that string will be a pointer to
-// unallocated memory. (allocated on the functions' call frame)
+// defineInput defines input(), which reads a line from stdin without the trailing line break, just like Python does.
+// The line is read character by character into a buffer that doubles in size whenever it is full.
+// An empty string is returned once the end of the input has been reached.
 func (cg *CodeGenerator) defineInput() *ir.Func {
+	zero := constant.NewInt(types.I32, 0)
+	one := constant.NewInt(types.I32, 1)
+
 	input := cg.Module.NewFunc("input", types.I8Ptr)
 	funcBlock := input.NewBlock(cg.uniqueNames.get("entry"))
-
-	strFormatPtr := cg.useStringDef(funcBlock, "str_format")
-
-	inputStr := funcBlock.NewCall(cg.functions["malloc"], constant.NewInt(types.I32, MaxBufferSize))
-	inputStr.LocalName = cg.uniqueNames.get("input_ptr")
-
-	scanRes := funcBlock.NewCall(cg.functions["scanf"], strFormatPtr, inputStr)
-	scanRes.LocalName = cg.uniqueNames.get("scan_res")
-
-	funcBlock.NewRet(inputStr)
+	readBlock := input.NewBlock("input.read")
+	appendBlock := input.NewBlock("input.append")
+	growBlock := input.NewBlock("input.grow")
+	storeBlock := input.NewBlock("input.store")
+	endBlock := input.NewBlock("input.end")
+	carriageReturnBlock := input.NewBlock("input.cr")
+	terminateBlock := input.NewBlock("input.terminate")
+
+	bufferPtr := funcBlock.NewAlloca(types.I8Ptr)
+	capacityPtr := funcBlock.NewAlloca(types.I32)
+	lengthPtr := funcBlock.NewAlloca(types.I32)
+	initialCapacity := constant.NewInt(types.I32, MaxBufferSize)
+	funcBlock.NewStore(funcBlock.NewCall(cg.functions["alloc"], initialCapacity), bufferPtr)
+	funcBlock.NewStore(initialCapacity, capacityPtr)
+	funcBlock.NewStore(zero, lengthPtr)
+	funcBlock.NewBr(readBlock)
+
+	/* Read characters until the end of the line or the input */
+	char := readBlock.NewCall(cg.functions["getchar"])
+	char.LocalName = cg.uniqueNames.get("char")
+	isEOF := readBlock.NewICmp(enum.IPredEQ, char, constant.NewInt(types.I32, -1))
+	isNewline := readBlock.NewICmp(enum.IPredEQ, char, constant.NewInt(types.I32, '\n'))
+	readBlock.NewCondBr(readBlock.NewOr(isEOF, isNewline), endBlock, appendBlock)
+
+	/* Leave room for the null terminator when appending the character */
+	length := appendBlock.NewLoad(types.I32, lengthPtr)
+	capacity := appendBlock.NewLoad(types.I32, capacityPtr)
+	isFull := appendBlock.NewICmp(enum.IPredEQ, appendBlock.NewAdd(length, one), capacity)
+	appendBlock.NewCondBr(isFull, growBlock, storeBlock)
+
+	newCapacity := growBlock.NewMul(capacity, constant.NewInt(types.I32, 2))
+	newBuffer := growBlock.NewCall(cg.functions["alloc"], newCapacity)
+	oldBuffer := growBlock.NewLoad(types.I8Ptr, bufferPtr)
+	growBlock.NewCall(cg.functions["memcpy"],
+		growBlock.NewBitCast(newBuffer, types.I32Ptr), growBlock.NewBitCast(oldBuffer, types.I32Ptr), length)
+	growBlock.NewCall(cg.functions["free"], oldBuffer)
+	growBlock.NewStore(newBuffer, bufferPtr)
+	growBlock.NewStore(newCapacity, capacityPtr)
+	growBlock.NewBr(storeBlock)
+
+	buffer := storeBlock.NewLoad(types.I8Ptr, bufferPtr)
+	storeBlock.NewStore(storeBlock.NewTrunc(char, types.I8), storeBlock.NewGetElementPtr(types.I8, buffer, length))
+	storeBlock.NewStore(storeBlock.NewAdd(length, one), lengthPtr)
+	storeBlock.NewBr(readBlock)
+
+	/* Drop the carriage return of a Windows line break */
+	length = endBlock.NewLoad(types.I32, lengthPtr)
+	buffer = endBlock.NewLoad(types.I8Ptr, bufferPtr)
+	endBlock.NewCondBr(endBlock.NewICmp(enum.IPredSGT, length, zero), carriageReturnBlock, terminateBlock)
+
+	lastIdx := carriageReturnBlock.NewSub(length, one)
+	lastChar := carriageReturnBlock.NewLoad(types.I8, carriageReturnBlock.NewGetElementPtr(types.I8, buffer, lastIdx))
+	isCarriageReturn := carriageReturnBlock.NewICmp(enum.IPredEQ, lastChar, constant.NewInt(types.I8, '\r'))
+	carriageReturnBlock.NewStore(carriageReturnBlock.NewSelect(isCarriageReturn, lastIdx, length), lengthPtr)
+	carriageReturnBlock.NewBr(terminateBlock)
+
+	length = terminateBlock.NewLoad(types.I32, lengthPtr)
+	terminateBlock.NewStore(constant.NewInt(types.I8, 0), terminateBlock.NewGetElementPtr(types.I8, buffer, length))
+	terminateBlock.NewRet(buffer)
 
 	return input
 }
 
 func (cg *CodeGenerator) registerCustom() {
+	cg.functions["alloc"] = cg.defineAlloc()
+	cg.functions["checkobject"] = cg.defineCheckObject()
+	cg.functions["raiselennone"] = cg.defineRaise("raiselennone", "error_len_none")
+	cg.functions["raiseinvalidarg"] = cg.defineRaise("raiseinvalidarg", "error_invalid_argument")
+	cg.functions["input"] = cg.defineInput()
 	cg.functions["printstr"] = cg.definePrintString()
 	cg.functions["printint"] = cg.definePrintInt()
 	cg.functions["printbool"] = cg.definePrintBool()
 	cg.functions["floordiv"] = cg.defineFloorDiv()
+	cg.functions["floormod"] = cg.defineFloorMod()
 	cg.functions["newint"] = cg.defineNewInt()
 	cg.functions["newbool"] = cg.defineNewBool()
 
@@ -223,45 +307,179 @@ func (cg *CodeGenerator) registerCustom() {
 	}
 }
 
+// exceptionHelper raises the runtime error with the given name from RuntimeErrors at the end of the given block.
+// It writes the error message to stderr and terminates the program with the exit code of the error category.
+// Any buffered output of the program is flushed beforehand so that the error message appears after it.
 func (cg *CodeGenerator) exceptionHelper(block *ir.Block, exception string) {
-	returnCode := constant.NewInt(types.I32, 0)
+	stderr := constant.NewInt(types.I32, 2)
+	returnCode := constant.NewInt(types.I32, int64(RuntimeErrors[exception].Kind))
 	errorStr := cg.useStringDef(block, exception)
 
-	block.NewCall(cg.functions["printf"], errorStr)
+	block.NewCall(cg.functions["fflush"], constant.NewNull(types.I8Ptr))
+	block.NewCall(cg.functions["dprintf"], stderr, errorStr)
 	block.NewCall(cg.functions["exit"], returnCode)
 }
 
+// defineAlloc defines a wrapper around malloc() that raises a runtime error if the allocation fails.
+func (cg *CodeGenerator) defineAlloc() *ir.Func {
+	size := ir.NewParam("", types.I32)
+	alloc := cg.Module.NewFunc("alloc", types.I8Ptr, size)
+	funcBlock := alloc.NewBlock(cg.uniqueNames.get("entry"))
+	allocSuccessBlock := alloc.NewBlock("alloc.success")
+	allocFailBlock := alloc.NewBlock("alloc.fail")
+
+	heapPtr := funcBlock.NewCall(cg.functions["malloc"], size)
+	heapPtr.LocalName = cg.uniqueNames.get("heap_ptr")
+	allocFailed := funcBlock.NewICmp(enum.IPredEQ, heapPtr, constant.NewNull(types.I8Ptr))
+	allocFailed.LocalName = cg.uniqueNames.get("alloc_failed")
+	funcBlock.NewCondBr(allocFailed, allocFailBlock, allocSuccessBlock)
+
+	allocSuccessBlock.NewRet(heapPtr)
+
+	/* Raise runtime exception out of memory */
+	cg.exceptionHelper(allocFailBlock, "error_out_of_memory")
+	allocFailBlock.NewUnreachable()
+
+	return alloc
+}
+
+// defineRaise defines a function that raises the given runtime error unconditionally.
+// It returns an i32 so that calls to it can stand in for the result of a builtin function.
+func (cg *CodeGenerator) defineRaise(funcName string, exception string) *ir.Func {
+	raise := cg.Module.NewFunc(funcName, types.I32)
+	funcBlock := raise.NewBlock(cg.uniqueNames.get("entry"))
+
+	cg.exceptionHelper(funcBlock, exception)
+	funcBlock.NewUnreachable()
+
+	return raise
+}
+
+// defineCheckObject defines a function that raises a runtime error if the given object is None.
+// It is called before an attribute of an object is accessed or one of its methods is called.
+func (cg *CodeGenerator) defineCheckObject() *ir.Func {
+	object := ir.NewParam("", types.I8Ptr)
+	checkObject := cg.Module.NewFunc("checkobject", types.Void, object)
+	funcBlock := checkObject.NewBlock(cg.uniqueNames.get("entry"))
+	notNoneBlock := checkObject.NewBlock("object.notnone")
+	noneBlock := checkObject.NewBlock("object.none")
+
+	isNone := funcBlock.NewICmp(enum.IPredEQ, object, constant.NewNull(types.I8Ptr))
+	isNone.LocalName = cg.uniqueNames.get("object_is_none")
+	funcBlock.NewCondBr(isNone, noneBlock, notNoneBlock)
+
+	notNoneBlock.NewRet(nil)
+
+	/* Raise runtime exception member access on None */
+	cg.exceptionHelper(noneBlock, "error_member_none")
+	noneBlock.NewUnreachable()
+
+	return checkObject
+}
+
+// defineFloorDiv defines integer division that rounds towards negative infinity like Python's // operator.
+// LLVM's sdiv rounds towards zero, so the quotient is decremented whenever the division has a remainder
+// and the operands have different signs. A divisor of zero raises a ZeroDivisionError.
 func (cg *CodeGenerator) defineFloorDiv() *ir.Func {
 	lhs := ir.NewParam("", types.I32)
 	rhs := ir.NewParam("", types.I32)
 	floorDiv := cg.Module.NewFunc("floordiv", types.I32, lhs, rhs)
 	funcBlock := floorDiv.NewBlock(cg.uniqueNames.get("entry"))
 
-	lhsFloat := funcBlock.NewSIToFP(lhs, types.Float)
-	lhsFloat.LocalName = cg.uniqueNames.get("div_lhs_fp")
-	rhsFloat := funcBlock.NewSIToFP(rhs, types.Float)
-	rhsFloat.LocalName = cg.uniqueNames.get("div_rhs_fp")
-	floatDiv := funcBlock.NewFDiv(lhsFloat, rhsFloat)
-	floatDiv.LocalName = cg.uniqueNames.get("div_res_fp")
+	divBlock, negOneBlock := cg.checkDivisor(floorDiv, funcBlock, rhs)
+
+	// Dividing by -1 is handled separately because sdiv overflows for the smallest i32 value.
+	negLhs := negOneBlock.NewSub(constant.NewInt(types.I32, 0), lhs)
+	negLhs.LocalName = cg.uniqueNames.get("div_neg_lhs")
+	negOneBlock.NewRet(negLhs)
 
-	truncDiv := funcBlock.NewFPToSI(floatDiv, types.I32)
+	truncDiv := divBlock.NewSDiv(lhs, rhs)
 	truncDiv.LocalName = cg.uniqueNames.get("div_res_trunc")
-	truncDivFloat := funcBlock.NewSIToFP(truncDiv, types.Float)
-	truncDivFloat.LocalName = cg.uniqueNames.get("div_res_trunc_fp")
-
-	// floor(x) = trunc(x) - ((trunc(x) > x) as I32)
-	subtractOne := funcBlock.NewFCmp(enum.FPredOGT, truncDivFloat, floatDiv)
-	subtractOne.LocalName = cg.uniqueNames.get("trunc_gt_div_res")
-	subtractOneInt := funcBlock.NewZExt(subtractOne, types.I32)
-	subtractOneInt.LocalName = cg.uniqueNames.get("trunc_gt_div_res_int")
-	floorRes := funcBlock.NewSub(truncDiv, subtractOneInt)
+	truncRem := divBlock.NewSRem(lhs, rhs)
+	truncRem.LocalName = cg.uniqueNames.get("div_rem_trunc")
+
+	// floor(x / y) = trunc(x / y) - ((x % y != 0 and (x % y < 0) != (y < 0)) as I32)
+	subtractOne := cg.needsFloorAdjustment(divBlock, truncRem, rhs)
+	subtractOneInt := divBlock.NewZExt(subtractOne, types.I32)
+	subtractOneInt.LocalName = cg.uniqueNames.get("div_adjust_int")
+	floorRes := divBlock.NewSub(truncDiv, subtractOneInt)
 	floorRes.LocalName = cg.uniqueNames.get("floor_res")
 
-	funcBlock.NewRet(floorRes)
+	divBlock.NewRet(floorRes)
 
 	return floorDiv
 }
 
+// defineFloorMod defines the integer remainder of a floor division like Python's % operator.
+// The result always has the same sign as the divisor and a divisor of zero raises a ZeroDivisionError.
+func (cg *CodeGenerator) defineFloorMod() *ir.Func {
+	lhs := ir.NewParam("", types.I32)
+	rhs := ir.NewParam("", types.I32)
+	floorMod := cg.Module.NewFunc("floormod", types.I32, lhs, rhs)
+	funcBlock := floorMod.NewBlock(cg.uniqueNames.get("entry"))
+
+	modBlock, negOneBlock := cg.checkDivisor(floorMod, funcBlock, rhs)
+
+	// Any number is divisible by -1 and srem overflows for the smallest i32 value.
+	negOneBlock.NewRet(constant.NewInt(types.I32, 0))
+
+	truncRem := modBlock.NewSRem(lhs, rhs)
+	truncRem.LocalName = cg.uniqueNames.get("mod_rem_trunc")
+
+	// floor_mod(x, y) = x % y + ((x % y != 0 and (x % y < 0) != (y < 0)) ? y : 0)
+	addRhs := cg.needsFloorAdjustment(modBlock, truncRem, rhs)
+	adjustment := modBlock.NewSelect(addRhs, rhs, constant.NewInt(types.I32, 0))
+	adjustment.LocalName = cg.uniqueNames.get("mod_adjust")
+	floorRes := modBlock.NewAdd(truncRem, adjustment)
+	floorRes.LocalName = cg.uniqueNames.get("floor_mod_res")
+
+	modBlock.NewRet(floorRes)
+
+	return floorMod
+}
+
+// checkDivisor raises a ZeroDivisionError if the divisor is zero.
+// It returns the block in which the division should be performed as well as a block for the special case of a divisor of -1.
+func (cg *CodeGenerator) checkDivisor(function *ir.Func, block *ir.Block, rhs value.Value) (*ir.Block, *ir.Block) {
+	zeroBlock := function.NewBlock("divisor.zero")
+	nonZeroBlock := function.NewBlock("divisor.nonzero")
+	negOneBlock := function.NewBlock("divisor.negone")
+	divBlock := function.NewBlock("divisor.valid")
+
+	isZero := block.NewICmp(enum.IPredEQ, rhs, constant.NewInt(types.I32, 0))
+	isZero.LocalName = cg.uniqueNames.get("divisor_is_zero")
+	block.NewCondBr(isZero, zeroBlock, nonZeroBlock)
+
+	/* Raise runtime exception division by zero */
+	cg.exceptionHelper(zeroBlock, "error_div_zero")
+	zeroBlock.NewUnreachable()
+
+	isNegOne := nonZeroBlock.NewICmp(enum.IPredEQ, rhs, constant.NewInt(types.I32, -1))
+	isNegOne.LocalName = cg.uniqueNames.get("divisor_is_neg_one")
+	nonZeroBlock.NewCondBr(isNegOne, negOneBlock, divBlock)
+
+	return divBlock, negOneBlock
+}
+
+// needsFloorAdjustment checks whether the result of a truncating division has to be adjusted to
+// get the result of a flooring division, which is the case if the remainder and the divisor have different signs.
+func (cg *CodeGenerator) needsFloorAdjustment(block *ir.Block, truncRem value.Value, rhs value.Value) value.Value {
+	zero := constant.NewInt(types.I32, 0)
+
+	remNonZero := block.NewICmp(enum.IPredNE, truncRem, zero)
+	remNonZero.LocalName = cg.uniqueNames.get("rem_nonzero")
+	remNeg := block.NewICmp(enum.IPredSLT, truncRem, zero)
+	remNeg.LocalName = cg.uniqueNames.get("rem_neg")
+	rhsNeg := block.NewICmp(enum.IPredSLT, rhs, zero)
+	rhsNeg.LocalName = cg.uniqueNames.get("rhs_neg")
+	signsDiffer := block.NewXor(remNeg, rhsNeg)
+	signsDiffer.LocalName = cg.uniqueNames.get("signs_differ")
+
+	needsAdjustment := block.NewAnd(remNonZero, signsDiffer)
+	needsAdjustment.LocalName = cg.uniqueNames.get("needs_adjustment")
+	return needsAdjustment
+}
+
 func (cg *CodeGenerator) defineNewInt() *ir.Func {
 	intLiteral := ir.NewParam("", types.I32)
 	newInt := cg.Module.NewFunc("newint", types.I32, intLiteral)
@@ -22,7 +22,7 @@ func (cg *CodeGenerator) VisitAssignStmt(assignStmt *ast.AssignStmt) {
 	assignValue.Visit(cg)
 	value := cg.lastGenerated
 
-	if isIdentOrIndex(assignValue) {
+	if isAddress(assignValue) {
 		value = cg.LoadVal(value)
 	}
 
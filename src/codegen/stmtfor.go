@@ -2,7 +2,6 @@ package codegen
 
 import (
 	"chogopy/src/ast"
-	"log"
 
 	"github.com/llir/llvm/ir/constant"
 	"github.com/llir/llvm/ir/enum"
@@ -18,14 +17,14 @@ func (cg *CodeGenerator) VisitForStmt(forStmt *ast.ForStmt) {
 
 	iterNameInfo, err := cg.getVar(forStmt.IterName)
 	if err != nil {
-		log.Fatalln(err.Error())
+		panic(err)
 	}
 
 	iterName := iterNameInfo.value
 
 	forStmt.Iter.Visit(cg)
 	iterVal := cg.lastGenerated
-	if isIdentOrIndex(forStmt.Iter) {
+	if isAddress(forStmt.Iter) {
 		iterVal = cg.LoadVal(iterVal)
 	}
 
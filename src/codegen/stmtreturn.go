@@ -13,9 +13,10 @@ func (cg *CodeGenerator) VisitReturnStmt(returnStmt *ast.ReturnStmt) {
 		returnStmt.ReturnVal.Visit(cg)
 		returnVal = cg.lastGenerated
 
-		if isIdentOrIndex(returnStmt.ReturnVal) {
+		if isAddress(returnStmt.ReturnVal) {
 			returnVal = cg.LoadVal(returnVal)
 		}
+		returnVal = cg.castPtr(returnVal, cg.currentFunction.Sig.RetType)
 	}
 
 	cg.currentBlock.NewRet(returnVal)
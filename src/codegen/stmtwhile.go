@@ -14,7 +14,7 @@ func (cg *CodeGenerator) VisitWhileStmt(whileStmt *ast.WhileStmt) {
 	whileStmt.Condition.Visit(cg)
 	cond := cg.lastGenerated
 
-	if isIdentOrIndex(whileStmt.Condition) {
+	if isAddress(whileStmt.Condition) {
 		cond = cg.LoadVal(cond)
 	}
 
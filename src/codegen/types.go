@@ -2,7 +2,7 @@ package codegen
 
 import (
 	"chogopy/src/ast"
-	"log"
+	"fmt"
 
 	"github.com/kr/pretty"
 	"github.com/llir/llvm/ir"
@@ -29,7 +29,8 @@ import (
 type TypeEnvBuilder struct {
 	Module *ir.Module
 
-	types Types
+	types   Types
+	classes Classes
 
 	uniqueNames UniqueNames
 
@@ -61,6 +62,9 @@ func (tb *TypeEnvBuilder) Build(program *ast.Program) {
 	tb.types["list"] = listType
 	// cg.types["FILE"] = fileType
 
+	tb.classes = Classes{}
+	tb.registerClasses(program)
+
 	program.Visit(tb)
 }
 
@@ -114,6 +118,10 @@ func (tb *TypeEnvBuilder) attrToType(attr ast.TypeAttr) types.Type {
 		return types.NewPointer(tb.getOrCreate(listType))
 	}
 
+	if classAttr, ok := attr.(ast.ClassAttribute); ok {
+		return types.NewPointer(tb.classes[classAttr.ClassName].structType)
+	}
+
 	switch attr.(ast.BasicAttribute) {
 	case ast.Integer:
 		return types.I32
@@ -129,8 +137,7 @@ func (tb *TypeEnvBuilder) attrToType(attr ast.TypeAttr) types.Type {
 		return types.NewPointer(tb.types["object"])
 	}
 
-	log.Fatalf("Expected type attribute but got: %# v", pretty.Formatter(attr))
-	return nil
+	panic(fmt.Sprintf("Expected type attribute but got: %# v", pretty.Formatter(attr)))
 }
 
 func (tb *TypeEnvBuilder) astTypeToType(astType ast.Node) types.Type {
@@ -162,6 +169,9 @@ func (tb *TypeEnvBuilder) astTypeToType(astType ast.Node) types.Type {
 		return types.NewPointer(tb.types["object"])
 	}
 
-	log.Fatalf("Expected AST Type but got: %# v", pretty.Formatter(astType))
-	return nil
+	if classInfo, ok := tb.classes[astType.(*ast.NamedType).TypeName]; ok {
+		return types.NewPointer(classInfo.structType)
+	}
+
+	panic(fmt.Sprintf("Expected AST Type but got: %# v", pretty.Formatter(astType)))
 }
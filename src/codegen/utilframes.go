@@ -0,0 +1,163 @@
+package codegen
+
+import (
+	"chogopy/src/ast"
+	"slices"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+const staticLinkName = "static.link"
+
+// FuncInfo describes the lexical environment of a function.
+//
+// Every function that contains nested functions allocates a frame on its stack.
+// The frame holds the static link of the function itself followed by pointers to each of its parameters and local variables:
+//
+//	frame.f -->  {frame.parent*, var0*, var1*, ...}
+//
+// A nested function receives a pointer to the frame of its enclosing function as its static link.
+// By following the chain of static links, it can then read and write (nonlocal) variables of any enclosing function.
+type FuncInfo struct {
+	enclosing *ir.Func
+	nested    Functions
+
+	frameType *types.StructType
+	frame     value.Value
+	frameVars []string
+}
+
+func (cg *CodeGenerator) newFuncInfo(function *ir.Func, funcDef *ast.FuncDef) *FuncInfo {
+	funcInfo := &FuncInfo{nested: Functions{}}
+	if enclosingFunction, ok := cg.enclosingFunctions[function]; ok {
+		funcInfo.enclosing = enclosingFunction
+	}
+	cg.funcInfos[function] = funcInfo
+
+	hasNested := slices.ContainsFunc(funcDef.FuncBody, func(bodyNode ast.Node) bool {
+		_, isFuncDef := bodyNode.(*ast.FuncDef)
+		return isFuncDef
+	})
+	if !hasNested {
+		return funcInfo
+	}
+
+	var staticLinkType types.Type = types.I8Ptr
+	if funcInfo.enclosing != nil {
+		staticLinkType = types.NewPointer(cg.funcInfos[funcInfo.enclosing].frameType)
+	}
+
+	frameFields := []types.Type{staticLinkType}
+	funcInfo.frameVars = []string{staticLinkName}
+
+	for _, paramNode := range funcDef.Parameters {
+		paramType := cg.astTypeToType(paramNode.(*ast.TypedVar).VarType)
+		frameFields = append(frameFields, types.NewPointer(paramType))
+		funcInfo.frameVars = append(funcInfo.frameVars, paramNode.(*ast.TypedVar).VarName)
+	}
+	for _, bodyNode := range funcDef.FuncBody {
+		if varDef, ok := bodyNode.(*ast.VarDef); ok {
+			varType := cg.astTypeToType(varDef.TypedVar.(*ast.TypedVar).VarType)
+			frameFields = append(frameFields, types.NewPointer(varType))
+			funcInfo.frameVars = append(funcInfo.frameVars, varDef.TypedVar.(*ast.TypedVar).VarName)
+		}
+	}
+
+	funcInfo.frameType = types.NewStruct(frameFields...)
+	cg.Module.NewTypeDef("frame."+function.Name(), funcInfo.frameType)
+
+	frame := cg.currentBlock.NewAlloca(funcInfo.frameType)
+	frame.LocalName = cg.uniqueNames.get("frame")
+	funcInfo.frame = frame
+
+	var staticLink value.Value = constant.NewNull(types.I8Ptr)
+	if funcInfo.enclosing != nil {
+		staticLink = function.Params[0]
+	}
+	cg.currentBlock.NewStore(staticLink, cg.frameFieldPtr(funcInfo, frame, 0))
+
+	return funcInfo
+}
+
+// storeInFrame makes a local variable of the current function accessible to its nested functions.
+func (cg *CodeGenerator) storeInFrame(varInfo VarInfo) {
+	funcInfo, ok := cg.funcInfos[cg.currentFunction]
+	if !ok || funcInfo.frame == nil {
+		return
+	}
+
+	if fieldIdx := slices.Index(funcInfo.frameVars, varInfo.name); fieldIdx > 0 {
+		cg.NewStore(varInfo.value, cg.frameFieldPtr(funcInfo, funcInfo.frame, fieldIdx))
+	}
+}
+
+// getEnclosingVar looks up a variable in the frames of the functions enclosing the current function.
+// The innermost enclosing function that defines the variable takes precedence.
+func (cg *CodeGenerator) getEnclosingVar(name string) (VarInfo, bool) {
+	funcInfo, ok := cg.funcInfos[cg.currentFunction]
+	if !ok {
+		return VarInfo{}, false
+	}
+
+	for enclosing := funcInfo.enclosing; enclosing != nil; enclosing = cg.funcInfos[enclosing].enclosing {
+		enclosingInfo := cg.funcInfos[enclosing]
+
+		if fieldIdx := slices.Index(enclosingInfo.frameVars, name); fieldIdx > 0 {
+			frame := cg.frameOf(enclosing)
+			varPtrType := enclosingInfo.frameType.Fields[fieldIdx].(*types.PointerType)
+
+			varPtr := cg.currentBlock.NewLoad(varPtrType, cg.frameFieldPtr(enclosingInfo, frame, fieldIdx))
+			varPtr.LocalName = cg.uniqueNames.get("nonlocal_ptr")
+
+			return VarInfo{name: name, elemType: varPtrType.ElemType, value: varPtr}, true
+		}
+	}
+	return VarInfo{}, false
+}
+
+// getFunc looks up a function by its name. Functions nested inside of the current function or
+// inside of any of its enclosing functions take precedence over functions defined at the top level.
+// If the function is a nested function, the static link it expects is returned alongside it.
+func (cg *CodeGenerator) getFunc(name string) (*ir.Func, value.Value) {
+	for function := cg.currentFunction; function != nil; function = cg.funcInfos[function].enclosing {
+		funcInfo, ok := cg.funcInfos[function]
+		if !ok {
+			break
+		}
+		if nestedFunction, ok := funcInfo.nested[name]; ok {
+			return nestedFunction, cg.frameOf(function)
+		}
+	}
+	return cg.functions[name], nil
+}
+
+// frameOf follows the chain of static links from the current function up to the given enclosing function
+// and returns a pointer to the frame of that function.
+func (cg *CodeGenerator) frameOf(target *ir.Func) value.Value {
+	function := cg.currentFunction
+	funcInfo := cg.funcInfos[function]
+	if function == target {
+		return funcInfo.frame
+	}
+
+	frame := value.Value(function.Params[0])
+	for function = funcInfo.enclosing; function != target; function = cg.funcInfos[function].enclosing {
+		enclosingInfo := cg.funcInfos[function]
+		staticLinkType := enclosingInfo.frameType.Fields[0]
+
+		staticLink := cg.currentBlock.NewLoad(staticLinkType, cg.frameFieldPtr(enclosingInfo, frame, 0))
+		staticLink.LocalName = cg.uniqueNames.get("static_link")
+		frame = staticLink
+	}
+	return frame
+}
+
+func (cg *CodeGenerator) frameFieldPtr(funcInfo *FuncInfo, frame value.Value, fieldIdx int) value.Value {
+	zero := constant.NewInt(types.I32, 0)
+	fieldPtr := cg.currentBlock.NewGetElementPtr(funcInfo.frameType, frame, zero, constant.NewInt(types.I32, int64(fieldIdx)))
+	fieldPtr.LocalName = cg.uniqueNames.get("frame_field_ptr")
+	return fieldPtr
+}
@@ -1,8 +1,6 @@
 package codegen
 
 import (
-	"log"
-
 	"github.com/llir/llvm/ir"
 	"github.com/llir/llvm/ir/constant"
 	"github.com/llir/llvm/ir/types"
@@ -19,13 +17,28 @@ func (cg *CodeGenerator) NewStore(src value.Value, target value.Value) {
 	cg.currentBlock.NewStore(src, target)
 }
 
+// castPtr casts the given value into the target type if both of them are pointers of a different type.
+// This is the case whenever an object is used in a place where an object of one of its superclasses is expected.
+func (cg *CodeGenerator) castPtr(val value.Value, targetType types.Type) value.Value {
+	_, valIsPtr := val.Type().(*types.PointerType)
+	_, targetIsPtr := targetType.(*types.PointerType)
+	if !valIsPtr || !targetIsPtr || val.Type().Equal(targetType) {
+		return val
+	}
+
+	ptrCast := cg.currentBlock.NewBitCast(val, targetType)
+	ptrCast.LocalName = cg.uniqueNames.get("ptr_cast")
+	return ptrCast
+}
+
 // NewLiteral takes any literal of type int, bool, string, or nil and creates a new allocation and store for that value.
 // It returns an SSA value containing the value of the given literal with the following types:
 // int: i32   str: i8*   bool: i1   nil: %none*
 func (cg *CodeGenerator) NewLiteral(literal any) value.Value {
 	switch literal := literal.(type) {
 	case int:
-		intConst := constant.NewInt(types.I32, int64(literal))
+		// The literal 2147483648 only occurs negated, so it is wrapped around to -2147483648 before it is negated again
+		intConst := constant.NewInt(types.I32, int64(int32(literal)))
 		intLiteral := cg.currentBlock.NewCall(cg.functions["newint"], intConst)
 		intLiteral.LocalName = cg.uniqueNames.get("int_literal")
 		return intLiteral
@@ -66,8 +79,7 @@ func (cg *CodeGenerator) NewLiteral(literal any) value.Value {
 		return noneLoad
 	}
 
-	log.Fatalln("NewLiteral: expected literal of type int, bool, str, or nil")
-	return nil
+	panic("NewLiteral: expected literal of type int, bool, str, or nil")
 }
 
 // LoadVal can be used to load the value out of an identifier or an index expression.
@@ -103,7 +115,7 @@ func (cg *CodeGenerator) NewAllocN(elemType types.Type, NElems value.Value) *ir.
 }
 
 func (cg *CodeGenerator) NewMalloc(elemType types.Type, NElems value.Value) value.Value {
-	elemPtr := cg.currentBlock.NewCall(cg.functions["malloc"], cg.sizeof(elemType, NElems))
+	elemPtr := cg.currentBlock.NewCall(cg.functions["alloc"], cg.sizeof(elemType, NElems))
 	elemPtr.LocalName = cg.uniqueNames.get("heap_ptr")
 	elemPtrCast := cg.currentBlock.NewBitCast(elemPtr, types.NewPointer(elemType))
 	elemPtrCast.LocalName = cg.uniqueNames.get("heap_ptr_cast")
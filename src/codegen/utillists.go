@@ -18,7 +18,7 @@ func isList(val value.Value) bool {
 }
 
 func isListType(type_ types.Type) bool {
-	return strings.Contains(type_.Name(), "list") && type_.Name() != "list" && type_.Name() != "list_content"
+	return strings.HasPrefix(type_.Name(), "list") && type_.Name() != "list" && type_.Name() != "list_content"
 }
 
 func getListElemType(list value.Value) types.Type {
@@ -77,23 +77,21 @@ func (cg *CodeGenerator) setListContent(list value.Value, content value.Value) {
 // TODO: move this into its own function to avoid code repetition
 func (cg *CodeGenerator) concatLists(lhs value.Value, rhs value.Value, listType types.Type) value.Value {
 	zero := constant.NewInt(types.I32, 0)
-	four := constant.NewInt(types.I32, 4)
+	concatListElemType := getListElemTypeFromListType(listType)
 
-	// Compute lhs list content pointer and length (word-to-byte-adjusted)
+	// Compute lhs list content pointer and length (element-to-byte-adjusted according to the data layout)
 	lhsContentPtr := cg.getListElemPtr(lhs, zero)
 	lhsLenFunc := lhs.Type().(*types.PointerType).ElemType.Name() + "_len"
 	lhsLen := cg.currentBlock.NewCall(cg.functions[lhsLenFunc], lhs)
 	lhsLen.LocalName = cg.uniqueNames.get("lhs_len_word")
-	lhsLenByte := cg.currentBlock.NewMul(lhsLen, four)
-	lhsLenByte.LocalName = cg.uniqueNames.get("lhs_len_byte")
+	lhsLenByte := cg.sizeof(concatListElemType, lhsLen)
 
-	// Compute rhs list content pointer and length (word-to-byte-adjusted)
+	// Compute rhs list content pointer and length (element-to-byte-adjusted according to the data layout)
 	rhsContentPtr := cg.getListElemPtr(rhs, zero)
 	rhsLenFunc := rhs.Type().(*types.PointerType).ElemType.Name() + "_len"
 	rhsLen := cg.currentBlock.NewCall(cg.functions[rhsLenFunc], rhs)
 	rhsLen.LocalName = cg.uniqueNames.get("rhs_len_word")
-	rhsLenByte := cg.currentBlock.NewMul(rhsLen, four)
-	rhsLenByte.LocalName = cg.uniqueNames.get("rhs_len_byte")
+	rhsLenByte := cg.sizeof(concatListElemType, rhsLen)
 
 	// Heap-allocation for the list struct
 	concatPtr := cg.NewMalloc(listType, constant.NewInt(types.I32, 1))
@@ -104,7 +102,6 @@ func (cg *CodeGenerator) concatLists(lhs value.Value, rhs value.Value, listType
 	concatInit := constant.NewBool(true)
 
 	// Heap-allocation for the list content
-	concatListElemType := getListElemTypeFromListType(listType)
 	concatContentPtr := cg.NewMalloc(concatListElemType, concatLen)
 
 	// Copy lhs into concat content and then rhs into shifted concat content ptr
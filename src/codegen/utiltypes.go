@@ -2,7 +2,7 @@ package codegen
 
 import (
 	"chogopy/src/ast"
-	"log"
+	"fmt"
 
 	"github.com/kr/pretty"
 	"github.com/llir/llvm/ir/constant"
@@ -39,6 +39,8 @@ func structEqual(s1 types.Type, s2 types.Type) bool {
 	return true
 }
 
+// sizeof computes the size in bytes of the given number of elements of the given type.
+// LLVM folds the size of the type into a constant according to the data layout of the module.
 func (cg *CodeGenerator) sizeof(type_ types.Type, multiplier value.Value) value.Value {
 	typeSize := cg.currentBlock.NewGetElementPtr(type_, constant.NewNull(types.NewPointer(type_)), constant.NewInt(types.I32, 1))
 	typeSize.LocalName = cg.uniqueNames.get("type_size_ptr")
@@ -56,8 +58,7 @@ func (cg *CodeGenerator) getListType(checkType types.Type) types.Type {
 		}
 	}
 
-	log.Fatalf("getListType: Expected list type but found: %# v\n", pretty.Formatter(checkType))
-	return nil
+	panic(fmt.Sprintf("getListType: Expected list type but found: %# v", pretty.Formatter(checkType)))
 }
 
 func (cg *CodeGenerator) attrToType(attr ast.TypeAttr) types.Type {
@@ -67,6 +68,10 @@ func (cg *CodeGenerator) attrToType(attr ast.TypeAttr) types.Type {
 		return types.NewPointer(cg.getListType(listType))
 	}
 
+	if classAttr, ok := attr.(ast.ClassAttribute); ok {
+		return types.NewPointer(cg.classes[classAttr.ClassName].structType)
+	}
+
 	switch attr.(ast.BasicAttribute) {
 	case ast.Integer:
 		return types.I32
@@ -82,8 +87,7 @@ func (cg *CodeGenerator) attrToType(attr ast.TypeAttr) types.Type {
 		return types.NewPointer(cg.types["object"])
 	}
 
-	log.Fatalf("Expected type attribute but got: %# v", pretty.Formatter(attr))
-	return nil
+	panic(fmt.Sprintf("Expected type attribute but got: %# v", pretty.Formatter(attr)))
 }
 
 func (cg *CodeGenerator) astTypeToType(astType ast.Node) types.Type {
@@ -110,16 +114,23 @@ func (cg *CodeGenerator) astTypeToType(astType ast.Node) types.Type {
 		return types.NewPointer(cg.types["object"])
 	}
 
-	log.Fatalf("Expected AST type but got: %# v", pretty.Formatter(astType))
-	return nil
+	if classInfo, ok := cg.classes[astType.(*ast.NamedType).TypeName]; ok {
+		return types.NewPointer(classInfo.structType)
+	}
+
+	panic(fmt.Sprintf("Expected AST type but got: %# v", pretty.Formatter(astType)))
 }
 
-func isIdentOrIndex(astNode ast.Node) bool {
+// isAddress determines whether the value generated for the given node is the address of a variable,
+// a list element, or an attribute, in which case the actual value still has to be loaded via LoadVal().
+func isAddress(astNode ast.Node) bool {
 	switch astNode.(type) {
 	case *ast.IdentExpr:
 		return true
 	case *ast.IndexExpr:
 		return true
+	case *ast.MemberExpr:
+		return true
 	}
 	return false
 }
@@ -0,0 +1,385 @@
+// Package compiler ties the phases of the compiler together so that
+// the whole pipeline can be embedded into other programs.
+//
+// Compile runs the lexer, the parser, the name and type analysis passes, the code generator
+// and the LLVM or C backend on a piece of source code. It never exits the process. Instead, every problem
+// that is found along the way is returned as a diagnostic together with whatever the phases produced.
+package compiler
+
+import (
+	"chogopy/src/ast"
+	"chogopy/src/backend"
+	"chogopy/src/bytecode"
+	"chogopy/src/cbackend"
+	"chogopy/src/codegen"
+	"chogopy/src/diagnostics"
+	"chogopy/src/interp"
+	"chogopy/src/lexer"
+	"chogopy/src/parser"
+	"chogopy/src/scopes"
+	"chogopy/src/typechecks"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Stage is a phase of the compiler after which compilation can be stopped.
+// Every stage includes the stages that come before it.
+type Stage int
+
+const (
+	// StageLex only splits the source code into tokens.
+	StageLex Stage = iota
+	// StageParse parses the tokens into an AST.
+	StageParse
+	// StageNames checks assignment targets and the scopes of every name.
+	StageNames
+	// StageTypes performs static type checking and annotates the AST with types.
+	StageTypes
+	// StageCodegen generates LLVM IR, or C source code if the C backend is used, from the typed AST.
+	StageCodegen
+	// StageBackend optimizes the generated code and compiles it into the requested output kind.
+	StageBackend
+)
+
+// Backend is the code generator and compiler that turn the typed AST into machine code.
+type Backend int
+
+const (
+	// BackendLLVM generates LLVM IR and compiles it with the LLVM libraries that chogopy is linked against
+	// or, if it was built without them, with the opt and llc tools of an installed LLVM toolchain.
+	BackendLLVM Backend = iota
+	// BackendC generates C source code and compiles it with the system C compiler.
+	// It can only produce object files for the host machine.
+	BackendC
+)
+
+// OutputKind is the kind of output that the backend produces.
+type OutputKind int
+
+const (
+	OutputObject OutputKind = iota
+	OutputAssembly
+	// OutputBitcode is the optimized module as LLVM bitcode.
+	OutputBitcode
+)
+
+var fileTypes = map[OutputKind]backend.FileType{
+	OutputObject:   backend.ObjectFile,
+	OutputAssembly: backend.AssemblyFile,
+	OutputBitcode:  backend.BitcodeFile,
+}
+
+type Options struct {
+	// StopAfter is the last stage that is run.
+	StopAfter Stage
+	// OptLevel is the optimization level from 0 to 3 that the backend uses.
+	OptLevel int
+	// Output is the kind of output that the backend produces.
+	Output OutputKind
+	// Target is the machine that code is generated for. The zero value is the host machine.
+	Target backend.Target
+	// Backend is the backend that generates and compiles code.
+	Backend Backend
+}
+
+// DefaultOptions returns the options that run the complete pipeline
+// with full optimization and produce an object file.
+func DefaultOptions() Options {
+	return Options{
+		StopAfter: StageBackend,
+		OptLevel:  3,
+		Output:    OutputObject,
+	}
+}
+
+// Result holds what the stages that were run produced.
+// The fields of stages that were not run or that failed are left empty.
+type Result struct {
+	// Tokens is only filled in when compilation stops after StageLex.
+	Tokens  []lexer.Token
+	Program *ast.Program
+	IR      string
+	// C is the C source code that the C backend generated.
+	C string
+	// Output is the object file, assembly or bitcode produced by the backend.
+	Output []byte
+}
+
+const (
+	BackendFailed         = "E401"
+	LinkFailed            = "E402"
+	InternalCompilerError = "E901"
+)
+
+// Compile compiles the given source code according to the given options.
+// Compilation stops after the first stage that reports an error.
+func Compile(source string, options Options) (result Result, diags []diagnostics.Diagnostic) {
+	sink := diagnostics.NewSink()
+
+	// Any panic in one of the phases is a bug in the compiler itself.
+	// It is reported like any other problem so that the embedding program keeps running.
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			sink.Errorf(InternalCompilerError, diagnostics.Span{}, "Internal compiler error: %v", recovered)
+			diags = sink.Diagnostics()
+		}
+	}()
+
+	if options.OptLevel < 0 || options.OptLevel > 3 {
+		sink.Errorf(BackendFailed, diagnostics.Span{}, "Invalid optimization level %d.", options.OptLevel)
+		return result, sink.Diagnostics()
+	}
+
+	myLexer := lexer.NewLexer(source)
+
+	if options.StopAfter == StageLex {
+		token := myLexer.Consume(false)
+		result.Tokens = append(result.Tokens, token)
+		for token.Kind != lexer.EOF {
+			token = myLexer.Consume(false)
+			result.Tokens = append(result.Tokens, token)
+		}
+		for _, lexicalError := range myLexer.Errors() {
+			sink.Report(myLexer.Diagnostic(lexicalError))
+		}
+		return result, sink.Diagnostics()
+	}
+
+	myParser := parser.NewParser(&myLexer, sink)
+	program := myParser.ParseProgram()
+	result.Program = &program
+	if sink.HasErrors() || options.StopAfter == StageParse {
+		return result, sink.Diagnostics()
+	}
+
+	assignTargets := scopes.AssignTargets{}
+	assignTargets.Analyze(&program, sink)
+	nameScopes := scopes.NameScopes{}
+	nameScopes.Analyze(&program, sink)
+	if sink.HasErrors() || options.StopAfter == StageNames {
+		return result, sink.Diagnostics()
+	}
+
+	staticTyping := typechecks.StaticTyping{}
+	staticTyping.Analyze(&program, sink)
+	if sink.HasErrors() || options.StopAfter == StageTypes {
+		return result, sink.Diagnostics()
+	}
+
+	if options.Backend == BackendC {
+		return compileC(result, options, sink)
+	}
+
+	// The target is needed by the code generator as well since the data layout of the module depends on it
+	triple, dataLayout, err := backend.TargetInfo(options.Target)
+	if err != nil {
+		sink.Errorf(BackendFailed, diagnostics.Span{}, "%s", err)
+		return result, sink.Diagnostics()
+	}
+
+	codeGenerator := codegen.CodeGenerator{
+		TargetTriple: triple,
+		DataLayout:   dataLayout,
+	}
+	codeGenerator.Generate(&program)
+	result.IR = codeGenerator.Module.String()
+	if options.StopAfter == StageCodegen {
+		return result, sink.Diagnostics()
+	}
+
+	output, err := backend.CompileIR(result.IR, options.Target, options.OptLevel, fileTypes[options.Output])
+	if err != nil {
+		diagnostic := diagnostics.Diagnostic{
+			Severity: diagnostics.Error,
+			Code:     BackendFailed,
+			Message:  err.Error(),
+		}
+		var toolError *backend.ToolError
+		if errors.As(err, &toolError) && toolError.Stderr != "" {
+			diagnostic.Notes = strings.Split(toolError.Stderr, "\n")
+		}
+		sink.Report(diagnostic)
+		return result, sink.Diagnostics()
+	}
+	result.Output = output
+
+	return result, sink.Diagnostics()
+}
+
+// compileC generates C source code from the typed AST and compiles it with the system C compiler.
+func compileC(result Result, options Options, sink *diagnostics.Sink) (Result, []diagnostics.Diagnostic) {
+	if options.Target != (backend.Target{}) {
+		sink.Errorf(BackendFailed, diagnostics.Span{}, "The C backend can only generate code for the host machine.")
+		return result, sink.Diagnostics()
+	}
+
+	generator := cbackend.Generator{}
+	generator.Generate(result.Program)
+	result.C = generator.Source
+	if options.StopAfter == StageCodegen {
+		return result, sink.Diagnostics()
+	}
+
+	if options.Output != OutputObject {
+		sink.Errorf(BackendFailed, diagnostics.Span{}, "The C backend can only produce object files.")
+		return result, sink.Diagnostics()
+	}
+
+	output, err := cbackend.CompileObject(result.C, options.OptLevel)
+	if err != nil {
+		diagnostic := diagnostics.Diagnostic{
+			Severity: diagnostics.Error,
+			Code:     BackendFailed,
+			Message:  "Failed to compile the generated C code: " + err.Error(),
+		}
+		var compileError *cbackend.CompileError
+		if errors.As(err, &compileError) && compileError.Stderr != "" {
+			diagnostic.Notes = strings.Split(compileError.Stderr, "\n")
+		}
+		sink.Report(diagnostic)
+		return result, sink.Diagnostics()
+	}
+	result.Output = output
+
+	return result, sink.Diagnostics()
+}
+
+// Run compiles the given source code and executes it right away with the JIT compiler of LLVM
+// instead of producing an object file. It returns the exit code of the program.
+// Programs that fail at runtime write the error message to stderr and return the exit code of the runtime error.
+func Run(source string, options Options) (int, []diagnostics.Diagnostic) {
+	if options.Target != (backend.Target{}) {
+		sink := diagnostics.NewSink()
+		sink.Errorf(BackendFailed, diagnostics.Span{}, "Programs can only be run on the host machine.")
+		return 0, sink.Diagnostics()
+	}
+	if options.Backend != BackendLLVM {
+		sink := diagnostics.NewSink()
+		sink.Errorf(BackendFailed, diagnostics.Span{}, "Programs can only be run with the LLVM backend.")
+		return 0, sink.Diagnostics()
+	}
+	if !backend.Native {
+		sink := diagnostics.NewSink()
+		sink.Errorf(BackendFailed, diagnostics.Span{}, "Programs can only be run if the compiler is built with the native LLVM backend.")
+		return 0, sink.Diagnostics()
+	}
+
+	options.StopAfter = StageCodegen
+	result, diags := Compile(source, options)
+	if diagnostics.ErrorCount(diags) > 0 {
+		return 0, diags
+	}
+
+	sink := diagnostics.NewSink()
+	for _, diagnostic := range diags {
+		sink.Report(diagnostic)
+	}
+
+	exitCode, err := backend.RunIR(result.IR, options.Target, options.OptLevel)
+	if err != nil {
+		sink.Errorf(BackendFailed, diagnostics.Span{}, "%s", err)
+		return 0, sink.Diagnostics()
+	}
+
+	return exitCode, sink.Diagnostics()
+}
+
+// Interpret checks the given source code and executes it with the tree-walking interpreter
+// instead of compiling it. The program reads from stdin and prints to stdout.
+// If it raises a runtime error, the message of the error is written to stderr
+// and the exit code of the error is returned, just like a compiled program would do.
+func Interpret(source string, stdin io.Reader, stdout io.Writer, stderr io.Writer) (int, []diagnostics.Diagnostic) {
+	options := DefaultOptions()
+	options.StopAfter = StageTypes
+	result, diags := Compile(source, options)
+	if diagnostics.ErrorCount(diags) > 0 {
+		return 0, diags
+	}
+
+	interpreter := interp.NewInterpreter(stdin, stdout)
+	err := interpreter.Run(result.Program)
+
+	var runtimeError *interp.RuntimeError
+	if errors.As(err, &runtimeError) {
+		fmt.Fprintln(stderr, runtimeError.Message)
+		return runtimeError.ExitCode(), diags
+	}
+
+	return 0, diags
+}
+
+// CompileBytecode checks the given source code and compiles it into bytecode for the virtual machine.
+func CompileBytecode(source string) (*bytecode.Program, []diagnostics.Diagnostic) {
+	options := DefaultOptions()
+	options.StopAfter = StageTypes
+	result, diags := Compile(source, options)
+	if diagnostics.ErrorCount(diags) > 0 {
+		return nil, diags
+	}
+
+	program, err := bytecode.Compile(result.Program)
+	if err != nil {
+		sink := diagnostics.NewSink()
+		for _, diagnostic := range diags {
+			sink.Report(diagnostic)
+		}
+		sink.Errorf(BackendFailed, diagnostics.Span{}, "%s", err)
+		return nil, sink.Diagnostics()
+	}
+	return program, diags
+}
+
+// RunBytecode executes the given bytecode program on the virtual machine.
+// Runtime errors are handled like Interpret does. An error is only returned if the program is malformed.
+func RunBytecode(program *bytecode.Program, stdin io.Reader, stdout io.Writer, stderr io.Writer) (int, error) {
+	vm := bytecode.NewVM(program, stdin, stdout)
+	err := vm.Run()
+
+	var runtimeError *bytecode.RuntimeError
+	if errors.As(err, &runtimeError) {
+		fmt.Fprintln(stderr, runtimeError.Message)
+		return runtimeError.ExitCode(), nil
+	}
+	return 0, err
+}
+
+// Link links the given object code into an executable at the given path with the given linker.
+// The object file is placed in a temporary directory that is removed afterwards, even if linking fails.
+// If the linker fails, everything it wrote to stderr is attached to the diagnostic as notes.
+func Link(object []byte, outputPath string, linker backend.Linker) []diagnostics.Diagnostic {
+	sink := diagnostics.NewSink()
+
+	tempDir, err := os.MkdirTemp("", "chogopy-")
+	if err != nil {
+		sink.Errorf(LinkFailed, diagnostics.Span{}, "Failed to create temporary directory: %s", err)
+		return sink.Diagnostics()
+	}
+	defer os.RemoveAll(tempDir)
+
+	objectFilePath := filepath.Join(tempDir, "main.o")
+	err = os.WriteFile(objectFilePath, object, 0o644)
+	if err != nil {
+		sink.Errorf(LinkFailed, diagnostics.Span{}, "Failed to create object file: %s", err)
+		return sink.Diagnostics()
+	}
+
+	err = linker.Link([]string{objectFilePath}, outputPath)
+	if err != nil {
+		diagnostic := diagnostics.Diagnostic{
+			Severity: diagnostics.Error,
+			Code:     LinkFailed,
+			Message:  "Failed to link object file: " + err.Error(),
+		}
+		var linkError *backend.LinkError
+		if errors.As(err, &linkError) && linkError.Stderr != "" {
+			diagnostic.Notes = strings.Split(linkError.Stderr, "\n")
+		}
+		sink.Report(diagnostic)
+	}
+
+	return sink.Diagnostics()
+}
@@ -0,0 +1,334 @@
+package compiler
+
+import (
+	"chogopy/src/backend"
+	"chogopy/src/cbackend"
+	"chogopy/src/codegen"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// llvmAvailable reports whether the compiler was built with the native LLVM backend
+// or the external LLVM tools are installed.
+func llvmAvailable() bool {
+	if backend.Native {
+		return true
+	}
+	for _, tool := range []string{"opt", "llc"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// requireLLVM skips the test if the LLVM backend is not available.
+func requireLLVM(t *testing.T) {
+	if !llvmAvailable() {
+		t.Skip("Neither the native LLVM backend nor the LLVM tools opt and llc are available.")
+	}
+}
+
+func TestCompileToIR(t *testing.T) {
+	stream := `x:int = 1
+print(x + 2)`
+
+	options := DefaultOptions()
+	options.StopAfter = StageCodegen
+	result, diags := Compile(stream, options)
+
+	if len(diags) != 0 {
+		t.Fatalf("Expected no diagnostics but found %d.", len(diags))
+	}
+	if result.Program == nil || len(result.Program.Statements) != 1 {
+		t.Fatalf("Expected the parsed program to be returned.")
+	}
+	if !strings.Contains(result.IR, "define i32 @main()") {
+		t.Fatalf("Expected the IR to contain a main function but found:\n%s", result.IR)
+	}
+	if result.Output != nil {
+		t.Fatalf("Expected the backend not to run.")
+	}
+}
+
+func TestCompileStopsAtErrors(t *testing.T) {
+	stream := `x:int = 1
+print(y)
+print(x 1)`
+
+	result, diags := Compile(stream, DefaultOptions())
+
+	if len(diags) != 1 || diags[0].Code != "E106" {
+		t.Fatalf("Expected a single syntax error but found %v.", diags)
+	}
+	if result.IR != "" || result.Output != nil {
+		t.Fatalf("Expected compilation to stop after parsing.")
+	}
+}
+
+func TestSemanticErrorsReportedOnce(t *testing.T) {
+	definitions := `class A(object):
+    x:int = 1
+    def f(self:"A") -> int:
+        return self.x
+a:A = None
+`
+	tests := []struct {
+		statements string
+		code       string
+	}{
+		{"print(a.y + 1)", "E317"},
+		{"a.x = a.y", "E317"},
+		{"if a.g():\n    pass", "E317"},
+		{"for a in a.g():\n    pass", "E317"},
+		{"print(a.y.x[0])", "E317"},
+		{"a.f = 1", "E319"},
+		{"print(a.f)", "E320"},
+		{"def f(b:A) -> int:\n    A:int = 2\n    return b.x\nprint(f(a))", "E318"},
+		{"def f(b:A) -> int:\n    def A() -> int:\n        return 1\n    return b.x + 1\nprint(f(a))", "E318"},
+	}
+
+	options := DefaultOptions()
+	options.StopAfter = StageTypes
+	for _, test := range tests {
+		_, diags := Compile(definitions+test.statements, options)
+
+		if len(diags) != 1 || diags[0].Code != test.code {
+			t.Fatalf("Expected a single error %s for %q but found %v.", test.code, test.statements, diags)
+		}
+	}
+}
+
+func TestCompileToObject(t *testing.T) {
+	requireLLVM(t)
+	result, diags := Compile("print(1)", DefaultOptions())
+
+	if len(diags) != 0 {
+		t.Fatalf("Expected no diagnostics but found %v.", diags)
+	}
+	if len(result.Output) == 0 {
+		t.Fatalf("Expected an object file to be produced.")
+	}
+}
+
+func TestCompileToBitcode(t *testing.T) {
+	requireLLVM(t)
+	options := DefaultOptions()
+	options.Output = OutputBitcode
+	result, diags := Compile("print(1)", options)
+
+	if len(diags) != 0 {
+		t.Fatalf("Expected no diagnostics but found %v.", diags)
+	}
+	if !strings.HasPrefix(string(result.Output), "BC\xc0\xde") {
+		t.Fatalf("Expected the output to start with the bitcode magic number.")
+	}
+}
+
+func TestCompileToC(t *testing.T) {
+	options := DefaultOptions()
+	options.Backend = BackendC
+	options.StopAfter = StageCodegen
+	result, diags := Compile("print(1)", options)
+
+	if len(diags) != 0 {
+		t.Fatalf("Expected no diagnostics but found %v.", diags)
+	}
+	if !strings.Contains(result.C, "int main(void)") || result.IR != "" {
+		t.Fatalf("Expected C source code instead of IR but found:\n%s", result.C)
+	}
+	if result.Output != nil {
+		t.Fatalf("Expected the C compiler not to run.")
+	}
+}
+
+func TestCompileForTarget(t *testing.T) {
+	options := DefaultOptions()
+	options.StopAfter = StageCodegen
+	options.Target.Triple = "aarch64-linux-gnu"
+	result, diags := Compile("print(1)", options)
+
+	if len(diags) != 0 {
+		t.Fatalf("Expected no diagnostics but found %v.", diags)
+	}
+	if !strings.Contains(result.IR, `target triple = "aarch64`) {
+		t.Fatalf("Expected the IR to contain the target triple but found:\n%s", result.IR)
+	}
+	// Without the LLVM libraries, the data layout is left to the external tools
+	if backend.Native != strings.Contains(result.IR, "target datalayout") {
+		t.Fatalf("Expected the IR to contain a data layout only if the native backend is used but found:\n%s", result.IR)
+	}
+}
+
+func TestRun(t *testing.T) {
+	stream := `def f(x:int) -> int:
+    return x + 1
+f(1)`
+
+	exitCode, diags := Run(stream, DefaultOptions())
+
+	if !backend.Native {
+		if len(diags) != 1 || diags[0].Code != BackendFailed {
+			t.Fatalf("Expected running without the native backend to fail but found %v.", diags)
+		}
+		return
+	}
+	if len(diags) != 0 {
+		t.Fatalf("Expected no diagnostics but found %v.", diags)
+	}
+	if exitCode != 0 {
+		t.Fatalf("Expected exit code 0 but found %d.", exitCode)
+	}
+}
+
+func TestRunRuntimeError(t *testing.T) {
+	if !backend.Native {
+		t.Skip("Programs can only be run with the native LLVM backend.")
+	}
+
+	exitCode, diags := Run("print(1 // 0)\nprint(1)", DefaultOptions())
+
+	if len(diags) != 0 {
+		t.Fatalf("Expected no diagnostics but found %v.", diags)
+	}
+	if exitCode != int(codegen.RuntimeErrors["error_div_zero"].Kind) {
+		t.Fatalf("Expected the exit code of a division by zero but found %d.", exitCode)
+	}
+
+	// The compiler keeps running after the program has failed
+	exitCode, diags = Run("print(1)", DefaultOptions())
+	if len(diags) != 0 || exitCode != 0 {
+		t.Fatalf("Expected the next program to succeed but found exit code %d and %v.", exitCode, diags)
+	}
+}
+
+// execution is what a program wrote to stdout and stderr and the exit code it terminated with.
+type execution struct {
+	stdout   string
+	stderr   string
+	exitCode int
+}
+
+// executeEverywhere runs the given program with the given input on the interpreter and the virtual machine
+// and as an executable that is compiled by the LLVM and C backend, as far as they are available.
+// It returns how the program was executed by each of them.
+func executeEverywhere(t *testing.T, stream string, input string) map[string]execution {
+	executions := map[string]execution{}
+
+	stdout := strings.Builder{}
+	stderr := strings.Builder{}
+	exitCode, diags := Interpret(stream, strings.NewReader(input), &stdout, &stderr)
+	if len(diags) != 0 {
+		t.Fatalf("Expected no diagnostics but found %v.", diags)
+	}
+	executions["interp"] = execution{stdout.String(), stderr.String(), exitCode}
+
+	program, diags := CompileBytecode(stream)
+	if len(diags) != 0 {
+		t.Fatalf("Expected no diagnostics but found %v.", diags)
+	}
+	stdout.Reset()
+	stderr.Reset()
+	exitCode, err := RunBytecode(program, strings.NewReader(input), &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("Expected the bytecode to run but found %v.", err)
+	}
+	executions["vm"] = execution{stdout.String(), stderr.String(), exitCode}
+
+	if _, err := exec.LookPath("cc"); err != nil {
+		return executions
+	}
+	backends := map[string]Backend{}
+	if llvmAvailable() {
+		backends["llvm"] = BackendLLVM
+	}
+	if _, err := exec.LookPath(cbackend.CCompiler()); err == nil {
+		backends["c"] = BackendC
+	}
+	for name, compileBackend := range backends {
+		options := DefaultOptions()
+		options.Backend = compileBackend
+		result, diags := Compile(stream, options)
+		if len(diags) != 0 {
+			t.Fatalf("Expected no diagnostics from the %s backend but found %v.", name, diags)
+		}
+		executablePath := filepath.Join(t.TempDir(), name)
+		if diags := Link(result.Output, executablePath, backend.Linker{Name: "cc"}); len(diags) != 0 {
+			t.Fatalf("Expected the program to link but found %v.", diags)
+		}
+
+		programCmd := exec.Command(executablePath)
+		programCmd.Stdin = strings.NewReader(input)
+		stderr.Reset()
+		programCmd.Stderr = &stderr
+		output, err := programCmd.Output()
+
+		exitCode := 0
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			exitCode = exitError.ExitCode()
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		executions[name] = execution{string(output), stderr.String(), exitCode}
+	}
+	return executions
+}
+
+func TestRuntimeErrors(t *testing.T) {
+	tests := []struct {
+		definitions string
+		statement   string
+		errorName   string
+	}{
+		{"x:[int] = None\n", "print(len(x))", "error_len_none"},
+		{"", "print(len(None))", "error_len_none"},
+		{"x:[int] = None\n", "print(x[0])", "error_index_none"},
+		{"", "print([1, 2][-1])", "error_index_neg"},
+		{"", "print([1, 2][2])", "error_index_oob"},
+		{"class A(object):\n    x:int = 1\na:A = None\n", "print(a.x)", "error_member_none"},
+		{"", "print(1 // 0)", "error_div_zero"},
+		{"", "print(1 % 0)", "error_div_zero"},
+		{"", "print(None)", "error_invalid_argument"},
+		{"", "print([1, 2])", "error_invalid_argument"},
+		{"class A(object):\n    x:int = 1\n", "print(A())", "error_invalid_argument"},
+		{"x:object = None\n", "x = 1\nprint(len(x))", "error_invalid_argument"},
+		{"x:object = None\n", "if True:\n    print(x)", "error_invalid_argument"},
+	}
+
+	for _, test := range tests {
+		stream := test.definitions + "print(0)\n" + test.statement + "\nprint(1)"
+		runtimeError := codegen.RuntimeErrors[test.errorName]
+		expected := execution{"0\n", runtimeError.Message + "\n", int(runtimeError.Kind)}
+
+		for name, execution := range executeEverywhere(t, stream, "") {
+			if execution != expected {
+				t.Fatalf("Expected %s to be raised by %s but found exit code %d, stdout %q and stderr %q.",
+					test.errorName, name, execution.exitCode, execution.stdout, execution.stderr)
+			}
+		}
+	}
+}
+
+func TestInputIsReadByLine(t *testing.T) {
+	stream := `s:str = ""
+s = input()
+print(s)
+print(len(s))
+print(len(input()))
+print(len(input()))
+print(input())
+print(len(input()))`
+	input := "hello  big world \n\r\n" + strings.Repeat("x", 2500) + "\nlast"
+	expected := execution{"hello  big world \n17\n0\n2500\nlast\n0\n", "", 0}
+
+	for name, execution := range executeEverywhere(t, stream, input) {
+		if execution != expected {
+			t.Fatalf("Expected the input to be read line by line by %s but found exit code %d, stdout %q and stderr %q.",
+				name, execution.exitCode, execution.stdout, execution.stderr)
+		}
+	}
+}
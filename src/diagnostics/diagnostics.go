@@ -0,0 +1,157 @@
+// Package diagnostics provides a common representation for the errors and warnings
+// that are reported by the different phases of the compiler.
+package diagnostics
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+	Note
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	case Note:
+		return "note"
+	}
+	return "unknown"
+}
+
+// Position is a location in the source code given by its byte offset as well as its line and column,
+// which are both counted from 1. A position whose line is 0 does not refer to any location.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// Span describes a region of the source code.
+// It starts at the first character of the region and ends right after its last character.
+type Span struct {
+	Start Position
+	End   Position
+}
+
+func (s Span) IsValid() bool {
+	return s.Start.IsValid()
+}
+
+// Diagnostic is a single problem that was found in the source code.
+// The code uniquely identifies the kind of the problem so that tools are able to match on it
+// and the notes contain any additional information that may help with resolving the problem.
+type Diagnostic struct {
+	Severity Severity
+	Code     string
+	Message  string
+	Span     Span
+	Notes    []string
+}
+
+func (d Diagnostic) String() string {
+	if d.Span.IsValid() {
+		return fmt.Sprintf("%s[%s] (line %d, column %d): %s", d.Severity, d.Code, d.Span.Start.Line, d.Span.Start.Column, d.Message)
+	}
+	return fmt.Sprintf("%s[%s]: %s", d.Severity, d.Code, d.Message)
+}
+
+// Sink collects the diagnostics that are reported by the phases of the compiler.
+// Phases keep going after reporting an error wherever possible so that
+// every problem in the source code is reported at once.
+type Sink struct {
+	diagnostics []Diagnostic
+}
+
+func NewSink() *Sink {
+	return &Sink{diagnostics: []Diagnostic{}}
+}
+
+func (s *Sink) Report(diagnostic Diagnostic) {
+	s.diagnostics = append(s.diagnostics, diagnostic)
+}
+
+// Errorf reports an error with the given code at the given location in the source code.
+func (s *Sink) Errorf(code string, span Span, format string, args ...any) {
+	s.Report(Diagnostic{
+		Severity: Error,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+		Span:     span,
+	})
+}
+
+func (s *Sink) Diagnostics() []Diagnostic {
+	return s.diagnostics
+}
+
+func (s *Sink) HasErrors() bool {
+	return s.ErrorCount() > 0
+}
+
+func (s *Sink) ErrorCount() int {
+	return ErrorCount(s.diagnostics)
+}
+
+// Print writes every reported diagnostic to w in the order in which they were reported.
+func (s *Sink) Print(w io.Writer, source string) {
+	Print(w, s.diagnostics, source)
+}
+
+// ErrorCount returns the number of the given diagnostics that are errors.
+func ErrorCount(diagnostics []Diagnostic) int {
+	errorCount := 0
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == Error {
+			errorCount++
+		}
+	}
+	return errorCount
+}
+
+// Print writes the given diagnostics to w.
+// Diagnostics that refer to a location in the given source code are followed by
+// the line that contains the location and a marker pointing to its column:
+//
+//	error[E106] (line 1, column 9): Expected token not found.
+//	>>>print(1 2)
+//	>>>--------^
+func Print(w io.Writer, diagnostics []Diagnostic, source string) {
+	for _, diagnostic := range diagnostics {
+		fmt.Fprintln(w, diagnostic)
+
+		if diagnostic.Span.IsValid() {
+			start := diagnostic.Span.Start
+			fmt.Fprintf(w, ">>>%s\n", lineAt(source, start.Offset))
+			fmt.Fprintln(w, ">>>"+strings.Repeat("-", start.Column-1)+"^")
+		}
+
+		for _, note := range diagnostic.Notes {
+			fmt.Fprintf(w, "%s: %s\n", Note, note)
+		}
+	}
+}
+
+// lineAt returns the line of the source code that contains the given offset without its line break.
+func lineAt(source string, offset int) string {
+	offset = min(max(offset, 0), len(source))
+	lineStart := strings.LastIndexByte(source[:offset], '\n') + 1
+	lineEnd := strings.IndexByte(source[offset:], '\n')
+	if lineEnd < 0 {
+		return source[lineStart:]
+	}
+	return strings.TrimSuffix(source[lineStart:offset+lineEnd], "\r")
+}
@@ -0,0 +1,71 @@
+package interp
+
+import "chogopy/src/ast"
+
+func (it *Interpreter) VisitVarDef(varDef *ast.VarDef) {
+	varName := varDef.TypedVar.(*ast.TypedVar).VarName
+	value := it.evaluate(varDef.Literal)
+	it.frame.variables[varName] = &value
+}
+
+func (it *Interpreter) VisitFuncDef(funcDef *ast.FuncDef) {
+	it.frame.functions[funcDef.FuncName] = &Function{def: funcDef, frame: it.frame}
+}
+
+// VisitClassDef registers the class so that objects of it can be constructed.
+// Attributes are only initialized once an object is constructed.
+func (it *Interpreter) VisitClassDef(classDef *ast.ClassDef) {
+	class := &Class{
+		Name:       classDef.ClassName,
+		SuperClass: it.classes[classDef.SuperClass],
+		Methods:    map[string]*ast.FuncDef{},
+	}
+
+	for _, definition := range classDef.ClassBody {
+		switch definition := definition.(type) {
+		case *ast.VarDef:
+			class.Attributes = append(class.Attributes, definition)
+		case *ast.FuncDef:
+			class.Methods[definition.FuncName] = definition
+		}
+	}
+
+	it.classes[classDef.ClassName] = class
+}
+
+func (it *Interpreter) VisitGlobalDecl(globalDecl *ast.GlobalDecl) {
+	it.frame.variables[globalDecl.DeclName] = it.globals.variables[globalDecl.DeclName]
+}
+
+// VisitNonLocalDecl shares the cell of the variable with the closest enclosing function that defines it.
+func (it *Interpreter) VisitNonLocalDecl(nonLocalDecl *ast.NonLocalDecl) {
+	for frame := it.frame.parent; frame != nil; frame = frame.parent {
+		if cell, ok := frame.variables[nonLocalDecl.DeclName]; ok {
+			it.frame.variables[nonLocalDecl.DeclName] = cell
+			return
+		}
+	}
+}
+
+// newObject constructs an object of the given class.
+// The attributes are initialized starting with the ones of the topmost superclass before __init__ is called.
+func (it *Interpreter) newObject(class *Class) *Object {
+	object := &Object{Class: class, Attributes: map[string]Value{}}
+
+	classes := []*Class{}
+	for superClass := class; superClass != nil; superClass = superClass.SuperClass {
+		classes = append([]*Class{superClass}, classes...)
+	}
+	for _, superClass := range classes {
+		for _, attribute := range superClass.Attributes {
+			attrName := attribute.TypedVar.(*ast.TypedVar).VarName
+			object.Attributes[attrName] = it.evaluate(attribute.Literal)
+		}
+	}
+
+	if init := class.method("__init__"); init != nil {
+		it.call(&Function{def: init, frame: it.globals}, []Value{object})
+	}
+
+	return object
+}
@@ -0,0 +1,271 @@
+package interp
+
+import (
+	"chogopy/src/ast"
+	"fmt"
+	"io"
+	"strings"
+)
+
+func (it *Interpreter) VisitLiteralExpr(literalExpr *ast.LiteralExpr) {
+	switch literal := literalExpr.Value.(type) {
+	case int:
+		it.lastValue = int32(literal)
+	default:
+		it.lastValue = literal
+	}
+}
+
+func (it *Interpreter) VisitIdentExpr(identExpr *ast.IdentExpr) {
+	it.lastValue = *it.lookup(identExpr.Identifier)
+}
+
+func (it *Interpreter) VisitUnaryExpr(unaryExpr *ast.UnaryExpr) {
+	value := it.evaluate(unaryExpr.Value)
+
+	switch unaryExpr.Op {
+	case "-":
+		it.lastValue = -value.(int32)
+	case "not":
+		it.lastValue = !value.(bool)
+	}
+}
+
+// VisitBinaryExpr evaluates the right operand of "and" and "or" only if the left operand does not already determine the result.
+func (it *Interpreter) VisitBinaryExpr(binaryExpr *ast.BinaryExpr) {
+	lhs := it.evaluate(binaryExpr.Lhs)
+
+	switch binaryExpr.Op {
+	case "and":
+		it.lastValue = lhs.(bool) && it.evaluate(binaryExpr.Rhs).(bool)
+		return
+	case "or":
+		it.lastValue = lhs.(bool) || it.evaluate(binaryExpr.Rhs).(bool)
+		return
+	}
+
+	rhs := it.evaluate(binaryExpr.Rhs)
+
+	switch binaryExpr.Op {
+	case "+":
+		it.lastValue = add(lhs, rhs)
+	case "-":
+		it.lastValue = lhs.(int32) - rhs.(int32)
+	case "*":
+		it.lastValue = lhs.(int32) * rhs.(int32)
+	case "//":
+		it.lastValue = floorDiv(lhs.(int32), rhs.(int32))
+	case "%":
+		it.lastValue = floorMod(lhs.(int32), rhs.(int32))
+	case "<":
+		it.lastValue = lhs.(int32) < rhs.(int32)
+	case "<=":
+		it.lastValue = lhs.(int32) <= rhs.(int32)
+	case ">":
+		it.lastValue = lhs.(int32) > rhs.(int32)
+	case ">=":
+		it.lastValue = lhs.(int32) >= rhs.(int32)
+	case "==":
+		it.lastValue = lhs == rhs
+	case "!=":
+		it.lastValue = lhs != rhs
+	case "is":
+		// Only lists, objects and None can be compared by identity, all of which are pointers or nil
+		it.lastValue = lhs == rhs
+	}
+}
+
+// add adds two integers or concatenates two strings or lists.
+func add(lhs Value, rhs Value) Value {
+	switch lhs := lhs.(type) {
+	case int32:
+		return lhs + rhs.(int32)
+	case string:
+		return lhs + rhs.(string)
+	}
+
+	if lhs == nil || rhs == nil {
+		raise("error_len_none")
+	}
+	elements := append([]Value{}, lhs.(*List).Elements...)
+	elements = append(elements, rhs.(*List).Elements...)
+	return &List{Elements: elements}
+}
+
+// floorDiv divides two integers and rounds the result towards negative infinity like Python does.
+func floorDiv(lhs int32, rhs int32) int32 {
+	if rhs == 0 {
+		raise("error_div_zero")
+	}
+	quotient := lhs / rhs
+	if lhs%rhs != 0 && (lhs < 0) != (rhs < 0) {
+		quotient--
+	}
+	return quotient
+}
+
+// floorMod returns the remainder of floorDiv, which always has the same sign as the divisor.
+func floorMod(lhs int32, rhs int32) int32 {
+	if rhs == 0 {
+		raise("error_div_zero")
+	}
+	remainder := lhs % rhs
+	if remainder != 0 && (remainder < 0) != (rhs < 0) {
+		remainder += rhs
+	}
+	return remainder
+}
+
+func (it *Interpreter) VisitIfExpr(ifExpr *ast.IfExpr) {
+	if it.evaluate(ifExpr.Condition).(bool) {
+		it.lastValue = it.evaluate(ifExpr.IfNode)
+	} else {
+		it.lastValue = it.evaluate(ifExpr.ElseNode)
+	}
+}
+
+func (it *Interpreter) VisitListExpr(listExpr *ast.ListExpr) {
+	elements := []Value{}
+	for _, element := range listExpr.Elements {
+		elements = append(elements, it.evaluate(element))
+	}
+	it.lastValue = &List{Elements: elements}
+}
+
+// VisitCallExpr calls a function, constructs an object of a class or calls one of the builtin functions.
+func (it *Interpreter) VisitCallExpr(callExpr *ast.CallExpr) {
+	arguments := []Value{}
+	for _, argument := range callExpr.Arguments {
+		arguments = append(arguments, it.evaluate(argument))
+	}
+
+	if function := it.lookupFunc(callExpr.FuncName); function != nil {
+		it.lastValue = it.call(function, arguments)
+		return
+	}
+	if class, ok := it.classes[callExpr.FuncName]; ok {
+		it.lastValue = it.newObject(class)
+		return
+	}
+
+	switch callExpr.FuncName {
+	case "print":
+		printValue(it.stdout, arguments[0])
+		it.lastValue = nil
+	case "len":
+		it.lastValue = length(arguments[0])
+	case "input":
+		it.lastValue = it.input()
+	}
+}
+
+// printValue writes the given value to stdout like print() does.
+// Values other than integers, booleans and strings raise an invalid argument error, just like they do in compiled programs.
+func printValue(stdout io.Writer, value Value) {
+	switch value.(type) {
+	case int32, bool, string:
+		fmt.Fprintln(stdout, Format(value))
+	default:
+		raise("error_invalid_argument")
+	}
+}
+
+// length returns the length of a string or list. Any other value raises an invalid argument error.
+func length(value Value) int32 {
+	switch value := value.(type) {
+	case string:
+		return int32(len(value))
+	case *List:
+		return int32(len(value.Elements))
+	case nil:
+		raise("error_len_none")
+	}
+	raise("error_invalid_argument")
+	return 0
+}
+
+// input reads a line from stdin without the trailing newline.
+// An empty string is returned once the end of the input has been reached.
+func (it *Interpreter) input() string {
+	line, _ := it.stdin.ReadString('\n')
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r")
+}
+
+// VisitIndexExpr indexes a list or returns the character of a string at the given index as a new string.
+func (it *Interpreter) VisitIndexExpr(indexExpr *ast.IndexExpr) {
+	value := it.evaluate(indexExpr.Value)
+	index := it.evaluate(indexExpr.Index).(int32)
+
+	switch value := value.(type) {
+	case string:
+		checkIndex(index, len(value))
+		it.lastValue = value[index : index+1]
+	case *List:
+		checkIndex(index, len(value.Elements))
+		it.lastValue = value.Elements[index]
+	case nil:
+		raise("error_index_none")
+	}
+}
+
+func (it *Interpreter) VisitMemberExpr(memberExpr *ast.MemberExpr) {
+	object := it.evaluate(memberExpr.Object)
+	if object == nil {
+		raise("error_member_none")
+	}
+	it.lastValue = object.(*Object).Attributes[memberExpr.MemberName]
+}
+
+// VisitMethodCallExpr dispatches the call to the method of the class of the receiver at runtime,
+// so that methods that are overridden by a subclass are called for objects of the subclass.
+func (it *Interpreter) VisitMethodCallExpr(methodCallExpr *ast.MethodCallExpr) {
+	receiver := it.evaluate(methodCallExpr.Receiver)
+	if receiver == nil {
+		raise("error_member_none")
+	}
+	object := receiver.(*Object)
+
+	arguments := []Value{object}
+	for _, argument := range methodCallExpr.Arguments {
+		arguments = append(arguments, it.evaluate(argument))
+	}
+
+	method := object.Class.method(methodCallExpr.MethodName)
+	it.lastValue = it.call(&Function{def: method, frame: it.globals}, arguments)
+}
+
+// Format returns the text that print() writes for the given value.
+// Lists, objects and None are only formatted for the REPL, since print() raises an invalid argument error for them.
+// Lists are formatted the way Python would format them.
+func Format(value Value) string {
+	switch value := value.(type) {
+	case int32:
+		return fmt.Sprint(value)
+	case bool:
+		if value {
+			return "True"
+		}
+		return "False"
+	case string:
+		return value
+	case *List:
+		elements := []string{}
+		for _, element := range value.Elements {
+			elements = append(elements, Repr(element))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *Object:
+		return fmt.Sprintf("<%s object>", value.Class.Name)
+	}
+	return "None"
+}
+
+// Repr formats the given value like Format does but puts strings in quotes
+// so that they can be told apart from values of other types.
+func Repr(value Value) string {
+	if str, ok := value.(string); ok {
+		return fmt.Sprintf("%q", str)
+	}
+	return Format(value)
+}
@@ -0,0 +1,221 @@
+// Package interp implements a tree-walking interpreter that executes
+// a statically typed AST directly instead of compiling it into LLVM IR.
+//
+// The interpreter follows the semantics of the code generated by the codegen package.
+// Integers are 32 bits wide and wrap around on overflow, and programs fail with
+// the same runtime errors, messages and exit codes as their compiled counterparts.
+package interp
+
+import (
+	"bufio"
+	"chogopy/src/ast"
+	"chogopy/src/codegen"
+	"io"
+)
+
+// Value is a ChocoPy value at runtime. It is either an int32, a bool, a string,
+// a *List, an *Object or nil, which stands for None.
+type Value any
+
+// List is a list value. Lists are shared by reference just like in the compiled code.
+type List struct {
+	Elements []Value
+}
+
+// Object is an instance of a class whose attributes are looked up by name.
+type Object struct {
+	Class      *Class
+	Attributes map[string]Value
+}
+
+type Class struct {
+	Name       string
+	SuperClass *Class
+	// Attributes are the attribute definitions of the class itself, without the inherited ones.
+	Attributes []*ast.VarDef
+	Methods    map[string]*ast.FuncDef
+}
+
+// method looks up the method with the given name in the class and its superclasses.
+func (c *Class) method(name string) *ast.FuncDef {
+	for class := c; class != nil; class = class.SuperClass {
+		if method, ok := class.Methods[name]; ok {
+			return method
+		}
+	}
+	return nil
+}
+
+// Function is a function definition together with the frame that it was defined in.
+// The frame is the static link of the function, through which it accesses the variables of
+// the functions that it is nested in.
+type Function struct {
+	def   *ast.FuncDef
+	frame *Frame
+}
+
+// Frame holds the variables and nested functions of a single function call.
+// Variables are stored in cells so that nonlocal and global declarations can share them with other frames.
+type Frame struct {
+	variables map[string]*Value
+	functions map[string]*Function
+	parent    *Frame
+}
+
+func newFrame(parent *Frame) *Frame {
+	return &Frame{
+		variables: map[string]*Value{},
+		functions: map[string]*Function{},
+		parent:    parent,
+	}
+}
+
+// RuntimeError is an error that the interpreted program raised.
+type RuntimeError struct {
+	codegen.RuntimeError
+}
+
+func (re *RuntimeError) Error() string {
+	return re.Message
+}
+
+// ExitCode returns the exit code that a compiled program terminates with when it raises the error.
+func (re *RuntimeError) ExitCode() int {
+	return int(re.Kind)
+}
+
+// raise aborts the execution of the program with the runtime error of the given name from codegen.RuntimeErrors.
+func raise(errorName string) {
+	panic(&RuntimeError{codegen.RuntimeErrors[errorName]})
+}
+
+type Interpreter struct {
+	stdin  *bufio.Reader
+	stdout io.Writer
+
+	globals *Frame
+	frame   *Frame
+	classes map[string]*Class
+
+	lastValue   Value
+	returnValue Value
+	returning   bool
+	ast.BaseVisitor
+}
+
+// NewInterpreter creates an interpreter whose programs read from stdin when they call input()
+// and write to stdout when they call print().
+func NewInterpreter(stdin io.Reader, stdout io.Writer) *Interpreter {
+	globals := newFrame(nil)
+	return &Interpreter{
+		stdin:   bufio.NewReader(stdin),
+		stdout:  stdout,
+		globals: globals,
+		frame:   globals,
+		classes: map[string]*Class{
+			"object": {Name: "object", Methods: map[string]*ast.FuncDef{}},
+		},
+	}
+}
+
+// Run executes the given program, which has to have been annotated by typechecks.StaticTyping.
+// If the program raises a runtime error, execution stops and a *RuntimeError is returned.
+//
+// The global variables, functions and classes of the program are kept by the interpreter,
+// which means that subsequent calls to Run may refer to the definitions of earlier programs.
+func (it *Interpreter) Run(program *ast.Program) (err error) {
+	defer it.recoverRuntimeError(&err)
+
+	for _, definition := range program.Definitions {
+		definition.Visit(it)
+	}
+	it.execute(program.Statements)
+
+	return nil
+}
+
+// Eval evaluates the given expression in the global scope and returns its value.
+// If the expression raises a runtime error, a *RuntimeError is returned.
+func (it *Interpreter) Eval(expression ast.Node) (value Value, err error) {
+	defer it.recoverRuntimeError(&err)
+
+	return it.evaluate(expression), nil
+}
+
+// recoverRuntimeError stores the runtime error that the program raised in err and resets
+// the interpreter to the global scope so that it can be used to run further programs.
+// Panics that are not caused by a runtime error are propagated.
+func (it *Interpreter) recoverRuntimeError(err *error) {
+	if recovered := recover(); recovered != nil {
+		runtimeError, ok := recovered.(*RuntimeError)
+		if !ok {
+			panic(recovered)
+		}
+		it.frame = it.globals
+		it.returnValue = nil
+		it.returning = false
+		*err = runtimeError
+	}
+}
+
+// Traverse determines whether the Interpreter should traverse further down the AST after a call to any nodes' Visit() method.
+// The interpreter decides on its own when and how often the child nodes of a node are visited.
+func (it *Interpreter) Traverse() bool {
+	return false
+}
+
+// lookup returns the cell of the variable with the given name.
+// Variables that are not defined in the current frame are looked up in the frames of the enclosing functions.
+func (it *Interpreter) lookup(name string) *Value {
+	for frame := it.frame; frame != nil; frame = frame.parent {
+		if cell, ok := frame.variables[name]; ok {
+			return cell
+		}
+	}
+	panic("interp: undefined variable " + name)
+}
+
+// lookupFunc returns the function with the given name or nil if no such function exists.
+func (it *Interpreter) lookupFunc(name string) *Function {
+	for frame := it.frame; frame != nil; frame = frame.parent {
+		if function, ok := frame.functions[name]; ok {
+			return function
+		}
+	}
+	return nil
+}
+
+// evaluate returns the value of the given expression.
+func (it *Interpreter) evaluate(expression ast.Node) Value {
+	expression.Visit(it)
+	return it.lastValue
+}
+
+// execute runs the given statements until one of them returns from the current function.
+func (it *Interpreter) execute(statements []ast.Node) {
+	for _, statement := range statements {
+		statement.Visit(it)
+		if it.returning {
+			return
+		}
+	}
+}
+
+// call calls the given function with the given arguments in a new frame and returns its return value.
+func (it *Interpreter) call(function *Function, arguments []Value) Value {
+	callerFrame := it.frame
+	it.frame = newFrame(function.frame)
+	defer func() { it.frame = callerFrame }()
+
+	for paramIdx, param := range function.def.Parameters {
+		argument := arguments[paramIdx]
+		it.frame.variables[param.(*ast.TypedVar).VarName] = &argument
+	}
+
+	it.execute(function.def.FuncBody)
+
+	returnValue := it.returnValue
+	it.returnValue = nil
+	it.returning = false
+	return returnValue
+}
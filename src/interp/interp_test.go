@@ -0,0 +1,93 @@
+package interp_test
+
+import (
+	"chogopy/src/compiler"
+	"chogopy/src/interp"
+	"strings"
+	"testing"
+)
+
+// interpret checks and runs the given program with the given input and returns what it printed.
+func interpret(t *testing.T, stream string, input string) (string, error) {
+	result, diags := compiler.Compile(stream, compiler.Options{StopAfter: compiler.StageTypes})
+	if len(diags) != 0 {
+		t.Fatalf("Expected a valid program but found %v.", diags)
+	}
+
+	output := strings.Builder{}
+	interpreter := interp.NewInterpreter(strings.NewReader(input), &output)
+	err := interpreter.Run(result.Program)
+	return output.String(), err
+}
+
+func TestInterpretArithmetic(t *testing.T) {
+	stream := `print(-7 // 2)
+print(-7 % 2)
+print(7 % -2)
+print(2147483647 + 1)
+print(-2147483648 - 1)
+print(65536 * 65536)
+print(--2147483648)
+print(-2147483648 // -1)
+print(1 < 2 and not False)
+print("ab" + "c" == "abc")`
+
+	output, err := interpret(t, stream, "")
+
+	expected := "-4\n1\n-1\n-2147483648\n2147483647\n0\n-2147483648\n-2147483648\nTrue\nTrue\n"
+	if err != nil || output != expected {
+		t.Fatalf("Expected %q but found %q (%v).", expected, output, err)
+	}
+}
+
+func TestInterpretFunctionsAndClasses(t *testing.T) {
+	stream := `class A(object):
+    x:int = 1
+    def get(self:"A") -> int:
+        return self.x
+class B(A):
+    def __init__(self:"B"):
+        self.x = 2
+    def get(self:"B") -> int:
+        return self.x * 10
+def counter() -> int:
+    count:int = 0
+    def increment() -> int:
+        nonlocal count
+        count = count + 1
+        return count
+    increment()
+    return increment()
+a:A = None
+a = B()
+print(a.get())
+print(counter())`
+
+	output, err := interpret(t, stream, "")
+
+	expected := "20\n2\n"
+	if err != nil || output != expected {
+		t.Fatalf("Expected %q but found %q (%v).", expected, output, err)
+	}
+}
+
+func TestInterpretListsAndInput(t *testing.T) {
+	stream := `x:[int] = None
+y:[int] = None
+s:str = ""
+i:int = 0
+x = y = [1, 2]
+y[0] = 3
+for i in x + [4]:
+    print(i)
+s = input()
+print(s[1])
+print(len(s))`
+
+	output, err := interpret(t, stream, "hello\n")
+
+	expected := "3\n2\n4\ne\n5\n"
+	if err != nil || output != expected {
+		t.Fatalf("Expected %q but found %q (%v).", expected, output, err)
+	}
+}
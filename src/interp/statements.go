@@ -0,0 +1,112 @@
+package interp
+
+import "chogopy/src/ast"
+
+func (it *Interpreter) VisitIfStmt(ifStmt *ast.IfStmt) {
+	if it.evaluate(ifStmt.Condition).(bool) {
+		it.execute(ifStmt.IfBody)
+	} else {
+		it.execute(ifStmt.ElseBody)
+	}
+}
+
+func (it *Interpreter) VisitWhileStmt(whileStmt *ast.WhileStmt) {
+	for !it.returning && it.evaluate(whileStmt.Condition).(bool) {
+		it.execute(whileStmt.Body)
+	}
+}
+
+// VisitForStmt iterates over the elements of a list or the characters of a string.
+// The length of the iterable is determined once before the first iteration, whereas
+// the elements of a list are read in every iteration so that changes made by the loop body are visible.
+func (it *Interpreter) VisitForStmt(forStmt *ast.ForStmt) {
+	iterCell := it.lookup(forStmt.IterName)
+
+	switch iterable := it.evaluate(forStmt.Iter).(type) {
+	case string:
+		for index := range len(iterable) {
+			*iterCell = iterable[index : index+1]
+			it.execute(forStmt.Body)
+			if it.returning {
+				return
+			}
+		}
+	case *List:
+		iterLen := len(iterable.Elements)
+		for index := range iterLen {
+			*iterCell = iterable.Elements[index]
+			it.execute(forStmt.Body)
+			if it.returning {
+				return
+			}
+		}
+	case nil:
+		raise("error_len_none")
+	}
+}
+
+func (it *Interpreter) VisitPassStmt(passStmt *ast.PassStmt) {
+}
+
+func (it *Interpreter) VisitReturnStmt(returnStmt *ast.ReturnStmt) {
+	var returnValue Value
+	if returnStmt.ReturnVal != nil {
+		returnValue = it.evaluate(returnStmt.ReturnVal)
+	}
+	it.returnValue = returnValue
+	it.returning = true
+}
+
+// VisitAssignStmt evaluates the value of an assignment once and then assigns it to every target from left to right.
+// The targets of a multiple assignment such as a = b = 1 are parsed as nested assignments.
+func (it *Interpreter) VisitAssignStmt(assignStmt *ast.AssignStmt) {
+	targets := []ast.Node{assignStmt.Target}
+	value := assignStmt.Value
+	for {
+		nestedAssign, ok := value.(*ast.AssignStmt)
+		if !ok {
+			break
+		}
+		targets = append(targets, nestedAssign.Target)
+		value = nestedAssign.Value
+	}
+
+	assignValue := it.evaluate(value)
+	for _, target := range targets {
+		it.assign(target, assignValue)
+	}
+}
+
+func (it *Interpreter) assign(target ast.Node, value Value) {
+	switch target := target.(type) {
+	case *ast.IdentExpr:
+		*it.lookup(target.Identifier) = value
+
+	case *ast.IndexExpr:
+		list := it.evaluate(target.Value)
+		index := it.evaluate(target.Index).(int32)
+		if list == nil {
+			raise("error_index_none")
+		}
+		elements := list.(*List).Elements
+		checkIndex(index, len(elements))
+		elements[index] = value
+
+	case *ast.MemberExpr:
+		object := it.evaluate(target.Object)
+		if object == nil {
+			raise("error_member_none")
+		}
+		object.(*Object).Attributes[target.MemberName] = value
+	}
+}
+
+// checkIndex raises an error if the given index is out of the bounds of a list or string with the given length.
+func checkIndex(index int32, length int) {
+	if index < 0 {
+		raise("error_index_neg")
+	}
+	if int(index) >= length {
+		raise("error_index_oob")
+	}
+}
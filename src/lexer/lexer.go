@@ -1,30 +1,114 @@
 package lexer
 
 import (
-	"errors"
-	"log"
+	"chogopy/src/diagnostics"
+	"fmt"
 	"slices"
 	"strconv"
+	"unicode/utf8"
 )
 
-var (
-	tabSpaces = 8
-	spaces    = []string{"\t", "\r", "\n", " "}
-	symbols   = []string{"+", "-", "*", "%", "/", "=", "!", "<", ">", "(", ")", ":", "[", "]", ","}
-	numbers   = []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}
-	letters   = []string{
-		"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l", "m", "n", "o", "p", "q", "r", "s", "t", "u", "v", "w", "x", "y", "z",
-		"A", "B", "C", "D", "E", "J", "F", "G", "H", "I", "J", "K", "L", "M", "N", "O", "P", "Q", "R", "S", "T", "U", "V", "W", "X", "Y", "Z",
-		"_",
+var tabSpaces = 8
+
+// MaxIntegerLiteral is the largest integer literal. Integers are 32 bits wide,
+// but 2147483648 is still a valid literal so that the smallest integer can be written as -2147483648.
+// The parser makes sure that it only occurs as the operand of a unary minus.
+const MaxIntegerLiteral = 1 << 31
+
+// charClass determines which kind of token a character can start.
+type charClass uint8
+
+const (
+	invalidChar charClass = iota
+	spaceChar
+	symbolChar
+	digitChar
+	letterChar
+)
+
+// charClasses holds the class of every ASCII character.
+// All other characters are only allowed within comments.
+var charClasses = func() [utf8.RuneSelf]charClass {
+	classes := [utf8.RuneSelf]charClass{}
+	for _, char := range "\t\r\n " {
+		classes[char] = spaceChar
+	}
+	for _, char := range "+-*%/=!<>():[],." {
+		classes[char] = symbolChar
+	}
+	for char := '0'; char <= '9'; char++ {
+		classes[char] = digitChar
 	}
+	for char := 'a'; char <= 'z'; char++ {
+		classes[char] = letterChar
+		classes[char-'a'+'A'] = letterChar
+	}
+	classes['_'] = letterChar
+	return classes
+}()
+
+func classOf(char rune) charClass {
+	if char < 0 || char >= utf8.RuneSelf {
+		return invalidChar
+	}
+	return charClasses[char]
+}
+
+var keywords = map[string]TokenKind{
+	"class":    CLASS,
+	"def":      DEF,
+	"global":   GLOBAL,
+	"nonlocal": NONLOCAL,
+	"if":       IF,
+	"elif":     ELIF,
+	"else":     ELSE,
+	"while":    WHILE,
+	"for":      FOR,
+	"in":       IN,
+	"None":     NONE,
+	"True":     TRUE,
+	"False":    FALSE,
+	"pass":     PASS,
+	"or":       OR,
+	"and":      AND,
+	"not":      NOT,
+	"is":       IS,
+	"object":   OBJECT,
+	"int":      INT,
+	"bool":     BOOL,
+	"str":      STR,
+	"return":   RETURN,
+}
+
+// The codes of the lexical errors.
+const (
+	InvalidCharacter      = "E001"
+	UnknownOperator       = "E002"
+	UnknownEscapeSequence = "E003"
+	InvalidIntegerLiteral = "E004"
+	UnterminatedString    = "E005"
+	InconsistentDedent    = "E006"
 )
 
+// Error is a lexical error that spans the characters from Offset up to End.
+// The lexer recovers from every lexical error and keeps emitting tokens after it.
+type Error struct {
+	Code    string
+	Message string
+	Offset  int
+	End     int
+}
+
 type Lexer struct {
 	scanner     Scanner
 	tokenBuffer []Token
 	isNewLine   bool
 	indentLevel int
 	indentStack []int
+	// bracketDepth is the number of round and square brackets that are currently open.
+	// Lines are implicitly joined inside of brackets, so line breaks and indentation are ignored there.
+	bracketDepth int
+	errors       []Error
 }
 
 func NewLexer(stream string) Lexer {
@@ -35,42 +119,55 @@ func NewLexer(stream string) Lexer {
 		isNewLine:   true,
 		indentLevel: 0,
 		indentStack: []int{0},
+		errors:      []Error{},
+	}
+}
+
+// Errors returns the lexical errors in the part of the input that has been lexed so far in the order of their offsets.
+func (l *Lexer) Errors() []Error {
+	return l.errors
+}
+
+// Diagnostic converts the given lexical error into a diagnostic that is located in the source code.
+func (l *Lexer) Diagnostic(lexicalError Error) diagnostics.Diagnostic {
+	return diagnostics.Diagnostic{
+		Severity: diagnostics.Error,
+		Code:     lexicalError.Code,
+		Message:  lexicalError.Message,
+		Span: diagnostics.Span{
+			Start: l.position(lexicalError.Offset),
+			End:   l.position(lexicalError.End),
+		},
 	}
 }
 
+func (l *Lexer) position(offset int) diagnostics.Position {
+	line, column := l.scanner.location(offset)
+	return diagnostics.Position{Offset: offset, Line: line, Column: column}
+}
+
+func (l *Lexer) error(code string, offset int, end int, format string, args ...any) {
+	l.errors = append(l.errors, Error{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+		Offset:  offset,
+		End:     end,
+	})
+}
+
+// LocationInfo describes where in the source code a token is located.
+// LineLiteral is the text of the whole line that contains the token.
 type LocationInfo struct {
 	Line        int
 	Column      int
 	LineLiteral string
 }
 
+// GetLocation looks up the line and column of the given token in the table of line starts that the scanner builds.
+// The token only needs to have its offset set, and the offset has to be within the part of the input that has been lexed.
 func (l *Lexer) GetLocation(token *Token) *LocationInfo {
-	line := 1
-	column := 0
-	lineLiteral := ""
-
-	for charIndex, char := range l.scanner.streamLookup {
-		if charIndex == token.Offset {
-			column++
-			break
-		}
-		if charIndex > token.Offset && char == '\n' {
-			break
-		}
-
-		if charIndex > token.Offset {
-			lineLiteral += string(char)
-		} else if char == '\n' {
-			line++
-			column = 0
-			lineLiteral = ""
-		} else {
-			column++
-			lineLiteral += string(char)
-		}
-	}
-
-	return &LocationInfo{line, column, lineLiteral}
+	line, column := l.scanner.location(token.Offset)
+	return &LocationInfo{line, column, l.scanner.lineText(line)}
 }
 
 func (l *Lexer) Peek(tokenAmount int) []Token {
@@ -95,14 +192,25 @@ func (l *Lexer) Consume(keepBuffer bool) Token {
 		return token
 	}
 
-	nextChar := l.scanner.Peek()
+	token := l.scan()
+	token.End = l.scanner.offset
+	token.Line, token.Column = l.scanner.location(token.Offset)
+	return token
+}
+
+// scan lexes the next token from the input.
+func (l *Lexer) scan() Token {
 	for {
-		if slices.Contains(spaces, nextChar) {
-			return l.handleSpaces(nextChar, keepBuffer)
-		} else if nextChar == "#" {
-			l.handleComment(nextChar)
-			return l.Consume(keepBuffer)
-		} else if nextChar != "" && l.isNewLine {
+		nextChar := l.scanner.Peek()
+		nextClass := classOf(nextChar)
+
+		if nextClass == spaceChar {
+			if token, ok := l.handleSpace(nextChar); ok {
+				return token
+			}
+		} else if nextChar == '#' {
+			l.handleComment()
+		} else if nextChar != endOfInput && l.isNewLine {
 			if l.indentLevel > l.indentStack[len(l.indentStack)-1] {
 				return l.handleIndent()
 			} else if l.indentLevel < l.indentStack[len(l.indentStack)-1] {
@@ -112,38 +220,45 @@ func (l *Lexer) Consume(keepBuffer bool) Token {
 			// which is not a space or a comment (already handled in the previous two cases)
 			// AND after we have emitted all the necessary indent/dedent tokens
 			l.isNewLine = false
-		} else if slices.Contains(symbols, nextChar) {
+		} else if nextClass == symbolChar {
 			return l.handleSymbols(nextChar)
-		} else if slices.Contains(letters, nextChar) {
-			return l.handleName(nextChar)
-		} else if slices.Contains(numbers, nextChar) {
-			return l.handleIntegerLiteral(nextChar)
-		} else if nextChar == string('"') {
+		} else if nextClass == letterChar {
+			return l.handleName()
+		} else if nextClass == digitChar {
+			return l.handleIntegerLiteral()
+		} else if nextChar == '"' {
 			return l.handleStringLiteral()
-		} else if nextChar == "" {
+		} else if nextChar == endOfInput {
 			return l.handleEndOfFile()
+		} else if nextChar == '\\' {
+			l.handleLineContinuation()
 		} else {
-			log.Fatal(errors.New("invalid symbol in input"))
+			// The invalid character is skipped as if it was not there
+			offset := l.scanner.offset
+			l.scanner.Consume()
+			l.error(InvalidCharacter, offset, l.scanner.offset, "Invalid character %q.", nextChar)
 		}
 	}
 }
 
-func (l *Lexer) handleSpaces(nextChar string, keepBuffer bool) Token {
+// handleSpace consumes a single space character. It only returns a token if the character ends a line.
+func (l *Lexer) handleSpace(nextChar rune) (Token, bool) {
 	switch nextChar {
-	case "\n", "\r":
+	case '\n', '\r':
 		// We only want to emit a newline token after a regular line has ended
 		// This prevents emitting multiple newline tokens for a series of newlines and instead only emits a single newline for them
-		if !l.isNewLine {
+		// Inside of brackets, the line continues on the next line
+		if !l.isNewLine && l.bracketDepth == 0 {
 			l.isNewLine = true
 			l.indentLevel = 0
 			l.scanner.Consume()
-			return Token{NEWLINE, nil, l.scanner.offset - 1}
+			return Token{Kind: NEWLINE, Offset: l.scanner.offset - 1}, true
 		}
-	case " ":
+	case ' ':
 		if l.isNewLine {
 			l.indentLevel += 1
 		}
-	case "\t":
+	case '\t':
 		if l.isNewLine {
 			// The reason we are subtracing (indentLevel mod tabSpaces) is to end up with proper indentation
 			// if for example the source text has been indented via '   \t' or ' \t' (both will lead to 8 spaces)
@@ -152,209 +267,206 @@ func (l *Lexer) handleSpaces(nextChar string, keepBuffer bool) Token {
 		}
 	}
 	l.scanner.Consume()
-	return l.Consume(keepBuffer)
+	return Token{}, false
 }
 
-func (l *Lexer) handleComment(nextChar string) {
-	for nextChar != "" && nextChar != "\n" && nextChar != "\r" {
+func (l *Lexer) handleComment() {
+	for nextChar := l.scanner.Peek(); nextChar != endOfInput && nextChar != '\n' && nextChar != '\r'; nextChar = l.scanner.Peek() {
 		l.scanner.Consume()
-		nextChar = l.scanner.Peek()
 	}
 	l.indentLevel = 0
 }
 
+// handleLineContinuation consumes a backslash that explicitly joins the current line with the next one.
+// The backslash has to be the last character of the line.
+func (l *Lexer) handleLineContinuation() {
+	offset := l.scanner.offset
+	l.scanner.Consume()
+	switch l.scanner.Peek() {
+	case '\n':
+		l.scanner.Consume()
+	case '\r':
+		l.scanner.Consume()
+		if l.scanner.Peek() == '\n' {
+			l.scanner.Consume()
+		}
+	default:
+		l.error(InvalidCharacter, offset, l.scanner.offset, "Unexpected character after line continuation character.")
+	}
+}
+
 func (l *Lexer) handleIndent() Token {
 	l.indentStack = append(l.indentStack, l.indentLevel)
 	indentTokenSize := l.indentStack[len(l.indentStack)-1] - l.indentStack[len(l.indentStack)-2]
 	_ = indentTokenSize
-	return Token{INDENT, nil, l.scanner.offset}
+	return Token{Kind: INDENT, Offset: l.scanner.offset}
 }
 
 func (l *Lexer) handleDedent() Token {
 	// if the current indentation doesn't match any of the previous ones -> mismatch
+	// The line is then treated as if it was indented like the innermost block that it is part of
 	if !slices.Contains(l.indentStack, l.indentLevel) {
-		log.Fatal(errors.New("indentation: mismatched blocks"))
+		l.error(InconsistentDedent, l.scanner.offset, l.scanner.offset, "Unindent does not match any outer indentation level.")
+		for _, indentLevel := range l.indentStack {
+			if indentLevel < l.indentLevel {
+				l.indentLevel = indentLevel
+			}
+		}
 	}
 	l.indentStack = l.indentStack[:len(l.indentStack)-1]
-	return Token{DEDENT, nil, l.scanner.offset}
+	return Token{Kind: DEDENT, Offset: l.scanner.offset}
 }
 
-func (l *Lexer) handleSymbols(nextChar string) Token {
+func (l *Lexer) handleSymbols(nextChar rune) Token {
 	switch nextChar {
-	case "+":
+	case '+':
 		l.scanner.Consume()
-		return Token{PLUS, "+", l.scanner.offset - 1}
-	case "-":
+		return Token{Kind: PLUS, Value: "+", Offset: l.scanner.offset - 1}
+	case '-':
 		l.scanner.Consume()
-		if l.scanner.Peek() == ">" {
+		if l.scanner.Peek() == '>' {
 			l.scanner.Consume()
-			return Token{RARROW, "->", l.scanner.offset - 2}
+			return Token{Kind: RARROW, Value: "->", Offset: l.scanner.offset - 2}
 		}
-		return Token{MINUS, "-", l.scanner.offset - 1}
-	case "*":
+		return Token{Kind: MINUS, Value: "-", Offset: l.scanner.offset - 1}
+	case '*':
 		l.scanner.Consume()
-		return Token{MUL, "*", l.scanner.offset - 1}
-	case "%":
+		return Token{Kind: MUL, Value: "*", Offset: l.scanner.offset - 1}
+	case '%':
 		l.scanner.Consume()
-		return Token{MOD, "%", l.scanner.offset - 1}
-	case "/":
+		return Token{Kind: MOD, Value: "%", Offset: l.scanner.offset - 1}
+	case '/':
 		l.scanner.Consume()
-		if l.scanner.Peek() == "/" {
+		if l.scanner.Peek() == '/' {
 			l.scanner.Consume()
-			return Token{DIV, "//", l.scanner.offset - 2}
+			return Token{Kind: DIV, Value: "//", Offset: l.scanner.offset - 2}
 		}
-		log.Fatal(errors.New("unknown symbol: '/'"))
-	case "=":
+		l.error(UnknownOperator, l.scanner.offset-1, l.scanner.offset, "Unknown operator '/', use '//' for integer division.")
+		return Token{Kind: DIV, Value: "//", Offset: l.scanner.offset - 1}
+	case '=':
 		l.scanner.Consume()
-		if l.scanner.Peek() == "=" {
+		if l.scanner.Peek() == '=' {
 			l.scanner.Consume()
-			return Token{EQ, "==", l.scanner.offset - 2}
+			return Token{Kind: EQ, Value: "==", Offset: l.scanner.offset - 2}
 		}
-		return Token{ASSIGN, "=", l.scanner.offset - 1}
-	case "!":
+		return Token{Kind: ASSIGN, Value: "=", Offset: l.scanner.offset - 1}
+	case '!':
 		l.scanner.Consume()
-		if l.scanner.Peek() == "=" {
+		if l.scanner.Peek() == '=' {
 			l.scanner.Consume()
-			return Token{NE, "!=", l.scanner.offset - 2}
+			return Token{Kind: NE, Value: "!=", Offset: l.scanner.offset - 2}
 		}
-		log.Fatal(errors.New("unknown symbol: '!'"))
-	case "<":
+		l.error(UnknownOperator, l.scanner.offset-1, l.scanner.offset, "Unknown operator '!', use 'not' for negation or '!=' for inequality.")
+		return Token{Kind: NOT, Value: "not", Offset: l.scanner.offset - 1}
+	case '<':
 		l.scanner.Consume()
-		if l.scanner.Peek() == "=" {
+		if l.scanner.Peek() == '=' {
 			l.scanner.Consume()
-			return Token{LE, "<=", l.scanner.offset - 2}
+			return Token{Kind: LE, Value: "<=", Offset: l.scanner.offset - 2}
 		}
-		return Token{LT, "<", l.scanner.offset - 1}
-	case ">":
+		return Token{Kind: LT, Value: "<", Offset: l.scanner.offset - 1}
+	case '>':
 		l.scanner.Consume()
-		if l.scanner.Peek() == "=" {
+		if l.scanner.Peek() == '=' {
 			l.scanner.Consume()
-			return Token{GE, ">=", l.scanner.offset - 2}
+			return Token{Kind: GE, Value: ">=", Offset: l.scanner.offset - 2}
 		}
-		return Token{GT, ">", l.scanner.offset - 1}
-	case "(":
+		return Token{Kind: GT, Value: ">", Offset: l.scanner.offset - 1}
+	case '(':
+		l.scanner.Consume()
+		l.bracketDepth++
+		return Token{Kind: LROUNDBRACKET, Value: "(", Offset: l.scanner.offset - 1}
+	case ')':
 		l.scanner.Consume()
-		return Token{LROUNDBRACKET, "(", l.scanner.offset - 1}
-	case ")":
+		// Unmatched closing brackets are reported by the parser
+		l.bracketDepth = max(l.bracketDepth-1, 0)
+		return Token{Kind: RROUNDBRACKET, Value: ")", Offset: l.scanner.offset - 1}
+	case ':':
 		l.scanner.Consume()
-		return Token{RROUNDBRACKET, ")", l.scanner.offset - 1}
-	case ":":
+		return Token{Kind: COLON, Value: ":", Offset: l.scanner.offset - 1}
+	case '[':
 		l.scanner.Consume()
-		return Token{COLON, ":", l.scanner.offset - 1}
-	case "[":
+		l.bracketDepth++
+		return Token{Kind: LSQUAREBRACKET, Value: "[", Offset: l.scanner.offset - 1}
+	case ']':
 		l.scanner.Consume()
-		return Token{LSQUAREBRACKET, "[", l.scanner.offset - 1}
-	case "]":
+		// Unmatched closing brackets are reported by the parser
+		l.bracketDepth = max(l.bracketDepth-1, 0)
+		return Token{Kind: RSQUAREBRACKET, Value: "]", Offset: l.scanner.offset - 1}
+	case ',':
 		l.scanner.Consume()
-		return Token{RSQUAREBRACKET, "]", l.scanner.offset - 1}
-	case ",":
+		return Token{Kind: COMMA, Value: ",", Offset: l.scanner.offset - 1}
+	case '.':
 		l.scanner.Consume()
-		return Token{COMMA, ",", l.scanner.offset - 1}
+		return Token{Kind: DOT, Value: ".", Offset: l.scanner.offset - 1}
 	}
 	return Token{}
 }
 
-func (l *Lexer) handleName(nextChar string) Token {
-	name := ""
-	for slices.Contains(letters, nextChar) || slices.Contains(numbers, nextChar) {
-		name += nextChar
+func (l *Lexer) handleName() Token {
+	start := l.scanner.offset
+	for nextClass := classOf(l.scanner.Peek()); nextClass == letterChar || nextClass == digitChar; nextClass = classOf(l.scanner.Peek()) {
 		l.scanner.Consume()
-		nextChar = l.scanner.Peek()
 	}
 
-	switch name {
-	case "class":
-		return Token{CLASS, name, l.scanner.offset - len(name)}
-	case "def":
-		return Token{DEF, name, l.scanner.offset - len(name)}
-	case "global":
-		return Token{GLOBAL, name, l.scanner.offset - len(name)}
-	case "nonlocal":
-		return Token{NONLOCAL, name, l.scanner.offset - len(name)}
-	case "if":
-		return Token{IF, name, l.scanner.offset - len(name)}
-	case "elif":
-		return Token{ELIF, name, l.scanner.offset - len(name)}
-	case "else":
-		return Token{ELSE, name, l.scanner.offset - len(name)}
-	case "while":
-		return Token{WHILE, name, l.scanner.offset - len(name)}
-	case "for":
-		return Token{FOR, name, l.scanner.offset - len(name)}
-	case "in":
-		return Token{IN, name, l.scanner.offset - len(name)}
-	case "None":
-		return Token{NONE, name, l.scanner.offset - len(name)}
-	case "True":
-		return Token{TRUE, name, l.scanner.offset - len(name)}
-	case "False":
-		return Token{FALSE, name, l.scanner.offset - len(name)}
-	case "pass":
-		return Token{PASS, name, l.scanner.offset - len(name)}
-	case "or":
-		return Token{OR, name, l.scanner.offset - len(name)}
-	case "and":
-		return Token{AND, name, l.scanner.offset - len(name)}
-	case "not":
-		return Token{NOT, name, l.scanner.offset - len(name)}
-	case "is":
-		return Token{IS, name, l.scanner.offset - len(name)}
-	case "object":
-		return Token{OBJECT, name, l.scanner.offset - len(name)}
-	case "int":
-		return Token{INT, name, l.scanner.offset - len(name)}
-	case "bool":
-		return Token{BOOL, name, l.scanner.offset - len(name)}
-	case "str":
-		return Token{STR, name, l.scanner.offset - len(name)}
-	case "return":
-		return Token{RETURN, name, l.scanner.offset - len(name)}
+	name := l.scanner.text(start)
+	if kind, ok := keywords[name]; ok {
+		return Token{Kind: kind, Value: name, Offset: start}
 	}
-
-	return Token{IDENTIFIER, name, l.scanner.offset - len(name)}
+	return Token{Kind: IDENTIFIER, Value: name, Offset: start}
 }
 
-func (l *Lexer) handleIntegerLiteral(nextChar string) Token {
-	value := ""
-	for slices.Contains(numbers, nextChar) {
-		value += nextChar
+func (l *Lexer) handleIntegerLiteral() Token {
+	start := l.scanner.offset
+	for classOf(l.scanner.Peek()) == digitChar {
 		l.scanner.Consume()
-		nextChar = l.scanner.Peek()
 	}
 
-	valueInt, err := strconv.Atoi(value)
-	if err != nil {
-		log.Fatal(errors.New("failed to convert integer literal"))
+	valueInt, err := strconv.Atoi(l.scanner.text(start))
+	if err != nil || valueInt > MaxIntegerLiteral {
+		l.error(InvalidIntegerLiteral, start, l.scanner.offset, "Integer literal %s is out of range.", l.scanner.text(start))
+		valueInt = 0
 	}
 
-	return Token{INTEGER, valueInt, l.scanner.offset - len(value)}
+	return Token{Kind: INTEGER, Value: valueInt, Offset: start}
 }
 
+// handleStringLiteral lexes a string literal. Its value is the text between the quotes,
+// which still contains the escape sequences. String literals cannot span multiple lines,
+// so an unterminated string literal ends at the end of its line.
 func (l *Lexer) handleStringLiteral() Token {
-	value := ""
+	start := l.scanner.offset
 	l.scanner.Consume()
 	nextChar := l.scanner.Peek()
 
-	allowedEscapedChars := []string{"t", "n", "\\", string('"')}
-	for nextChar != string('"') {
-		if nextChar == "\\" {
-			value += nextChar
+	for nextChar != '"' {
+		if nextChar == endOfInput || nextChar == '\n' || nextChar == '\r' {
+			l.error(UnterminatedString, start, l.scanner.offset, "Unterminated string literal.")
+			// The closing brackets were most likely swallowed by the string literal,
+			// so the line ends here instead of being joined with the following lines
+			l.bracketDepth = 0
+			return Token{Kind: STRING, Value: l.scanner.text(start + 1), Offset: start}
+		}
+		if nextChar == '\\' {
+			escapeStart := l.scanner.offset
 			l.scanner.Consume()
 			nextChar = l.scanner.Peek()
-			if !slices.Contains(allowedEscapedChars, nextChar) {
-				log.Fatal(errors.New("unknown escape sequence"))
+			switch nextChar {
+			case 't', 'n', '\\', '"':
+			case endOfInput, '\n', '\r':
+				continue
+			default:
+				l.error(UnknownEscapeSequence, escapeStart, l.scanner.offset+utf8.RuneLen(nextChar), "Unknown escape sequence '\\%c'.", nextChar)
 			}
 		}
-		value += nextChar
 		l.scanner.Consume()
 		nextChar = l.scanner.Peek()
 	}
 
-	// consume the closing " without adding it to the value
-	// and adjust the offset for the length of the surrounding "" that are not part of the value
+	// consume the closing " which is not part of the value
 	l.scanner.Consume()
 
-	return Token{STRING, value, l.scanner.offset - len(value) - 2}
+	return Token{Kind: STRING, Value: l.scanner.source[start+1 : l.scanner.offset-1], Offset: start}
 }
 
 func (l *Lexer) handleEndOfFile() Token {
@@ -363,14 +475,14 @@ func (l *Lexer) handleEndOfFile() Token {
 		l.isNewLine = true
 		// act as if we had consumed a newline token in the scanner to keep the offsets consistent
 		l.scanner.offset += 1
-		return Token{NEWLINE, nil, l.scanner.offset - 1}
+		return Token{Kind: NEWLINE, Offset: l.scanner.offset - 1}
 	}
 	// emit a dedent token for all remaining indentation levels
 	if l.indentStack[len(l.indentStack)-1] > 0 {
 		dedentTokenSize := l.indentStack[len(l.indentStack)-1] - l.indentStack[len(l.indentStack)-2]
 		_ = dedentTokenSize
 		l.indentStack = l.indentStack[:len(l.indentStack)-1]
-		return Token{DEDENT, nil, l.scanner.offset}
+		return Token{Kind: DEDENT, Offset: l.scanner.offset}
 	}
-	return Token{EOF, nil, l.scanner.offset}
+	return Token{Kind: EOF, Offset: l.scanner.offset}
 }
@@ -1,6 +1,7 @@
 package lexer
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -9,19 +10,19 @@ func TestArithmetic(t *testing.T) {
 	1 + 2`
 
 	expectedTokenList := []Token{
-		{DEF, "def", 0},
-		{IDENTIFIER, "foo", 4},
-		{LROUNDBRACKET, "(", 7},
-		{RROUNDBRACKET, ")", 8},
-		{COLON, ":", 9},
-		{NEWLINE, nil, 10},
-		{INDENT, nil, 12},
-		{INTEGER, 1, 12},
-		{PLUS, "+", 14},
-		{INTEGER, 2, 16},
-		{NEWLINE, nil, 17},
-		{DEDENT, nil, 18},
-		{EOF, nil, 18},
+		{Kind: DEF, Value: "def", Offset: 0},
+		{Kind: IDENTIFIER, Value: "foo", Offset: 4},
+		{Kind: LROUNDBRACKET, Value: "(", Offset: 7},
+		{Kind: RROUNDBRACKET, Value: ")", Offset: 8},
+		{Kind: COLON, Value: ":", Offset: 9},
+		{Kind: NEWLINE, Value: nil, Offset: 10},
+		{Kind: INDENT, Value: nil, Offset: 12},
+		{Kind: INTEGER, Value: 1, Offset: 12},
+		{Kind: PLUS, Value: "+", Offset: 14},
+		{Kind: INTEGER, Value: 2, Offset: 16},
+		{Kind: NEWLINE, Value: nil, Offset: 17},
+		{Kind: DEDENT, Value: nil, Offset: 18},
+		{Kind: EOF, Value: nil, Offset: 18},
 	}
 
 	lexer := NewLexer(stream)
@@ -42,19 +43,19 @@ def foo():
 	[0]`
 
 	expectedTokenList := []Token{
-		{DEF, "def", 1},
-		{IDENTIFIER, "foo", 5},
-		{LROUNDBRACKET, "(", 8},
-		{RROUNDBRACKET, ")", 9},
-		{COLON, ":", 10},
-		{NEWLINE, nil, 11},
-		{INDENT, nil, 15},
-		{LSQUAREBRACKET, "[", 15},
-		{INTEGER, 0, 16},
-		{RSQUAREBRACKET, "]", 17},
-		{NEWLINE, nil, 18},
-		{DEDENT, nil, 19},
-		{EOF, nil, 19},
+		{Kind: DEF, Value: "def", Offset: 1},
+		{Kind: IDENTIFIER, Value: "foo", Offset: 5},
+		{Kind: LROUNDBRACKET, Value: "(", Offset: 8},
+		{Kind: RROUNDBRACKET, Value: ")", Offset: 9},
+		{Kind: COLON, Value: ":", Offset: 10},
+		{Kind: NEWLINE, Value: nil, Offset: 11},
+		{Kind: INDENT, Value: nil, Offset: 15},
+		{Kind: LSQUAREBRACKET, Value: "[", Offset: 15},
+		{Kind: INTEGER, Value: 0, Offset: 16},
+		{Kind: RSQUAREBRACKET, Value: "]", Offset: 17},
+		{Kind: NEWLINE, Value: nil, Offset: 18},
+		{Kind: DEDENT, Value: nil, Offset: 19},
+		{Kind: EOF, Value: nil, Offset: 19},
 	}
 
 	lexer := NewLexer(stream)
@@ -71,11 +72,35 @@ func TestDivision(t *testing.T) {
 	stream := "0 // 1"
 
 	expectedTokenList := []Token{
-		{INTEGER, 0, 0},
-		{DIV, "//", 2},
-		{INTEGER, 1, 5},
-		{NEWLINE, nil, 6},
-		{EOF, nil, 7},
+		{Kind: INTEGER, Value: 0, Offset: 0},
+		{Kind: DIV, Value: "//", Offset: 2},
+		{Kind: INTEGER, Value: 1, Offset: 5},
+		{Kind: NEWLINE, Value: nil, Offset: 6},
+		{Kind: EOF, Value: nil, Offset: 7},
+	}
+
+	lexer := NewLexer(stream)
+
+	for _, expectedToken := range expectedTokenList {
+		token := lexer.Consume(false)
+		if token.Kind != expectedToken.Kind || token.Value != expectedToken.Value || token.Offset != expectedToken.Offset {
+			t.Fatalf("expected: %v (%v) got: %v (%v)", expectedToken.Kind.String(), expectedToken, token.Kind.String(), token)
+		}
+	}
+}
+
+func TestDot(t *testing.T) {
+	stream := "a.b(1)"
+
+	expectedTokenList := []Token{
+		{Kind: IDENTIFIER, Value: "a", Offset: 0},
+		{Kind: DOT, Value: ".", Offset: 1},
+		{Kind: IDENTIFIER, Value: "b", Offset: 2},
+		{Kind: LROUNDBRACKET, Value: "(", Offset: 3},
+		{Kind: INTEGER, Value: 1, Offset: 4},
+		{Kind: RROUNDBRACKET, Value: ")", Offset: 5},
+		{Kind: NEWLINE, Value: nil, Offset: 6},
+		{Kind: EOF, Value: nil, Offset: 7},
 	}
 
 	lexer := NewLexer(stream)
@@ -95,17 +120,17 @@ def foo():
 `
 
 	expectedTokenList := []Token{
-		{DEF, "def", 1},
-		{IDENTIFIER, "foo", 5},
-		{LROUNDBRACKET, "(", 8},
-		{RROUNDBRACKET, ")", 9},
-		{COLON, ":", 10},
-		{NEWLINE, nil, 11},
-		{INDENT, nil, 13},
-		{INTEGER, 0, 13},
-		{NEWLINE, nil, 37},
-		{DEDENT, nil, 38},
-		{EOF, nil, 38},
+		{Kind: DEF, Value: "def", Offset: 1},
+		{Kind: IDENTIFIER, Value: "foo", Offset: 5},
+		{Kind: LROUNDBRACKET, Value: "(", Offset: 8},
+		{Kind: RROUNDBRACKET, Value: ")", Offset: 9},
+		{Kind: COLON, Value: ":", Offset: 10},
+		{Kind: NEWLINE, Value: nil, Offset: 11},
+		{Kind: INDENT, Value: nil, Offset: 13},
+		{Kind: INTEGER, Value: 0, Offset: 13},
+		{Kind: NEWLINE, Value: nil, Offset: 37},
+		{Kind: DEDENT, Value: nil, Offset: 38},
+		{Kind: EOF, Value: nil, Offset: 38},
 	}
 
 	lexer := NewLexer(stream)
@@ -131,35 +156,35 @@ pass
 `
 
 	expectedTokenList := []Token{
-		{DEF, "def", 1},
-		{IDENTIFIER, "foo", 5},
-		{LROUNDBRACKET, "(", 8},
-		{RROUNDBRACKET, ")", 9},
-		{COLON, ":", 10},
-		{NEWLINE, nil, 11},
-		{INDENT, nil, 13},
-		{IF, "if", 13},
-		{TRUE, "True", 16},
-		{COLON, ":", 20},
-		{NEWLINE, nil, 21},
-		{INDENT, nil, 24},
-		{RETURN, "return", 24},
-		{NEWLINE, nil, 30},
-		{DEDENT, nil, 32},
-		{DEDENT, nil, 32},
-		{DEF, "def", 32},
-		{IDENTIFIER, "bar", 36},
-		{LROUNDBRACKET, "(", 39},
-		{RROUNDBRACKET, ")", 40},
-		{COLON, ":", 41},
-		{NEWLINE, nil, 42},
-		{INDENT, nil, 44},
-		{RETURN, "return", 44},
-		{NEWLINE, nil, 50},
-		{DEDENT, nil, 52},
-		{PASS, "pass", 52},
-		{NEWLINE, nil, 56},
-		{EOF, nil, 57},
+		{Kind: DEF, Value: "def", Offset: 1},
+		{Kind: IDENTIFIER, Value: "foo", Offset: 5},
+		{Kind: LROUNDBRACKET, Value: "(", Offset: 8},
+		{Kind: RROUNDBRACKET, Value: ")", Offset: 9},
+		{Kind: COLON, Value: ":", Offset: 10},
+		{Kind: NEWLINE, Value: nil, Offset: 11},
+		{Kind: INDENT, Value: nil, Offset: 13},
+		{Kind: IF, Value: "if", Offset: 13},
+		{Kind: TRUE, Value: "True", Offset: 16},
+		{Kind: COLON, Value: ":", Offset: 20},
+		{Kind: NEWLINE, Value: nil, Offset: 21},
+		{Kind: INDENT, Value: nil, Offset: 24},
+		{Kind: RETURN, Value: "return", Offset: 24},
+		{Kind: NEWLINE, Value: nil, Offset: 30},
+		{Kind: DEDENT, Value: nil, Offset: 32},
+		{Kind: DEDENT, Value: nil, Offset: 32},
+		{Kind: DEF, Value: "def", Offset: 32},
+		{Kind: IDENTIFIER, Value: "bar", Offset: 36},
+		{Kind: LROUNDBRACKET, Value: "(", Offset: 39},
+		{Kind: RROUNDBRACKET, Value: ")", Offset: 40},
+		{Kind: COLON, Value: ":", Offset: 41},
+		{Kind: NEWLINE, Value: nil, Offset: 42},
+		{Kind: INDENT, Value: nil, Offset: 44},
+		{Kind: RETURN, Value: "return", Offset: 44},
+		{Kind: NEWLINE, Value: nil, Offset: 50},
+		{Kind: DEDENT, Value: nil, Offset: 52},
+		{Kind: PASS, Value: "pass", Offset: 52},
+		{Kind: NEWLINE, Value: nil, Offset: 56},
+		{Kind: EOF, Value: nil, Offset: 57},
 	}
 
 	lexer := NewLexer(stream)
@@ -184,19 +209,19 @@ False
 `
 
 	expectedTokenList := []Token{
-		{NONE, "None", 1},
-		{NEWLINE, nil, 5},
-		{TRUE, "True", 6},
-		{NEWLINE, nil, 10},
-		{FALSE, "False", 11},
-		{NEWLINE, nil, 16},
-		{STRING, "Hello", 17},
-		{NEWLINE, nil, 24},
-		{STRING, "He\\\"ll\\\"o", 25},
-		{NEWLINE, nil, 36},
-		{STRING, "He\\nllo", 37},
-		{NEWLINE, nil, 46},
-		{STRING, "He\\\\\\\"llo", 47},
+		{Kind: NONE, Value: "None", Offset: 1},
+		{Kind: NEWLINE, Value: nil, Offset: 5},
+		{Kind: TRUE, Value: "True", Offset: 6},
+		{Kind: NEWLINE, Value: nil, Offset: 10},
+		{Kind: FALSE, Value: "False", Offset: 11},
+		{Kind: NEWLINE, Value: nil, Offset: 16},
+		{Kind: STRING, Value: "Hello", Offset: 17},
+		{Kind: NEWLINE, Value: nil, Offset: 24},
+		{Kind: STRING, Value: "He\\\"ll\\\"o", Offset: 25},
+		{Kind: NEWLINE, Value: nil, Offset: 36},
+		{Kind: STRING, Value: "He\\nllo", Offset: 37},
+		{Kind: NEWLINE, Value: nil, Offset: 46},
+		{Kind: STRING, Value: "He\\\\\\\"llo", Offset: 47},
 	}
 
 	lexer := NewLexer(stream)
@@ -219,31 +244,31 @@ def foo():
 `
 
 	expectedTokenList := []Token{
-		{DEF, "def", 0},
-		{IDENTIFIER, "foo", 0},
-		{LROUNDBRACKET, "(", 0},
-		{RROUNDBRACKET, ")", 0},
-		{COLON, ":", 0},
-		{NEWLINE, nil, 0},
-		{INDENT, nil, 0},
-		{IF, "if", 0},
-		{TRUE, "True", 0},
-		{COLON, ":", 0},
-		{NEWLINE, nil, 0},
-		{INDENT, nil, 0},
-		{PASS, "pass", 0},
-		{NEWLINE, nil, 0},
-		{DEDENT, nil, 0},
-		{ELIF, "elif", 0},
-		{FALSE, "False", 0},
-		{COLON, ":", 0},
-		{NEWLINE, nil, 0},
-		{INDENT, nil, 0},
-		{RETURN, "return", 0},
-		{NEWLINE, nil, 0},
-		{DEDENT, nil, 0},
-		{DEDENT, nil, 0},
-		{EOF, nil, 0},
+		{Kind: DEF, Value: "def", Offset: 0},
+		{Kind: IDENTIFIER, Value: "foo", Offset: 0},
+		{Kind: LROUNDBRACKET, Value: "(", Offset: 0},
+		{Kind: RROUNDBRACKET, Value: ")", Offset: 0},
+		{Kind: COLON, Value: ":", Offset: 0},
+		{Kind: NEWLINE, Value: nil, Offset: 0},
+		{Kind: INDENT, Value: nil, Offset: 0},
+		{Kind: IF, Value: "if", Offset: 0},
+		{Kind: TRUE, Value: "True", Offset: 0},
+		{Kind: COLON, Value: ":", Offset: 0},
+		{Kind: NEWLINE, Value: nil, Offset: 0},
+		{Kind: INDENT, Value: nil, Offset: 0},
+		{Kind: PASS, Value: "pass", Offset: 0},
+		{Kind: NEWLINE, Value: nil, Offset: 0},
+		{Kind: DEDENT, Value: nil, Offset: 0},
+		{Kind: ELIF, Value: "elif", Offset: 0},
+		{Kind: FALSE, Value: "False", Offset: 0},
+		{Kind: COLON, Value: ":", Offset: 0},
+		{Kind: NEWLINE, Value: nil, Offset: 0},
+		{Kind: INDENT, Value: nil, Offset: 0},
+		{Kind: RETURN, Value: "return", Offset: 0},
+		{Kind: NEWLINE, Value: nil, Offset: 0},
+		{Kind: DEDENT, Value: nil, Offset: 0},
+		{Kind: DEDENT, Value: nil, Offset: 0},
+		{Kind: EOF, Value: nil, Offset: 0},
 	}
 
 	lexer := NewLexer(stream)
@@ -255,3 +280,283 @@ def foo():
 		}
 	}
 }
+
+func TestImplicitLineJoining(t *testing.T) {
+	stream := `if x:
+    y = [1,
+  2, # two
+        (3
+)]
+    z(
+)`
+
+	expectedTokenList := []Token{
+		{Kind: IF, Value: "if", Offset: 0},
+		{Kind: IDENTIFIER, Value: "x", Offset: 3},
+		{Kind: COLON, Value: ":", Offset: 4},
+		{Kind: NEWLINE, Offset: 5},
+		{Kind: INDENT, Offset: 10},
+		{Kind: IDENTIFIER, Value: "y", Offset: 10},
+		{Kind: ASSIGN, Value: "=", Offset: 12},
+		{Kind: LSQUAREBRACKET, Value: "[", Offset: 14},
+		{Kind: INTEGER, Value: 1, Offset: 15},
+		{Kind: COMMA, Value: ",", Offset: 16},
+		{Kind: INTEGER, Value: 2, Offset: 20},
+		{Kind: COMMA, Value: ",", Offset: 21},
+		{Kind: LROUNDBRACKET, Value: "(", Offset: 37},
+		{Kind: INTEGER, Value: 3, Offset: 38},
+		{Kind: RROUNDBRACKET, Value: ")", Offset: 40},
+		{Kind: RSQUAREBRACKET, Value: "]", Offset: 41},
+		{Kind: NEWLINE, Offset: 42},
+		{Kind: IDENTIFIER, Value: "z", Offset: 47},
+		{Kind: LROUNDBRACKET, Value: "(", Offset: 48},
+		{Kind: RROUNDBRACKET, Value: ")", Offset: 50},
+		{Kind: NEWLINE, Offset: 51},
+		{Kind: DEDENT, Offset: 52},
+		{Kind: EOF, Offset: 52},
+	}
+
+	lexer := NewLexer(stream)
+
+	for _, expectedToken := range expectedTokenList {
+		token := lexer.Consume(false)
+		if token.Kind != expectedToken.Kind || token.Value != expectedToken.Value || token.Offset != expectedToken.Offset {
+			t.Fatalf("expected: %v (%v) got: %v (%v)", expectedToken.Kind.String(), expectedToken, token.Kind.String(), token)
+		}
+	}
+}
+
+func TestExplicitLineJoining(t *testing.T) {
+	stream := "x = 1 + \\\n    2\ny = \\\r\n3 \\ 4"
+
+	expectedTokenList := []Token{
+		{Kind: IDENTIFIER, Value: "x", Offset: 0},
+		{Kind: ASSIGN, Value: "=", Offset: 2},
+		{Kind: INTEGER, Value: 1, Offset: 4},
+		{Kind: PLUS, Value: "+", Offset: 6},
+		{Kind: INTEGER, Value: 2, Offset: 14},
+		{Kind: NEWLINE, Offset: 15},
+		{Kind: IDENTIFIER, Value: "y", Offset: 16},
+		{Kind: ASSIGN, Value: "=", Offset: 18},
+		{Kind: INTEGER, Value: 3, Offset: 23},
+		{Kind: INTEGER, Value: 4, Offset: 27},
+		{Kind: NEWLINE, Offset: 28},
+		{Kind: EOF, Offset: 29},
+	}
+
+	lexer := NewLexer(stream)
+
+	for _, expectedToken := range expectedTokenList {
+		token := lexer.Consume(false)
+		if token.Kind != expectedToken.Kind || token.Value != expectedToken.Value || token.Offset != expectedToken.Offset {
+			t.Fatalf("expected: %v (%v) got: %v (%v)", expectedToken.Kind.String(), expectedToken, token.Kind.String(), token)
+		}
+	}
+
+	// A backslash that is not at the end of its line is skipped like an invalid character
+	expectedErrors := []Error{
+		{Code: InvalidCharacter, Offset: 25, End: 26},
+	}
+	if len(lexer.Errors()) != len(expectedErrors) {
+		t.Fatalf("expected %d errors but got %v", len(expectedErrors), lexer.Errors())
+	}
+	for errorIdx, lexicalError := range lexer.Errors() {
+		expectedError := expectedErrors[errorIdx]
+		if lexicalError.Code != expectedError.Code || lexicalError.Offset != expectedError.Offset || lexicalError.End != expectedError.End {
+			t.Fatalf("expected error %v but got %v", expectedError, lexicalError)
+		}
+	}
+}
+
+func TestLocations(t *testing.T) {
+	stream := "x:str = \"a\"\r\nif x:\n\tprint(x)"
+
+	expectedTokenList := []Token{
+		{Kind: IDENTIFIER, Value: "x", Offset: 0, End: 1, Line: 1, Column: 1},
+		{Kind: COLON, Value: ":", Offset: 1, End: 2, Line: 1, Column: 2},
+		{Kind: STR, Value: "str", Offset: 2, End: 5, Line: 1, Column: 3},
+		{Kind: ASSIGN, Value: "=", Offset: 6, End: 7, Line: 1, Column: 7},
+		{Kind: STRING, Value: "a", Offset: 8, End: 11, Line: 1, Column: 9},
+		{Kind: NEWLINE, Offset: 11, End: 12, Line: 1, Column: 12},
+		{Kind: IF, Value: "if", Offset: 13, End: 15, Line: 2, Column: 1},
+		{Kind: IDENTIFIER, Value: "x", Offset: 16, End: 17, Line: 2, Column: 4},
+		{Kind: COLON, Value: ":", Offset: 17, End: 18, Line: 2, Column: 5},
+		{Kind: NEWLINE, Offset: 18, End: 19, Line: 2, Column: 6},
+		{Kind: INDENT, Offset: 20, End: 20, Line: 3, Column: 2},
+		{Kind: IDENTIFIER, Value: "print", Offset: 20, End: 25, Line: 3, Column: 2},
+		{Kind: LROUNDBRACKET, Value: "(", Offset: 25, End: 26, Line: 3, Column: 7},
+		{Kind: IDENTIFIER, Value: "x", Offset: 26, End: 27, Line: 3, Column: 8},
+		{Kind: RROUNDBRACKET, Value: ")", Offset: 27, End: 28, Line: 3, Column: 9},
+		// The newline at the end of the input is inserted by the lexer
+		{Kind: NEWLINE, Offset: 28, End: 29, Line: 3, Column: 10},
+		{Kind: DEDENT, Offset: 29, End: 29, Line: 3, Column: 10},
+		{Kind: EOF, Offset: 29, End: 29, Line: 3, Column: 10},
+	}
+
+	lexer := NewLexer(stream)
+
+	for _, expectedToken := range expectedTokenList {
+		token := lexer.Consume(false)
+		if token != expectedToken {
+			t.Fatalf("expected: %v (%v) got: %v (%v)", expectedToken.Kind.String(), expectedToken, token.Kind.String(), token)
+		}
+	}
+
+	expectedLocations := map[int]LocationInfo{
+		8:  {1, 9, "x:str = \"a\""},
+		11: {1, 12, "x:str = \"a\""},
+		16: {2, 4, "if x:"},
+		26: {3, 8, "\tprint(x)"},
+	}
+	for offset, expectedLocation := range expectedLocations {
+		location := lexer.GetLocation(&Token{Offset: offset})
+		if *location != expectedLocation {
+			t.Fatalf("expected location %v of offset %d but got %v", expectedLocation, offset, *location)
+		}
+	}
+}
+
+func TestMultiByteCharacters(t *testing.T) {
+	stream := "x = \"ä€\" # ü\ny"
+
+	expectedTokenList := []Token{
+		{Kind: IDENTIFIER, Value: "x", Offset: 0, End: 1, Line: 1, Column: 1},
+		{Kind: ASSIGN, Value: "=", Offset: 2, End: 3, Line: 1, Column: 3},
+		{Kind: STRING, Value: "ä€", Offset: 4, End: 11, Line: 1, Column: 5},
+		{Kind: NEWLINE, Offset: 16, End: 17, Line: 1, Column: 13},
+		{Kind: IDENTIFIER, Value: "y", Offset: 17, End: 18, Line: 2, Column: 1},
+	}
+
+	lexer := NewLexer(stream)
+
+	for _, expectedToken := range expectedTokenList {
+		token := lexer.Consume(false)
+		if token != expectedToken {
+			t.Fatalf("expected: %v (%v) got: %v (%v)", expectedToken.Kind.String(), expectedToken, token.Kind.String(), token)
+		}
+	}
+}
+
+func TestLexicalErrors(t *testing.T) {
+	stream := "if x:\n    a = $1 / 2\n  b = \"c\\d\"\nprint(!\"e"
+
+	expectedTokenList := []Token{
+		{Kind: IF, Value: "if", Offset: 0},
+		{Kind: IDENTIFIER, Value: "x", Offset: 3},
+		{Kind: COLON, Value: ":", Offset: 4},
+		{Kind: NEWLINE, Offset: 5},
+		{Kind: INDENT, Offset: 10},
+		{Kind: IDENTIFIER, Value: "a", Offset: 10},
+		{Kind: ASSIGN, Value: "=", Offset: 12},
+		{Kind: INTEGER, Value: 1, Offset: 15},
+		{Kind: DIV, Value: "//", Offset: 17},
+		{Kind: INTEGER, Value: 2, Offset: 19},
+		{Kind: NEWLINE, Offset: 20},
+		{Kind: DEDENT, Offset: 23},
+		{Kind: IDENTIFIER, Value: "b", Offset: 23},
+		{Kind: ASSIGN, Value: "=", Offset: 25},
+		{Kind: STRING, Value: "c\\d", Offset: 27},
+		{Kind: NEWLINE, Offset: 32},
+		{Kind: IDENTIFIER, Value: "print", Offset: 33},
+		{Kind: LROUNDBRACKET, Value: "(", Offset: 38},
+		{Kind: NOT, Value: "not", Offset: 39},
+		{Kind: STRING, Value: "e", Offset: 40},
+		{Kind: NEWLINE, Offset: 42},
+		{Kind: EOF, Offset: 43},
+	}
+
+	lexer := NewLexer(stream)
+
+	for _, expectedToken := range expectedTokenList {
+		token := lexer.Consume(false)
+		if token.Kind != expectedToken.Kind || token.Value != expectedToken.Value || token.Offset != expectedToken.Offset {
+			t.Fatalf("expected: %v (%v) got: %v (%v)", expectedToken.Kind.String(), expectedToken, token.Kind.String(), token)
+		}
+	}
+
+	expectedErrors := []Error{
+		{Code: InvalidCharacter, Offset: 14, End: 15},
+		{Code: UnknownOperator, Offset: 17, End: 18},
+		{Code: InconsistentDedent, Offset: 23, End: 23},
+		{Code: UnknownEscapeSequence, Offset: 29, End: 31},
+		{Code: UnknownOperator, Offset: 39, End: 40},
+		{Code: UnterminatedString, Offset: 40, End: 42},
+	}
+	if len(lexer.Errors()) != len(expectedErrors) {
+		t.Fatalf("expected %d errors but got %v", len(expectedErrors), lexer.Errors())
+	}
+	for errorIdx, lexicalError := range lexer.Errors() {
+		expectedError := expectedErrors[errorIdx]
+		if lexicalError.Code != expectedError.Code || lexicalError.Offset != expectedError.Offset || lexicalError.End != expectedError.End {
+			t.Fatalf("expected error %v but got %v", expectedError, lexicalError)
+		}
+	}
+}
+
+func TestIntegerLiteralRange(t *testing.T) {
+	stream := "2147483647 2147483648 2147483649 99999999999999999999"
+
+	expectedTokenList := []Token{
+		{Kind: INTEGER, Value: 2147483647, Offset: 0},
+		{Kind: INTEGER, Value: 2147483648, Offset: 11},
+		{Kind: INTEGER, Value: 0, Offset: 22},
+		{Kind: INTEGER, Value: 0, Offset: 33},
+	}
+
+	lexer := NewLexer(stream)
+
+	for _, expectedToken := range expectedTokenList {
+		token := lexer.Consume(false)
+		if token.Kind != expectedToken.Kind || token.Value != expectedToken.Value || token.Offset != expectedToken.Offset {
+			t.Fatalf("expected: %v (%v) got: %v (%v)", expectedToken.Kind.String(), expectedToken, token.Kind.String(), token)
+		}
+	}
+
+	expectedErrors := []Error{
+		{Code: InvalidIntegerLiteral, Offset: 22, End: 32},
+		{Code: InvalidIntegerLiteral, Offset: 33, End: 53},
+	}
+	if len(lexer.Errors()) != len(expectedErrors) {
+		t.Fatalf("expected %d errors but got %v", len(expectedErrors), lexer.Errors())
+	}
+	for errorIdx, lexicalError := range lexer.Errors() {
+		expectedError := expectedErrors[errorIdx]
+		if lexicalError.Code != expectedError.Code || lexicalError.Offset != expectedError.Offset || lexicalError.End != expectedError.End {
+			t.Fatalf("expected error %v but got %v", expectedError, lexicalError)
+		}
+	}
+}
+
+// benchmarkProgram is a large generated program that resembles the test programs the lexer is run on.
+var benchmarkProgram = strings.Repeat(`class Counter(object):
+    count:int = 0
+    def increment(self:"Counter", step:int) -> int:
+        # Counts in steps
+        self.count = self.count + step
+        return self.count
+
+def fib(n:int) -> int:
+    if n <= 1:
+        return n
+    else:
+        return fib(n - 1) + fib(n - 2)
+
+numbers:[int] = None
+numbers = [1, 2, 3, 42, 1000000]
+while len(numbers) > 0 and not False:
+    print("The number is:\t" + "\"done\"")
+    numbers = numbers + [numbers[0]] if numbers[0] != 3 else []
+`, 1000)
+
+// BenchmarkLexer measures how fast the lexer tokenizes benchmarkProgram.
+// Most of the allocations that remain come from storing the values of tokens in Token.Value.
+func BenchmarkLexer(b *testing.B) {
+	b.SetBytes(int64(len(benchmarkProgram)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		lexer := NewLexer(benchmarkProgram)
+		for lexer.Consume(false).Kind != EOF {
+		}
+	}
+}
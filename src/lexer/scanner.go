@@ -1,54 +1,92 @@
 package lexer
 
-import "strings"
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
 
+// endOfInput is returned by the scanner once the whole input has been consumed.
+const endOfInput rune = -1
+
+// Scanner reads the characters of the source code one after the other.
+// It never copies the source code, so the lexer can build the values of tokens by slicing it.
 type Scanner struct {
-	stream       string
-	streamLookup string
-	peekBuffer   string
-	offset       int
+	source string
+	// offset is the byte offset of the next character in the source code.
+	offset int
+	// lineStarts holds the offset of the first character of every line that has been scanned so far.
+	lineStarts []int
 }
 
-func NewScanner(stream string) Scanner {
-	// This is an unmodified copy of the input stream which can
-	// later be used to search for token locations etc. (for example in Lexer.GetLocation)
-	streamLookup := strings.Clone(stream)
-
+func NewScanner(source string) Scanner {
 	return Scanner{
-		stream:       stream,
-		streamLookup: streamLookup,
-		peekBuffer:   "",
-		offset:       0,
+		source:     source,
+		offset:     0,
+		lineStarts: []int{0},
 	}
 }
 
-func (s *Scanner) Peek() string {
-	if s.peekBuffer == "" {
-		s.peekBuffer = s.Consume()
-		// 1) we never want to shift the offset when only peeking
-		s.offset -= 1
+// Peek returns the next character without consuming it.
+func (s *Scanner) Peek() rune {
+	if s.offset >= len(s.source) {
+		return endOfInput
 	}
-
-	return s.peekBuffer
+	if char := s.source[s.offset]; char < utf8.RuneSelf {
+		return rune(char)
+	}
+	char, _ := utf8.DecodeRuneInString(s.source[s.offset:])
+	return char
 }
 
-func (s *Scanner) Consume() string {
-	if s.peekBuffer != "" {
-		nextChar := s.peekBuffer
-		s.peekBuffer = ""
-		// 2) we only want to shift the offset once the peeked symbol is actually consumed
+// Consume returns the next character and moves on to the character after it.
+// Consuming past the end of the input still moves the offset by one.
+func (s *Scanner) Consume() rune {
+	if s.offset >= len(s.source) {
 		s.offset += 1
-		return nextChar
+		return endOfInput
 	}
 
-	if len(s.stream) == 0 {
-		s.offset += 1
-		return ""
+	char := rune(s.source[s.offset])
+	size := 1
+	if char >= utf8.RuneSelf {
+		char, size = utf8.DecodeRuneInString(s.source[s.offset:])
+	}
+	s.offset += size
+
+	if char == '\n' {
+		s.lineStarts = append(s.lineStarts, s.offset)
 	}
 
-	nextChar := string(s.stream[0])
-	s.stream = s.stream[1:]
-	s.offset += 1
+	return char
+}
+
+// text returns the source code from the given offset up to the next character.
+func (s *Scanner) text(start int) string {
+	return s.source[start:s.offset]
+}
 
-	return nextChar
+// location returns the line and column of the given offset, which are both counted from 1.
+// Columns are counted in characters rather than bytes. Offsets past the end of the input
+// are located right after its last character.
+func (s *Scanner) location(offset int) (int, int) {
+	offset = min(max(offset, 0), len(s.source))
+	// Tokens are almost always located on the line that has been scanned last
+	line := len(s.lineStarts)
+	if offset < s.lineStarts[line-1] {
+		line = sort.Search(len(s.lineStarts), func(lineIdx int) bool {
+			return s.lineStarts[lineIdx] > offset
+		})
+	}
+	column := utf8.RuneCountInString(s.source[s.lineStarts[line-1]:offset]) + 1
+	return line, column
+}
+
+// lineText returns the text of the given line without its line break.
+func (s *Scanner) lineText(line int) string {
+	text := s.source[s.lineStarts[line-1]:]
+	if lineEnd := strings.IndexByte(text, '\n'); lineEnd >= 0 {
+		text = text[:lineEnd]
+	}
+	return strings.TrimSuffix(text, "\r")
 }
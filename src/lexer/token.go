@@ -47,6 +47,7 @@ const (
 	RSQUAREBRACKET
 	COMMA
 	RARROW
+	DOT
 
 	EQ
 	NE
@@ -109,6 +110,7 @@ var TokenKindName = map[TokenKind]string{
 	RSQUAREBRACKET: "RSQUAREBRACKET",
 	COMMA:          "COMMA",
 	RARROW:         "RARROW",
+	DOT:            "DOT",
 
 	EQ: "EQ",
 	NE: "NE",
@@ -136,10 +138,16 @@ func (tk TokenKind) String() string {
 	return TokenKindName[tk]
 }
 
+// Token is a single token of the source code. It starts at Offset and ends right before End,
+// so tokens that do not consume any characters, such as INDENT and DEDENT, have the same offset and end.
+// Line and Column are the location of its start, which are both counted from 1.
 type Token struct {
 	Kind   TokenKind
 	Value  any
 	Offset int
+	End    int
+	Line   int
+	Column int
 }
 
 func (t *Token) Repr() string {
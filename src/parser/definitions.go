@@ -3,6 +3,7 @@ package parser
 import (
 	"chogopy/src/ast"
 	"chogopy/src/lexer"
+	"math"
 )
 
 func (p *Parser) parseDefinitions() []ast.Node {
@@ -10,15 +11,20 @@ func (p *Parser) parseDefinitions() []ast.Node {
 
 	for {
 		if p.check(lexer.IDENTIFIER, lexer.COLON) {
-			varDef := p.parseVarDef()
+			varDef := p.parseRecovering(p.parseVarDef)
 			definitions = append(definitions, varDef)
 			continue
 		}
 		if p.check(lexer.DEF) {
-			funcDef := p.parseFuncDef()
+			funcDef := p.parseRecovering(p.parseFuncDef)
 			definitions = append(definitions, funcDef)
 			continue
 		}
+		if p.check(lexer.CLASS) {
+			classDef := p.parseRecovering(p.parseClassDef)
+			definitions = append(definitions, classDef)
+			continue
+		}
 		break
 	}
 
@@ -26,108 +32,208 @@ func (p *Parser) parseDefinitions() []ast.Node {
 }
 
 func (p *Parser) parseVarDef() ast.Node {
+	start := p.start()
 	varNameToken := p.match(lexer.IDENTIFIER)
 	varName := varNameToken.Value.(string)
 	p.match(lexer.COLON)
 	varType := p.parseType()
+	typedVar := p.finish(&ast.TypedVar{
+		VarName: varName,
+		VarType: varType,
+	}, start)
 	p.match(lexer.ASSIGN)
-	literal := p.parseLiteral()
+	literal := p.parseLiteral(false)
+	varDef := p.finish(&ast.VarDef{
+		TypedVar: typedVar,
+		Literal:  literal,
+	}, start)
 	p.match(lexer.NEWLINE)
 
-	return &ast.VarDef{
-		TypedVar: &ast.TypedVar{
-			VarName: varName,
-			VarType: varType,
-		},
-		Literal: literal,
-	}
+	return varDef
 }
 
 func (p *Parser) parseType() ast.Node {
+	start := p.start()
+
 	if p.check(lexer.INT) {
 		p.match(lexer.INT)
-		return &ast.NamedType{
+		return p.finish(&ast.NamedType{
 			TypeName: "int",
-		}
+		}, start)
 	}
 
 	if p.check(lexer.STR) {
 		p.match(lexer.STR)
-		return &ast.NamedType{
+		return p.finish(&ast.NamedType{
 			TypeName: "str",
-		}
+		}, start)
 	}
 
 	if p.check(lexer.BOOL) {
 		p.match(lexer.BOOL)
-		return &ast.NamedType{
+		return p.finish(&ast.NamedType{
 			TypeName: "bool",
-		}
+		}, start)
 	}
 
 	if p.check(lexer.OBJECT) {
 		p.match(lexer.OBJECT)
-		return &ast.NamedType{
+		return p.finish(&ast.NamedType{
 			TypeName: "object",
-		}
+		}, start)
+	}
+
+	// Class names can be used as types either directly or
+	// in their quoted form, which allows referring to classes that are defined later on.
+	if p.check(lexer.IDENTIFIER) {
+		classNameToken := p.match(lexer.IDENTIFIER)
+		return p.finish(&ast.NamedType{
+			TypeName: classNameToken.Value.(string),
+		}, start)
+	}
+
+	if p.check(lexer.STRING) {
+		classNameToken := p.match(lexer.STRING)
+		return p.finish(&ast.NamedType{
+			TypeName: classNameToken.Value.(string),
+		}, start)
 	}
 
 	if p.check(lexer.LSQUAREBRACKET) {
 		p.match(lexer.LSQUAREBRACKET)
 		elemType := p.parseType()
 		p.match(lexer.RSQUAREBRACKET)
-		return &ast.ListType{
+		return p.finish(&ast.ListType{
 			ElemType: elemType,
-		}
+		}, start)
 	}
 
 	p.syntaxError(UnknownType)
 	return nil
 }
 
-func (p *Parser) parseLiteral() ast.Node {
+// parseLiteral parses a literal. The integer literal 2147483648 is only valid
+// if it is negated, since its value does not fit into a 32-bit integer otherwise.
+func (p *Parser) parseLiteral(negated bool) ast.Node {
+	start := p.start()
+
 	if p.check(lexer.NONE) {
 		p.match(lexer.NONE)
-		return &ast.LiteralExpr{
+		return p.finish(&ast.LiteralExpr{
 			Value: nil,
-		}
+		}, start)
 	}
 
 	if p.check(lexer.TRUE) {
 		p.match(lexer.TRUE)
-		return &ast.LiteralExpr{
+		return p.finish(&ast.LiteralExpr{
 			Value: true,
-		}
+		}, start)
 	}
 
 	if p.check(lexer.FALSE) {
 		p.match(lexer.FALSE)
-		return &ast.LiteralExpr{
+		return p.finish(&ast.LiteralExpr{
 			Value: false,
-		}
+		}, start)
 	}
 
 	if p.check(lexer.INTEGER) {
 		integerToken := p.match(lexer.INTEGER)
 		integerValue := integerToken.Value.(int)
-		return &ast.LiteralExpr{
+		literal := p.finish(&ast.LiteralExpr{
 			Value: integerValue,
+		}, start)
+		if integerValue > math.MaxInt32 && !negated {
+			p.diagnostics.Errorf(lexer.InvalidIntegerLiteral, literal.Span(), "Integer literal %d is out of range.", integerValue)
 		}
+		return literal
 	}
 
 	if p.check(lexer.STRING) {
 		stringToken := p.match(lexer.STRING)
 		stringValue := stringToken.Value.(string)
-		return &ast.LiteralExpr{
+		return p.finish(&ast.LiteralExpr{
 			Value: stringValue,
-		}
+		}, start)
 	}
 
 	p.syntaxError(TokenNotFound)
 	return nil
 }
 
+func (p *Parser) parseClassDef() ast.Node {
+	start := p.start()
+	p.match(lexer.CLASS)
+	classNameToken := p.match(lexer.IDENTIFIER)
+	className := classNameToken.Value.(string)
+
+	p.match(lexer.LROUNDBRACKET)
+	superClass := p.parseSuperClass()
+	p.match(lexer.RROUNDBRACKET)
+
+	p.match(lexer.COLON)
+	p.match(lexer.NEWLINE)
+	p.match(lexer.INDENT)
+
+	classBody := p.parseClassBody()
+
+	if p.check(lexer.INDENT) {
+		p.syntaxError(UnexpectedIndentation)
+	}
+
+	p.match(lexer.DEDENT)
+
+	return p.finish(&ast.ClassDef{
+		ClassName:  className,
+		SuperClass: superClass,
+		ClassBody:  classBody,
+	}, start)
+}
+
+func (p *Parser) parseSuperClass() string {
+	if p.check(lexer.OBJECT) {
+		p.match(lexer.OBJECT)
+		return "object"
+	}
+
+	superClassToken := p.match(lexer.IDENTIFIER)
+	return superClassToken.Value.(string)
+}
+
+func (p *Parser) parseClassBody() []ast.Node {
+	classBody := []ast.Node{}
+
+	// A class without any attributes or methods has to consist of a single pass statement
+	if p.check(lexer.PASS) {
+		p.match(lexer.PASS)
+		p.match(lexer.NEWLINE)
+		return classBody
+	}
+
+	for {
+		if p.check(lexer.IDENTIFIER, lexer.COLON) {
+			attribute := p.parseRecovering(p.parseVarDef)
+			classBody = append(classBody, attribute)
+			continue
+		}
+		if p.check(lexer.DEF) {
+			method := p.parseRecovering(p.parseFuncDef)
+			classBody = append(classBody, method)
+			continue
+		}
+		break
+	}
+
+	if len(classBody) == 0 {
+		p.syntaxError(Indentation)
+	}
+
+	return classBody
+}
+
 func (p *Parser) parseFuncDef() ast.Node {
+	start := p.start()
 	p.match(lexer.DEF)
 	functionNameToken := p.match(lexer.IDENTIFIER)
 	functionName := functionNameToken.Value.(string)
@@ -158,12 +264,12 @@ func (p *Parser) parseFuncDef() ast.Node {
 
 	p.match(lexer.DEDENT)
 
-	return &ast.FuncDef{
+	return p.finish(&ast.FuncDef{
 		FuncName:   functionName,
 		Parameters: parameters,
 		FuncBody:   funcBody,
 		ReturnType: returnType,
-	}
+	}, start)
 }
 
 func (p *Parser) parseFuncParams() []ast.Node {
@@ -180,12 +286,13 @@ func (p *Parser) parseFuncParams() []ast.Node {
 			p.match(lexer.COMMA)
 		}
 
+		start := p.start()
 		varNameToken := p.match(lexer.IDENTIFIER)
 		varName := varNameToken.Value.(string)
 		p.match(lexer.COLON)
 		varType := p.parseType()
 
-		parameter := &ast.TypedVar{VarName: varName, VarType: varType}
+		parameter := p.finish(&ast.TypedVar{VarName: varName, VarType: varType}, start)
 		parameters = append(parameters, parameter)
 		paramIndex++
 	}
@@ -209,30 +316,50 @@ func (p *Parser) parseFuncDeclarations() []ast.Node {
 	funcDeclarations := []ast.Node{}
 
 	if p.check(lexer.NONLOCAL) {
-		p.match(lexer.NONLOCAL)
-		declNameToken := p.match(lexer.IDENTIFIER)
-		declName := declNameToken.Value.(string)
-		p.match(lexer.NEWLINE)
-		nonLocalDecl := &ast.NonLocalDecl{DeclName: declName}
+		nonLocalDecl := p.parseRecovering(p.parseNonLocalDecl)
 		funcDeclarations = append(funcDeclarations, nonLocalDecl)
 		funcDeclarations = append(funcDeclarations, p.parseFuncDeclarations()...)
 	}
 
 	if p.check(lexer.GLOBAL) {
-		p.match(lexer.GLOBAL)
-		declNameToken := p.match(lexer.IDENTIFIER)
-		declName := declNameToken.Value.(string)
-		p.match(lexer.NEWLINE)
-		globalDecl := &ast.GlobalDecl{DeclName: declName}
+		globalDecl := p.parseRecovering(p.parseGlobalDecl)
 		funcDeclarations = append(funcDeclarations, globalDecl)
 		funcDeclarations = append(funcDeclarations, p.parseFuncDeclarations()...)
 	}
 
 	if p.check(lexer.IDENTIFIER, lexer.COLON) {
-		varDef := p.parseVarDef()
+		varDef := p.parseRecovering(p.parseVarDef)
 		funcDeclarations = append(funcDeclarations, varDef)
 		funcDeclarations = append(funcDeclarations, p.parseFuncDeclarations()...)
 	}
 
+	if p.check(lexer.DEF) {
+		nestedFuncDef := p.parseRecovering(p.parseFuncDef)
+		funcDeclarations = append(funcDeclarations, nestedFuncDef)
+		funcDeclarations = append(funcDeclarations, p.parseFuncDeclarations()...)
+	}
+
 	return funcDeclarations
 }
+
+func (p *Parser) parseNonLocalDecl() ast.Node {
+	start := p.start()
+	p.match(lexer.NONLOCAL)
+	declNameToken := p.match(lexer.IDENTIFIER)
+	declName := declNameToken.Value.(string)
+	nonLocalDecl := p.finish(&ast.NonLocalDecl{DeclName: declName}, start)
+	p.match(lexer.NEWLINE)
+
+	return nonLocalDecl
+}
+
+func (p *Parser) parseGlobalDecl() ast.Node {
+	start := p.start()
+	p.match(lexer.GLOBAL)
+	declNameToken := p.match(lexer.IDENTIFIER)
+	declName := declNameToken.Value.(string)
+	globalDecl := p.finish(&ast.GlobalDecl{DeclName: declName}, start)
+	p.match(lexer.NEWLINE)
+
+	return globalDecl
+}
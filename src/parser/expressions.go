@@ -17,6 +17,10 @@ func (p *Parser) parseExpression(insideAnd bool, insideOr bool) ast.Node {
 		expression = p.parseCompoundExpression(false, false, false, false)
 	}
 
+	if expression == nil {
+		p.syntaxError(TokenNotFound)
+	}
+
 	if p.check(lexer.AND) && !insideAnd {
 		expression = p.parseAndExpression(expression)
 	}
@@ -30,30 +34,30 @@ func (p *Parser) parseExpression(insideAnd bool, insideOr bool) ast.Node {
 		condition := p.parseExpression(false, false)
 		p.match(lexer.ELSE)
 		elseNode := p.parseExpression(false, false)
-		return &ast.IfExpr{Condition: condition, IfNode: expression, ElseNode: elseNode}
+		return p.finish(&ast.IfExpr{Condition: condition, IfNode: expression, ElseNode: elseNode}, expression.Span().Start)
 	}
 
-	if expression == nil {
-		p.syntaxError(TokenNotFound)
-	}
 	return expression
 }
 
 func (p *Parser) parseNotExpression() ast.Node {
+	start := p.start()
 	p.match(lexer.NOT)
 
 	if p.check(lexer.NOT) {
-		return &ast.UnaryExpr{Op: "not", Value: p.parseNotExpression()}
+		value := p.parseNotExpression()
+		return p.finish(&ast.UnaryExpr{Op: "not", Value: value}, start)
 	}
 
-	return &ast.UnaryExpr{Op: "not", Value: p.parseCompoundExpression(false, false, false, false)}
+	value := p.parseCompoundExpression(false, false, false, false)
+	return p.finish(&ast.UnaryExpr{Op: "not", Value: value}, start)
 }
 
 func (p *Parser) parseAndExpression(expression ast.Node) ast.Node {
 	if p.check(lexer.AND) {
 		p.match(lexer.AND)
 		rhs := p.parseExpression(true, false)
-		andExpression := &ast.BinaryExpr{Op: "and", Lhs: expression, Rhs: rhs}
+		andExpression := p.finish(&ast.BinaryExpr{Op: "and", Lhs: expression, Rhs: rhs}, expression.Span().Start)
 		return p.parseAndExpression(andExpression)
 	}
 
@@ -64,7 +68,7 @@ func (p *Parser) parseOrExpression(expression ast.Node) ast.Node {
 	if p.check(lexer.OR) {
 		p.match(lexer.OR)
 		rhs := p.parseExpression(false, true)
-		orExpression := &ast.BinaryExpr{Op: "or", Lhs: expression, Rhs: rhs}
+		orExpression := p.finish(&ast.BinaryExpr{Op: "or", Lhs: expression, Rhs: rhs}, expression.Span().Start)
 		return p.parseOrExpression(orExpression)
 	}
 
@@ -79,11 +83,19 @@ func (p *Parser) parseCompoundExpression(insideNegation bool, insideMult bool, i
 	}
 
 	if p.nextTokenIn(simpleCompoundExpressionTokens) {
-		compoundExpression = p.parseSimpleCompoundExpression()
+		compoundExpression = p.parseSimpleCompoundExpression(insideNegation)
 	}
 
-	if p.check(lexer.LSQUAREBRACKET) {
-		compoundExpression = p.parseIndexExpression(compoundExpression)
+	if compoundExpression == nil {
+		p.syntaxError(TokenNotFound)
+	}
+
+	for p.check(lexer.LSQUAREBRACKET) || p.check(lexer.DOT) {
+		if p.check(lexer.LSQUAREBRACKET) {
+			compoundExpression = p.parseIndexExpression(compoundExpression)
+		} else {
+			compoundExpression = p.parseMemberExpression(compoundExpression)
+		}
 	}
 
 	if p.nextTokenIn(multTokens) && !insideNegation && !insideMult {
@@ -98,37 +110,36 @@ func (p *Parser) parseCompoundExpression(insideNegation bool, insideMult bool, i
 		compoundExpression = p.parseCompareExpression(compoundExpression)
 	}
 
-	if compoundExpression == nil {
-		p.syntaxError(TokenNotFound)
-	}
 	return compoundExpression
 }
 
-func (p *Parser) parseSimpleCompoundExpression() ast.Node {
+func (p *Parser) parseSimpleCompoundExpression(insideNegation bool) ast.Node {
 	if p.nextTokenIn(literalTokens) {
-		return p.parseLiteral()
+		return p.parseLiteral(insideNegation)
 	}
 
+	start := p.start()
+
 	if p.check(lexer.IDENTIFIER, lexer.LROUNDBRACKET) {
 		funcNameToken := p.match(lexer.IDENTIFIER)
 		funcName := funcNameToken.Value.(string)
 		p.match(lexer.LROUNDBRACKET)
 		arguments := p.parseExpressionList()
 		p.match(lexer.RROUNDBRACKET)
-		return &ast.CallExpr{FuncName: funcName, Arguments: arguments}
+		return p.finish(&ast.CallExpr{FuncName: funcName, Arguments: arguments}, start)
 	}
 
 	if p.check(lexer.IDENTIFIER) {
 		identifierToken := p.match(lexer.IDENTIFIER)
 		identifier := identifierToken.Value.(string)
-		return &ast.IdentExpr{Identifier: identifier}
+		return p.finish(&ast.IdentExpr{Identifier: identifier}, start)
 	}
 
 	if p.check(lexer.LSQUAREBRACKET) {
 		p.match(lexer.LSQUAREBRACKET)
 		elements := p.parseExpressionList()
 		p.match(lexer.RSQUAREBRACKET)
-		return &ast.ListExpr{Elements: elements}
+		return p.finish(&ast.ListExpr{Elements: elements}, start)
 	}
 
 	if p.check(lexer.LROUNDBRACKET) {
@@ -160,31 +171,55 @@ func (p *Parser) parseExpressionList() []ast.Node {
 }
 
 func (p *Parser) parseUnaryNegation() ast.Node {
+	start := p.start()
 	p.match(lexer.MINUS)
 
 	if p.check(lexer.MINUS) {
-		return &ast.UnaryExpr{Op: "-", Value: p.parseUnaryNegation()}
+		value := p.parseUnaryNegation()
+		return p.finish(&ast.UnaryExpr{Op: "-", Value: value}, start)
 	}
 
-	return &ast.UnaryExpr{Op: "-", Value: p.parseCompoundExpression(true, false, false, false)}
+	value := p.parseCompoundExpression(true, false, false, false)
+	return p.finish(&ast.UnaryExpr{Op: "-", Value: value}, start)
 }
 
 func (p *Parser) parseIndexExpression(compoundExpression ast.Node) ast.Node {
+	start := compoundExpression.Span().Start
+
 	p.match(lexer.LSQUAREBRACKET)
 	index := p.parseExpression(false, false)
 	p.match(lexer.RSQUAREBRACKET)
 
-	indexExpression := &ast.IndexExpr{Value: compoundExpression, Index: index}
+	indexExpression := p.finish(&ast.IndexExpr{Value: compoundExpression, Index: index}, start)
 	for p.check(lexer.LSQUAREBRACKET) {
 		p.match(lexer.LSQUAREBRACKET)
 		index = p.parseExpression(false, false)
 		p.match(lexer.RSQUAREBRACKET)
-		indexExpression = &ast.IndexExpr{Value: indexExpression, Index: index}
+		indexExpression = p.finish(&ast.IndexExpr{Value: indexExpression, Index: index}, start)
 	}
 
 	return indexExpression
 }
 
+// parseMemberExpression parses an attribute access like a.b or, if the
+// member is followed by a list of arguments, a method call like a.f(x).
+func (p *Parser) parseMemberExpression(compoundExpression ast.Node) ast.Node {
+	start := compoundExpression.Span().Start
+
+	p.match(lexer.DOT)
+	memberNameToken := p.match(lexer.IDENTIFIER)
+	memberName := memberNameToken.Value.(string)
+
+	if p.check(lexer.LROUNDBRACKET) {
+		p.match(lexer.LROUNDBRACKET)
+		arguments := p.parseExpressionList()
+		p.match(lexer.RROUNDBRACKET)
+		return p.finish(&ast.MethodCallExpr{Receiver: compoundExpression, MethodName: memberName, Arguments: arguments}, start)
+	}
+
+	return p.finish(&ast.MemberExpr{Object: compoundExpression, MemberName: memberName}, start)
+}
+
 func (p *Parser) parseMultExpression(compoundExpression ast.Node) ast.Node {
 	peekedTokens := p.lexer.Peek(1)
 	peekedToken := &peekedTokens[0]
@@ -194,7 +229,7 @@ func (p *Parser) parseMultExpression(compoundExpression ast.Node) ast.Node {
 		p.match(peekedToken.Kind)
 		rhs := p.parseCompoundExpression(false, true, false, false)
 
-		multExpr := &ast.BinaryExpr{Op: op, Lhs: compoundExpression, Rhs: rhs}
+		multExpr := p.finish(&ast.BinaryExpr{Op: op, Lhs: compoundExpression, Rhs: rhs}, compoundExpression.Span().Start)
 		return p.parseMultExpression(multExpr)
 	}
 
@@ -210,7 +245,7 @@ func (p *Parser) parseAddExpression(compoundExpression ast.Node) ast.Node {
 		p.match(peekedToken.Kind)
 		rhs := p.parseCompoundExpression(false, false, true, false)
 
-		addExpr := &ast.BinaryExpr{Op: op, Lhs: compoundExpression, Rhs: rhs}
+		addExpr := p.finish(&ast.BinaryExpr{Op: op, Lhs: compoundExpression, Rhs: rhs}, compoundExpression.Span().Start)
 		return p.parseAddExpression(addExpr)
 	}
 
@@ -232,5 +267,5 @@ func (p *Parser) parseCompareExpression(compoundExpression ast.Node) ast.Node {
 		p.syntaxError(ComparisonNotAssociative)
 	}
 
-	return &ast.BinaryExpr{Op: op, Lhs: compoundExpression, Rhs: rhs}
+	return p.finish(&ast.BinaryExpr{Op: op, Lhs: compoundExpression, Rhs: rhs}, compoundExpression.Span().Start)
 }
@@ -3,6 +3,7 @@ package parser
 
 import (
 	"chogopy/src/ast"
+	"chogopy/src/diagnostics"
 	"chogopy/src/lexer"
 	"slices"
 )
@@ -64,12 +65,20 @@ var statementTokens = []lexer.TokenKind{
 }
 
 type Parser struct {
-	lexer *lexer.Lexer
+	lexer       *lexer.Lexer
+	diagnostics *diagnostics.Sink
+	lastToken   lexer.Token
+
+	// blockDepth is the number of indented blocks that the parser is currently in.
+	blockDepth int
+	// lexicalErrors is the number of lexical errors that have been reported so far.
+	lexicalErrors int
 }
 
-func NewParser(lexer *lexer.Lexer) Parser {
+func NewParser(lexer *lexer.Lexer, sink *diagnostics.Sink) Parser {
 	return Parser{
-		lexer,
+		lexer:       lexer,
+		diagnostics: sink,
 	}
 }
 
@@ -98,6 +107,8 @@ func (p *Parser) check(expectedTokenKinds ...lexer.TokenKind) bool {
 func (p *Parser) match(expected lexer.TokenKind) lexer.Token {
 	if p.check(expected) {
 		token := p.lexer.Consume(false)
+		p.trackBlockDepth(token)
+		p.trackLastToken(token)
 		return token
 	}
 
@@ -105,14 +116,94 @@ func (p *Parser) match(expected lexer.TokenKind) lexer.Token {
 	return lexer.Token{}
 }
 
+// ParseProgram parses the token stream into the AST of a program.
+// Syntax errors are reported to the diagnostics sink of the parser, which then skips ahead
+// to the next definition or statement and keeps parsing. The returned program may thus be partial
+// and contain an ast.ErrorNode in place of every definition or statement that could not be parsed.
 func (p *Parser) ParseProgram() ast.Program {
+	start := p.start()
 	definitions := p.parseDefinitions()
 	statements := p.parseStatements()
 
+	// Whatever is left over at this point cannot start a statement, so it is
+	// reported and skipped before parsing the statements that come after it.
+	for !p.check(lexer.EOF) {
+		statements = append(statements, p.parseRecovering(p.parseUnexpected))
+		statements = append(statements, p.parseStatements()...)
+	}
+
 	p.match(lexer.EOF)
 
-	return ast.Program{
+	program := ast.Program{
 		Definitions: definitions,
 		Statements:  statements,
 	}
+	p.finish(&program, start)
+	return program
+}
+
+func (p *Parser) parseUnexpected() ast.Node {
+	if p.check(lexer.INDENT) {
+		p.syntaxError(UnexpectedIndentation)
+	}
+	p.syntaxError(TokenNotFound)
+	return nil
+}
+
+// position returns the position of the given offset in the source code.
+func (p *Parser) position(offset int) diagnostics.Position {
+	locationInfo := p.lexer.GetLocation(&lexer.Token{Offset: offset})
+	return diagnostics.Position{
+		Offset: offset,
+		Line:   locationInfo.Line,
+		Column: locationInfo.Column,
+	}
+}
+
+// start returns the position of the next token, which is where the node that is parsed next starts.
+func (p *Parser) start() diagnostics.Position {
+	peekedTokens := p.lexer.Peek(1)
+	peekedToken := &peekedTokens[0]
+	return diagnostics.Position{
+		Offset: peekedToken.Offset,
+		Line:   peekedToken.Line,
+		Column: peekedToken.Column,
+	}
+}
+
+// finish sets the span of a node that has just been parsed.
+// The span starts at the given position and ends with the last token that has been matched.
+func (p *Parser) finish(node ast.Node, start diagnostics.Position) ast.Node {
+	end := p.position(p.lastToken.End)
+	node.SetSpan(diagnostics.Span{Start: start, End: end})
+	return node
+}
+
+// trackBlockDepth keeps track of the number of indented blocks that the given token enters or leaves.
+func (p *Parser) trackBlockDepth(token lexer.Token) {
+	switch token.Kind {
+	case lexer.INDENT:
+		p.blockDepth++
+	case lexer.DEDENT:
+		p.blockDepth--
+	}
+}
+
+// trackLastToken remembers the given token if it can be the last token of a node.
+// Nodes end with their last token that is not part of the indentation structure.
+func (p *Parser) trackLastToken(token lexer.Token) {
+	p.reportLexicalErrors(token.End)
+	if token.Kind != lexer.NEWLINE && token.Kind != lexer.INDENT && token.Kind != lexer.DEDENT {
+		p.lastToken = token
+	}
+}
+
+// reportLexicalErrors reports the errors that the lexer found up to the given offset.
+// Since the lexer runs ahead of the parser, lexical errors are only reported once the parser reaches them
+// so that they are reported in order with the syntax errors.
+func (p *Parser) reportLexicalErrors(end int) {
+	lexicalErrors := p.lexer.Errors()
+	for ; p.lexicalErrors < len(lexicalErrors) && lexicalErrors[p.lexicalErrors].Offset <= end; p.lexicalErrors++ {
+		p.diagnostics.Report(p.lexer.Diagnostic(lexicalErrors[p.lexicalErrors]))
+	}
 }
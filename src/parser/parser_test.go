@@ -2,6 +2,7 @@ package parser
 
 import (
 	"chogopy/src/ast"
+	"chogopy/src/diagnostics"
 	"chogopy/src/lexer"
 	"reflect"
 	"testing"
@@ -11,8 +12,9 @@ import (
 
 func matchParsed(stream string, expectedAst ast.Program) bool {
 	lexer := lexer.NewLexer(stream)
-	parser := NewParser(&lexer)
+	parser := NewParser(&lexer, diagnostics.NewSink())
 	parsedAst := parser.ParseProgram()
+	clearSpans(reflect.ValueOf(&parsedAst))
 
 	if !reflect.DeepEqual(expectedAst, parsedAst) {
 		diffs := pretty.Diff(expectedAst, parsedAst)
@@ -24,6 +26,31 @@ func matchParsed(stream string, expectedAst ast.Program) bool {
 	return true
 }
 
+// clearSpans resets the span of every node in a parsed AST so that it can be compared
+// against an expected AST which has been written down without any source locations.
+func clearSpans(value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return
+		}
+		if node, ok := value.Interface().(ast.Node); ok && value.Kind() == reflect.Pointer {
+			node.SetSpan(diagnostics.Span{})
+		}
+		clearSpans(value.Elem())
+	case reflect.Struct:
+		for i := range value.NumField() {
+			if value.Type().Field(i).IsExported() {
+				clearSpans(value.Field(i))
+			}
+		}
+	case reflect.Slice:
+		for i := range value.Len() {
+			clearSpans(value.Index(i))
+		}
+	}
+}
+
 func TestArithmetic(t *testing.T) {
 	stream := `def foo():
 	1 + 2`
@@ -194,3 +221,385 @@ pass
 		t.Fatalf("Expected AST did not match parsed AST.")
 	}
 }
+
+func TestClassDefinitions(t *testing.T) {
+	stream := `
+class A(object):
+	x: int = 1
+
+	def get(self: A) -> int:
+		return 1
+
+class B(A):
+	pass
+`
+
+	expectedAst := ast.Program{
+		Definitions: []ast.Node{
+			&ast.ClassDef{
+				ClassName:  "A",
+				SuperClass: "object",
+				ClassBody: []ast.Node{
+					&ast.VarDef{
+						TypedVar: &ast.TypedVar{
+							VarName: "x",
+							VarType: &ast.NamedType{
+								TypeName: "int",
+							},
+						},
+						Literal: &ast.LiteralExpr{
+							Value: 1,
+						},
+					},
+					&ast.FuncDef{
+						FuncName: "get",
+						Parameters: []ast.Node{
+							&ast.TypedVar{
+								VarName: "self",
+								VarType: &ast.NamedType{
+									TypeName: "A",
+								},
+							},
+						},
+						FuncBody: []ast.Node{
+							&ast.ReturnStmt{
+								ReturnVal: &ast.LiteralExpr{
+									Value: 1,
+								},
+							},
+						},
+						ReturnType: &ast.NamedType{
+							TypeName: "int",
+						},
+					},
+				},
+			},
+			&ast.ClassDef{
+				ClassName:  "B",
+				SuperClass: "A",
+				ClassBody:  []ast.Node{},
+			},
+		},
+		Statements: []ast.Node{},
+	}
+
+	if !matchParsed(stream, expectedAst) {
+		t.Fatalf("Expected AST did not match parsed AST.")
+	}
+}
+
+func TestMemberExpressions(t *testing.T) {
+	stream := `
+a.b = a.f(1).c[0]
+`
+
+	expectedAst := ast.Program{
+		Definitions: []ast.Node{},
+		Statements: []ast.Node{
+			&ast.AssignStmt{
+				Target: &ast.MemberExpr{
+					Object: &ast.IdentExpr{
+						Identifier: "a",
+					},
+					MemberName: "b",
+				},
+				Value: &ast.IndexExpr{
+					Value: &ast.MemberExpr{
+						Object: &ast.MethodCallExpr{
+							Receiver: &ast.IdentExpr{
+								Identifier: "a",
+							},
+							MethodName: "f",
+							Arguments: []ast.Node{
+								&ast.LiteralExpr{
+									Value: 1,
+								},
+							},
+						},
+						MemberName: "c",
+					},
+					Index: &ast.LiteralExpr{
+						Value: 0,
+					},
+				},
+			},
+		},
+	}
+
+	if !matchParsed(stream, expectedAst) {
+		t.Fatalf("Expected AST did not match parsed AST.")
+	}
+}
+
+func TestLineJoining(t *testing.T) {
+	stream := `
+a = [1,
+     2]
+print(a, \
+    3)
+`
+
+	expectedAst := ast.Program{
+		Definitions: []ast.Node{},
+		Statements: []ast.Node{
+			&ast.AssignStmt{
+				Target: &ast.IdentExpr{
+					Identifier: "a",
+				},
+				Value: &ast.ListExpr{
+					Elements: []ast.Node{
+						&ast.LiteralExpr{
+							Value: 1,
+						},
+						&ast.LiteralExpr{
+							Value: 2,
+						},
+					},
+				},
+			},
+			&ast.CallExpr{
+				FuncName: "print",
+				Arguments: []ast.Node{
+					&ast.IdentExpr{
+						Identifier: "a",
+					},
+					&ast.LiteralExpr{
+						Value: 3,
+					},
+				},
+			},
+		},
+	}
+
+	if !matchParsed(stream, expectedAst) {
+		t.Fatalf("Expected AST did not match parsed AST.")
+	}
+}
+
+func TestNestedFunctionDefinitions(t *testing.T) {
+	stream := `
+def foo():
+	x: int = 0
+	def bar():
+		nonlocal x
+		x = 1
+	bar()
+`
+
+	expectedAst := ast.Program{
+		Definitions: []ast.Node{
+			&ast.FuncDef{
+				FuncName:   "foo",
+				Parameters: []ast.Node{},
+				FuncBody: []ast.Node{
+					&ast.VarDef{
+						TypedVar: &ast.TypedVar{
+							VarName: "x",
+							VarType: &ast.NamedType{
+								TypeName: "int",
+							},
+						},
+						Literal: &ast.LiteralExpr{
+							Value: 0,
+						},
+					},
+					&ast.FuncDef{
+						FuncName:   "bar",
+						Parameters: []ast.Node{},
+						FuncBody: []ast.Node{
+							&ast.NonLocalDecl{
+								DeclName: "x",
+							},
+							&ast.AssignStmt{
+								Target: &ast.IdentExpr{
+									Identifier: "x",
+								},
+								Value: &ast.LiteralExpr{
+									Value: 1,
+								},
+							},
+						},
+						ReturnType: &ast.NamedType{
+							TypeName: "<None>",
+						},
+					},
+					&ast.CallExpr{
+						FuncName:  "bar",
+						Arguments: []ast.Node{},
+					},
+				},
+				ReturnType: &ast.NamedType{
+					TypeName: "<None>",
+				},
+			},
+		},
+		Statements: []ast.Node{},
+	}
+
+	if !matchParsed(stream, expectedAst) {
+		t.Fatalf("Expected AST did not match parsed AST.")
+	}
+}
+
+func TestSyntaxErrorReported(t *testing.T) {
+	stream := `x:int = 1
+print(x 1)`
+
+	lexer := lexer.NewLexer(stream)
+	sink := diagnostics.NewSink()
+	parser := NewParser(&lexer, sink)
+	parser.ParseProgram()
+
+	if sink.ErrorCount() != 1 {
+		t.Fatalf("Expected 1 syntax error but found %d.", sink.ErrorCount())
+	}
+
+	syntaxError := sink.Diagnostics()[0]
+	if syntaxError.Span.Start.Line != 2 || syntaxError.Span.Start.Column != 9 {
+		t.Fatalf("Expected syntax error at line 2, column 9 but found %s.", syntaxError)
+	}
+}
+
+func TestLexicalErrorsReported(t *testing.T) {
+	stream := `x:int = 1
+print(x 1)
+print("abc)
+print(x $ 1)`
+
+	lexer := lexer.NewLexer(stream)
+	sink := diagnostics.NewSink()
+	parser := NewParser(&lexer, sink)
+	parser.ParseProgram()
+
+	expectedErrors := []struct {
+		code   string
+		line   int
+		column int
+	}{
+		{"E106", 2, 9},
+		{"E005", 3, 7},
+		{"E106", 3, 12},
+		{"E001", 4, 9},
+		{"E106", 4, 11},
+	}
+	if sink.ErrorCount() != len(expectedErrors) {
+		t.Fatalf("Expected %d errors but found %v.", len(expectedErrors), sink.Diagnostics())
+	}
+	for i, diagnostic := range sink.Diagnostics() {
+		expectedError := expectedErrors[i]
+		if diagnostic.Code != expectedError.code || diagnostic.Span.Start.Line != expectedError.line || diagnostic.Span.Start.Column != expectedError.column {
+			t.Fatalf("Expected error %s at line %d, column %d but found %s.", expectedError.code, expectedError.line, expectedError.column, diagnostic)
+		}
+	}
+}
+
+func TestIntegerLiteralRange(t *testing.T) {
+	stream := `x:int = 2147483648
+print(-2147483648)
+print(--2147483648)
+print(2147483648)
+print(-(2147483648))
+print(1 - 2147483648)
+print(-3000000000)`
+
+	lexer := lexer.NewLexer(stream)
+	sink := diagnostics.NewSink()
+	parser := NewParser(&lexer, sink)
+	parser.ParseProgram()
+
+	expectedErrors := []struct {
+		code   string
+		line   int
+		column int
+	}{
+		{"E004", 1, 9},
+		{"E004", 4, 7},
+		{"E004", 5, 9},
+		{"E004", 6, 11},
+		{"E004", 7, 8},
+	}
+	if sink.ErrorCount() != len(expectedErrors) {
+		t.Fatalf("Expected %d errors but found %v.", len(expectedErrors), sink.Diagnostics())
+	}
+	for i, diagnostic := range sink.Diagnostics() {
+		expectedError := expectedErrors[i]
+		if diagnostic.Code != expectedError.code || diagnostic.Span.Start.Line != expectedError.line || diagnostic.Span.Start.Column != expectedError.column {
+			t.Fatalf("Expected error %s at line %d, column %d but found %s.", expectedError.code, expectedError.line, expectedError.column, diagnostic)
+		}
+	}
+}
+
+func TestSyntaxErrorRecovery(t *testing.T) {
+	stream := `x:int = 1
+def f(a int):
+    pass
+if x y:
+    print(1)
+print(x 1)
+while x:
+    x = 
+    print(x)
+print(x)`
+
+	lexer := lexer.NewLexer(stream)
+	sink := diagnostics.NewSink()
+	parser := NewParser(&lexer, sink)
+	program := parser.ParseProgram()
+
+	expectedLines := []int{2, 4, 6, 8}
+	if sink.ErrorCount() != len(expectedLines) {
+		t.Fatalf("Expected %d syntax errors but found %d.", len(expectedLines), sink.ErrorCount())
+	}
+	for i, syntaxError := range sink.Diagnostics() {
+		if syntaxError.Span.Start.Line != expectedLines[i] {
+			t.Fatalf("Expected syntax error on line %d but found %s.", expectedLines[i], syntaxError)
+		}
+	}
+
+	if _, ok := program.Definitions[1].(*ast.ErrorNode); !ok {
+		t.Fatalf("Expected the function definition to be replaced by an error node.")
+	}
+
+	expectedStatements := []string{"ErrorNode", "ErrorNode", "WhileStmt", "CallExpr"}
+	if len(program.Statements) != len(expectedStatements) {
+		t.Fatalf("Expected %d statements but found %d.", len(expectedStatements), len(program.Statements))
+	}
+	for i, statement := range program.Statements {
+		if statement.Name() != expectedStatements[i] {
+			t.Fatalf("Expected statement %d to be %s but found %s.", i, expectedStatements[i], statement.Name())
+		}
+	}
+
+	whileBody := program.Statements[2].(*ast.WhileStmt).Body
+	if _, ok := whileBody[0].(*ast.ErrorNode); !ok || len(whileBody) != 2 {
+		t.Fatalf("Expected the parser to recover within the body of the while loop.")
+	}
+}
+
+func TestNodeSpans(t *testing.T) {
+	stream := `x:int = 1
+print(x + 12)`
+
+	lexer := lexer.NewLexer(stream)
+	parser := NewParser(&lexer, diagnostics.NewSink())
+	program := parser.ParseProgram()
+
+	callExpr := program.Statements[0].(*ast.CallExpr)
+	binaryExpr := callExpr.Arguments[0].(*ast.BinaryExpr)
+
+	expectedSpans := []struct {
+		node ast.Node
+		span diagnostics.Span
+	}{
+		{program.Definitions[0], diagnostics.Span{Start: diagnostics.Position{Offset: 0, Line: 1, Column: 1}, End: diagnostics.Position{Offset: 9, Line: 1, Column: 10}}},
+		{callExpr, diagnostics.Span{Start: diagnostics.Position{Offset: 10, Line: 2, Column: 1}, End: diagnostics.Position{Offset: 23, Line: 2, Column: 14}}},
+		{binaryExpr, diagnostics.Span{Start: diagnostics.Position{Offset: 16, Line: 2, Column: 7}, End: diagnostics.Position{Offset: 22, Line: 2, Column: 13}}},
+		{binaryExpr.Rhs, diagnostics.Span{Start: diagnostics.Position{Offset: 20, Line: 2, Column: 11}, End: diagnostics.Position{Offset: 22, Line: 2, Column: 13}}},
+	}
+
+	for _, expected := range expectedSpans {
+		if expected.node.Span() != expected.span {
+			t.Fatalf("Expected %s to span %# v but found %# v.", expected.node.Name(), expected.span, expected.node.Span())
+		}
+	}
+}
@@ -9,7 +9,7 @@ func (p *Parser) parseStatements() []ast.Node {
 	statements := []ast.Node{}
 
 	for p.nextTokenIn(expressionTokens) || p.nextTokenIn(statementTokens) {
-		statement := p.parseStatement()
+		statement := p.parseRecovering(p.parseStatement)
 		statements = append(statements, statement)
 	}
 
@@ -25,6 +25,8 @@ func (p *Parser) parseStatement() ast.Node {
 		return simpleStatement
 	}
 
+	start := p.start()
+
 	if p.check(lexer.IF) {
 		p.match(lexer.IF)
 		condition := p.parseExpression(false, false)
@@ -43,7 +45,7 @@ func (p *Parser) parseStatement() ast.Node {
 		if len(elseBody) > 0 {
 			p.match(lexer.DEDENT)
 		}
-		return &ast.IfStmt{Condition: condition, IfBody: ifBody, ElseBody: elseBody}
+		return p.finish(&ast.IfStmt{Condition: condition, IfBody: ifBody, ElseBody: elseBody}, start)
 	}
 
 	if p.check(lexer.WHILE) {
@@ -60,7 +62,7 @@ func (p *Parser) parseStatement() ast.Node {
 			p.syntaxError(UnexpectedIndentation)
 		}
 		p.match(lexer.DEDENT)
-		return &ast.WhileStmt{Condition: condition, Body: body}
+		return p.finish(&ast.WhileStmt{Condition: condition, Body: body}, start)
 	}
 
 	if p.check(lexer.FOR) {
@@ -80,7 +82,7 @@ func (p *Parser) parseStatement() ast.Node {
 			p.syntaxError(UnexpectedIndentation)
 		}
 		p.match(lexer.DEDENT)
-		return &ast.ForStmt{IterName: iterName, Iter: iter, Body: body}
+		return p.finish(&ast.ForStmt{IterName: iterName, Iter: iter, Body: body}, start)
 	}
 
 	p.syntaxError(TokenNotFound)
@@ -91,6 +93,7 @@ func (p *Parser) parseElseBody() []ast.Node {
 	elseBody := []ast.Node{}
 
 	if p.check(lexer.ELIF) {
+		start := p.start()
 		p.match(lexer.ELIF)
 
 		condition := p.parseExpression(false, false)
@@ -109,7 +112,7 @@ func (p *Parser) parseElseBody() []ast.Node {
 		p.match(lexer.DEDENT)
 		elifElseBody := p.parseElseBody()
 
-		elif := &ast.IfStmt{Condition: condition, IfBody: elifIfBody, ElseBody: elifElseBody}
+		elif := p.finish(&ast.IfStmt{Condition: condition, IfBody: elifIfBody, ElseBody: elifElseBody}, start)
 
 		elseBody = append(elseBody, elif)
 		return elseBody
@@ -132,9 +135,11 @@ func (p *Parser) parseSimpleStatement() ast.Node {
 		p.syntaxError(VariableDefinedLater)
 	}
 
+	start := p.start()
+
 	if p.check(lexer.PASS) {
 		p.match(lexer.PASS)
-		return &ast.PassStmt{}
+		return p.finish(&ast.PassStmt{}, start)
 	}
 
 	if p.check(lexer.RETURN) {
@@ -143,7 +148,7 @@ func (p *Parser) parseSimpleStatement() ast.Node {
 		if p.nextTokenIn(expressionTokens) {
 			returnVal = p.parseExpression(false, false)
 		}
-		return &ast.ReturnStmt{ReturnVal: returnVal}
+		return p.finish(&ast.ReturnStmt{ReturnVal: returnVal}, start)
 	}
 
 	if p.nextTokenIn(expressionTokens) {
@@ -159,7 +164,8 @@ func (p *Parser) parseExpressionAssignList() ast.Node {
 
 	if p.check(lexer.ASSIGN) {
 		p.match(lexer.ASSIGN)
-		return &ast.AssignStmt{Target: expression, Value: p.parseExpressionAssignList()}
+		value := p.parseExpressionAssignList()
+		return p.finish(&ast.AssignStmt{Target: expression, Value: value}, expression.Span().Start)
 	}
 
 	return expression
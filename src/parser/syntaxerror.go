@@ -1,9 +1,10 @@
 package parser
 
 import (
-	"fmt"
-	"os"
-	"strings"
+	"chogopy/src/ast"
+	"chogopy/src/diagnostics"
+	"chogopy/src/lexer"
+	"slices"
 )
 
 type SyntaxErrorKind int
@@ -21,35 +22,138 @@ const (
 	VariableDefinedLater
 )
 
+// syncTokens are the keywords that start a new definition or statement.
+// The parser resumes at them after skipping the remainder of an erroneous one.
+var syncTokens = append([]lexer.TokenKind{
+	lexer.DEF,
+	lexer.CLASS,
+}, statementTokens...)
+
+// abortParsing is used to unwind the parser after a syntax error has been reported.
+// It is recovered from in parseRecovering.
+type abortParsing struct{}
+
+// syntaxError reports a syntax error at the location of the next token and unwinds the parser
+// to the definition or statement that is currently being parsed.
 func (p *Parser) syntaxError(errorKind SyntaxErrorKind) {
 	peekedTokens := p.lexer.Peek(1)
 	peekedToken := &peekedTokens[0]
-	locationInfo := p.lexer.GetLocation(peekedToken)
+	p.reportLexicalErrors(peekedToken.End)
+
+	span := diagnostics.Span{
+		Start: p.position(peekedToken.Offset),
+		End:   p.position(peekedToken.End),
+	}
 
 	switch errorKind {
 	case CommaExpected:
-		fmt.Printf("SyntaxError (line %d, column %d): Comma expected.\n", locationInfo.Line, locationInfo.Column)
+		p.diagnostics.Errorf("E101", span, "Comma expected.")
 	case ComparisonNotAssociative:
-		fmt.Printf("SyntaxError (line %d, column %d): Comparison operators are not associative.\n", locationInfo.Line, locationInfo.Column)
+		p.diagnostics.Errorf("E102", span, "Comparison operators are not associative.")
 	case ExpectedExpression:
-		fmt.Printf("SyntaxError (line %d, column %d): Expected Expression.\n", locationInfo.Line, locationInfo.Column)
+		p.diagnostics.Errorf("E103", span, "Expected Expression.")
 	case Indentation:
-		fmt.Printf("SyntaxError (line %d, column %d): Expected at least one indented statement.\n", locationInfo.Line, locationInfo.Column)
+		p.diagnostics.Errorf("E104", span, "Expected at least one indented statement.")
 	case NoLhsInAssignment:
-		fmt.Printf("SyntaxError (line %d, column %d): No left-hand side in assign statement.\n", locationInfo.Line, locationInfo.Column)
+		p.diagnostics.Errorf("E105", span, "No left-hand side in assign statement.")
 	case TokenNotFound:
-		fmt.Printf("SyntaxError (line %d, column %d): Expected token not found.\n", locationInfo.Line, locationInfo.Column)
+		p.diagnostics.Errorf("E106", span, "Expected token not found.")
 	case UnexpectedIndentation:
-		fmt.Printf("SyntaxError (line %d, column %d): Unexpected indentation.\n", locationInfo.Line, locationInfo.Column)
+		p.diagnostics.Errorf("E107", span, "Unexpected indentation.")
 	case UnknownType:
-		fmt.Printf("SyntaxError (line %d, column %d): Unknown type.\n", locationInfo.Line, locationInfo.Column)
+		p.diagnostics.Errorf("E108", span, "Unknown type.")
 	case UnmatchedParantheses:
-		fmt.Printf("SyntaxError (line %d, column %d): Unmatched ')'.\n", locationInfo.Line, locationInfo.Column)
+		p.diagnostics.Errorf("E109", span, "Unmatched ')'.")
 	case VariableDefinedLater:
-		fmt.Printf("SyntaxError (line %d, column %d): Variable declaration after non-declaration statement.\n", locationInfo.Line, locationInfo.Column)
+		p.diagnostics.Errorf("E110", span, "Variable declaration after non-declaration statement.")
+	}
+
+	panic(abortParsing{})
+}
+
+// parseRecovering parses a definition or statement with the given function.
+// If a syntax error occurs while doing so, the parser skips ahead to the start of
+// the next definition or statement and an ast.ErrorNode is returned in place of the node.
+func (p *Parser) parseRecovering(parse func() ast.Node) (node ast.Node) {
+	start := p.start()
+	blockDepth := p.blockDepth
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			if _, aborted := recovered.(abortParsing); !aborted {
+				panic(recovered)
+			}
+			p.synchronize(blockDepth)
+			node = p.finishError(start)
+		}
+	}()
+
+	return parse()
+}
+
+// synchronize skips tokens until the parser is back at the given block depth and at a point where
+// a new definition or statement can start. That is, after the NEWLINE ending the erroneous line
+// and any block that is indented under it, before a DEDENT closing the enclosing block,
+// or before a keyword starting a definition or statement.
+func (p *Parser) synchronize(blockDepth int) {
+	// The number of blocks that were opened after the erroneous node started and are yet to be closed
+	depth := p.blockDepth - blockDepth
+	p.blockDepth = blockDepth
+	skipped := false
+
+	for {
+		peekedTokens := p.lexer.Peek(1)
+		peekedToken := &peekedTokens[0]
+
+		switch {
+		case peekedToken.Kind == lexer.EOF:
+			return
+
+		case peekedToken.Kind == lexer.INDENT:
+			depth++
+
+		case peekedToken.Kind == lexer.DEDENT && depth > 0:
+			depth--
+			if depth == 0 {
+				p.skip()
+				return
+			}
+
+		// A DEDENT closes the enclosing block, which is left to the parsing function of that block.
+		// There is no enclosing block on the top level so the DEDENT is skipped there.
+		case peekedToken.Kind == lexer.DEDENT && blockDepth > 0:
+			return
+
+		case peekedToken.Kind == lexer.NEWLINE && depth == 0:
+			p.skip()
+			if !p.check(lexer.INDENT) {
+				return
+			}
+			continue
+
+		// The token that caused the error is always skipped so that the parser is guaranteed to make progress.
+		case slices.Contains(syncTokens, peekedToken.Kind) && depth == 0 && skipped:
+			return
+		}
+
+		p.skip()
+		skipped = true
 	}
+}
 
-	fmt.Printf(">>>%s\n", locationInfo.LineLiteral)
-	fmt.Println(">>>" + strings.Repeat("-", locationInfo.Column-1) + "^")
-	os.Exit(0)
+// skip consumes the next token without matching it against any expected token kind.
+func (p *Parser) skip() {
+	token := p.lexer.Consume(false)
+	p.trackLastToken(token)
+}
+
+// finishError creates an error node that spans from the given position to the last skipped token.
+func (p *Parser) finishError(start diagnostics.Position) ast.Node {
+	errorNode := &ast.ErrorNode{}
+	end := p.position(p.lastToken.End)
+	if end.Offset < start.Offset {
+		end = start
+	}
+	errorNode.SetSpan(diagnostics.Span{Start: start, End: end})
+	return errorNode
 }
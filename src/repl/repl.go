@@ -0,0 +1,211 @@
+// Package repl implements an interactive read-eval-print loop for ChocoPy.
+//
+// Every entry is checked against the definitions of all previous entries and then executed
+// by the tree-walking interpreter. Entries that contain errors are rejected as a whole,
+// which means that none of their definitions are kept.
+package repl
+
+import (
+	"bufio"
+	"chogopy/src/ast"
+	"chogopy/src/diagnostics"
+	"chogopy/src/interp"
+	"chogopy/src/lexer"
+	"chogopy/src/parser"
+	"chogopy/src/scopes"
+	"chogopy/src/typechecks"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+)
+
+const (
+	prompt             = ">>> "
+	continuationPrompt = "... "
+)
+
+type REPL struct {
+	stdin  *bufio.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	// nameContext and localEnv hold the definitions of every entry that has been accepted so far.
+	nameContext *scopes.NameContext
+	localEnv    typechecks.LocalEnvironment
+	interpreter *interp.Interpreter
+}
+
+// New creates a REPL that reads entries from stdin, prints results to stdout and reports errors on stderr.
+// Programs that call input() read from stdin as well.
+func New(stdin io.Reader, stdout io.Writer, stderr io.Writer) *REPL {
+	reader := bufio.NewReader(stdin)
+	empty := &ast.Program{}
+	sink := diagnostics.NewSink()
+
+	nameScopes := scopes.NameScopes{}
+	nameScopes.Analyze(empty, sink)
+	staticTyping := typechecks.StaticTyping{}
+	staticTyping.Analyze(empty, sink)
+
+	return &REPL{
+		stdin:       reader,
+		stdout:      stdout,
+		stderr:      stderr,
+		nameContext: nameScopes.NameContext,
+		localEnv:    staticTyping.LocalEnv,
+		interpreter: interp.NewInterpreter(reader, stdout),
+	}
+}
+
+// Run reads and executes entries until the end of the input is reached.
+func (r *REPL) Run() {
+	for {
+		entry, ok := r.readEntry()
+		if !ok {
+			fmt.Fprintln(r.stdout)
+			return
+		}
+		r.Execute(entry)
+	}
+}
+
+// readEntry reads lines until they form a complete entry.
+// It returns false once the end of the input has been reached.
+func (r *REPL) readEntry() (string, bool) {
+	entry := ""
+	fmt.Fprint(r.stdout, prompt)
+
+	for {
+		line, err := r.stdin.ReadString('\n')
+		if err != nil && line == "" {
+			return entry, entry != ""
+		}
+
+		// An empty line ends the block that is currently being entered
+		if entry != "" && strings.TrimSpace(line) == "" {
+			return entry, true
+		}
+
+		entry += strings.TrimSuffix(line, "\n") + "\n"
+		if isComplete(entry) {
+			return entry, true
+		}
+		fmt.Fprint(r.stdout, continuationPrompt)
+	}
+}
+
+// isComplete reports whether the given entry can be executed or whether it is in the middle of a block.
+// This is the case if its last line opens up a block or if the lexer emitted an INDENT token,
+// since only an empty line tells us that the block has ended.
+// An entry is also incomplete while it has unclosed brackets or its last line ends with a backslash.
+func isComplete(entry string) bool {
+	if strings.HasSuffix(strings.TrimRight(entry, "\r\n"), "\\") {
+		return false
+	}
+
+	entryLexer := lexer.NewLexer(entry)
+
+	lastKind := lexer.NEWLINE
+	bracketDepth := 0
+	for token := entryLexer.Consume(false); token.Kind != lexer.EOF; token = entryLexer.Consume(false) {
+		switch token.Kind {
+		case lexer.INDENT:
+			return false
+		case lexer.LROUNDBRACKET, lexer.LSQUAREBRACKET:
+			bracketDepth++
+		case lexer.RROUNDBRACKET, lexer.RSQUAREBRACKET:
+			bracketDepth--
+		}
+		if token.Kind != lexer.NEWLINE && token.Kind != lexer.DEDENT {
+			lastKind = token.Kind
+		}
+	}
+
+	return lastKind != lexer.COLON && bracketDepth <= 0
+}
+
+// Execute checks the given entry and executes it if no errors were found.
+// The value and type of every expression statement whose type is not None is printed.
+func (r *REPL) Execute(entry string) {
+	sink := diagnostics.NewSink()
+	entryLexer := lexer.NewLexer(entry)
+	entryParser := parser.NewParser(&entryLexer, sink)
+	program := entryParser.ParseProgram()
+
+	// The analysis passes add the definitions of the entry to copies of the
+	// previous definitions so that they can be thrown away if the entry is rejected
+	nameContext := r.nameContext.Clone()
+	localEnv := maps.Clone(r.localEnv)
+
+	if !sink.HasErrors() {
+		assignTargets := scopes.AssignTargets{}
+		assignTargets.Analyze(&program, sink)
+		nameScopes := scopes.NameScopes{NameContext: &nameContext}
+		nameScopes.Analyze(&program, sink)
+		nameContext = *nameScopes.NameContext
+	}
+	if !sink.HasErrors() {
+		staticTyping := typechecks.StaticTyping{LocalEnv: localEnv}
+		staticTyping.Analyze(&program, sink)
+		localEnv = staticTyping.LocalEnv
+	}
+	if sink.HasErrors() {
+		diagnostics.Print(r.stderr, sink.Diagnostics(), entry)
+		return
+	}
+
+	r.nameContext = &nameContext
+	r.localEnv = localEnv
+
+	err := r.interpreter.Run(&ast.Program{Definitions: program.Definitions})
+	for _, statement := range program.Statements {
+		if err != nil {
+			break
+		}
+
+		typeHint := ast.TypeHintOf(statement)
+		if typeHint == nil {
+			err = r.interpreter.Run(&ast.Program{Statements: []ast.Node{statement}})
+			continue
+		}
+
+		var value interp.Value
+		value, err = r.interpreter.Eval(statement)
+		if err == nil && typeHint != ast.None {
+			fmt.Fprintf(r.stdout, "%s : %s\n", interp.Repr(value), typeName(typeHint))
+		}
+	}
+
+	var runtimeError *interp.RuntimeError
+	if errors.As(err, &runtimeError) {
+		fmt.Fprintln(r.stderr, runtimeError.Message)
+	}
+}
+
+// typeName returns the name of the given type the way it is written in ChocoPy source code.
+func typeName(typeHint ast.TypeAttr) string {
+	switch typeHint := typeHint.(type) {
+	case ast.BasicAttribute:
+		switch typeHint {
+		case ast.Integer:
+			return "int"
+		case ast.Boolean:
+			return "bool"
+		case ast.String:
+			return "str"
+		case ast.None:
+			return "<None>"
+		case ast.Empty:
+			return "<Empty>"
+		case ast.Object:
+			return "object"
+		}
+	case ast.ListAttribute:
+		return "[" + typeName(typeHint.ElemType) + "]"
+	case ast.ClassAttribute:
+		return typeHint.ClassName
+	}
+	return typeHint.String()
+}
@@ -0,0 +1,64 @@
+package repl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestREPL(t *testing.T) {
+	input := `x:int = 3
+def double(n:int) -> int:
+    return n * 2
+
+double(x)
+print(x)
+for x in [1, 2]:
+    print(x)
+
+[x, x]
+`
+	stdout := strings.Builder{}
+	stderr := strings.Builder{}
+	New(strings.NewReader(input), &stdout, &stderr).Run()
+
+	expected := ">>> >>> ... ... >>> 6 : int\n>>> 3\n>>> ... ... 1\n2\n>>> [2, 2] : [int]\n>>> \n"
+	if stdout.String() != expected || stderr.String() != "" {
+		t.Fatalf("Expected %q but found %q and errors %q.", expected, stdout.String(), stderr.String())
+	}
+}
+
+func TestREPLRejectsEntriesWithErrors(t *testing.T) {
+	stdout := strings.Builder{}
+	stderr := strings.Builder{}
+	repl := New(strings.NewReader(""), &stdout, &stderr)
+
+	repl.Execute("x:int = 1\ny:int = True\n")
+	if !strings.Contains(stderr.String(), "error[") {
+		t.Fatalf("Expected the entry to be rejected but found %q.", stderr.String())
+	}
+
+	// None of the definitions of a rejected entry are kept
+	stderr.Reset()
+	repl.Execute("x:int = 2\nx\n")
+	if stdout.String() != "2 : int\n" || stderr.String() != "" {
+		t.Fatalf("Expected x to be defined anew but found %q and errors %q.", stdout.String(), stderr.String())
+	}
+}
+
+func TestIsComplete(t *testing.T) {
+	tests := map[string]bool{
+		"x:int = 1\n":                true,
+		"if True:\n":                 false,
+		"if True:\n    pass\n":       false,
+		"def f() -> int: return 1\n": true,
+		"print([1,\n":                false,
+		"print([1,\n2])\n":           true,
+		"x:int = 1 + \\\n":           false,
+	}
+
+	for entry, expected := range tests {
+		if isComplete(entry) != expected {
+			t.Fatalf("Expected isComplete(%q) to be %t.", entry, expected)
+		}
+	}
+}
@@ -2,15 +2,16 @@ package scopes
 
 import (
 	"chogopy/src/ast"
-	"fmt"
-	"os"
+	"chogopy/src/diagnostics"
 )
 
 type AssignTargets struct {
+	diagnostics *diagnostics.Sink
 	ast.BaseVisitor
 }
 
-func (at *AssignTargets) Analyze(program *ast.Program) {
+func (at *AssignTargets) Analyze(program *ast.Program, sink *diagnostics.Sink) {
+	at.diagnostics = sink
 	program.Visit(at)
 }
 
@@ -20,9 +21,9 @@ func (at *AssignTargets) VisitAssignStmt(assignStmt *ast.AssignStmt) {
 		return
 	case *ast.IndexExpr:
 		return
+	case *ast.MemberExpr:
+		return
 	}
 
-	fmt.Printf("Semantic Error: Found %s as the left hand side of an assignment.\n", assignStmt.Target.Name())
-	fmt.Println("Expected variable name or index expression.")
-	os.Exit(0)
+	semanticError(at.diagnostics, AssignTargetInvalid, assignStmt.Target, assignStmt.Target.Name())
 }
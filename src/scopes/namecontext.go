@@ -1,6 +1,10 @@
 package scopes
 
-import "chogopy/src/ast"
+import (
+	"chogopy/src/ast"
+	"chogopy/src/diagnostics"
+	"maps"
+)
 
 type NameContext struct {
 	names       map[string]*NameContext
@@ -14,6 +18,14 @@ func NewNameContext() NameContext {
 	}
 }
 
+// Clone returns a copy of the context to which names can be added without changing the original context.
+func (nc NameContext) Clone() NameContext {
+	return NameContext{
+		names:       maps.Clone(nc.names),
+		parentScope: nc.parentScope,
+	}
+}
+
 func (nc NameContext) contains(name string) bool {
 	_, nameInContext := nc.names[name]
 	return nameInContext
@@ -26,6 +38,15 @@ func (nc NameContext) parentScopeContains(name string) bool {
 	return false
 }
 
+// enclosingScopeContains checks whether the name is defined in the scope of an enclosing function.
+// In contrast to parentScopeContains(), the global scope is not taken into account.
+func (nc NameContext) enclosingScopeContains(name string) bool {
+	if nc.parentScope != nil && nc.parentScope.parentScope != nil {
+		return nc.parentScope.contains(name) || nc.parentScope.enclosingScopeContains(name)
+	}
+	return false
+}
+
 func (nc NameContext) globalScopeContains(name string) bool {
 	if nc.parentScope == nil {
 		return nc.contains(name)
@@ -33,34 +54,42 @@ func (nc NameContext) globalScopeContains(name string) bool {
 	return nc.parentScope.globalScopeContains(name)
 }
 
-func (nc NameContext) addVarName(name string) {
+// addVarName adds a variable name to the context.
+// It returns false without changing the context if the name has already been defined.
+func (nc NameContext) addVarName(name string) bool {
 	if nc.contains(name) {
-		semanticError(IdentifierAlreadyDefined, name)
+		return false
 	}
 	nc.names[name] = &NameContext{}
+	return true
 }
 
-func (nc NameContext) addFuncName(name string, funcContext NameContext) {
+// addFuncName adds a function name together with the context of its body to the context.
+// It returns false without changing the context if the name has already been defined.
+func (nc NameContext) addFuncName(name string, funcContext NameContext) bool {
 	if nc.contains(name) {
-		semanticError(IdentifierAlreadyDefined, name)
+		return false
 	}
 	nc.names[name] = &funcContext
+	return true
 }
 
 func (nc NameContext) getContext(name string) *NameContext {
-	if !nc.contains(name) {
-		semanticError(IdentifierUndefined, name)
-	}
 	return nc.names[name]
 }
 
 type NameContextBuilder struct {
 	NameContext NameContext
+	diagnostics *diagnostics.Sink
 	ast.BaseVisitor
 }
 
-func (nb *NameContextBuilder) Analyze(program *ast.Program) {
-	nb.NameContext = NewNameContext()
+// Analyze adds the names defined by the program to the NameContext, which is created if it has not been set yet.
+func (nb *NameContextBuilder) Analyze(program *ast.Program, sink *diagnostics.Sink) {
+	if nb.NameContext.names == nil {
+		nb.NameContext = NewNameContext()
+	}
+	nb.diagnostics = sink
 
 	for _, definition := range program.Definitions {
 		definition.Visit(nb)
@@ -73,10 +102,35 @@ func (nb *NameContextBuilder) Traverse() bool {
 
 func (nb *NameContextBuilder) VisitVarDef(varDef *ast.VarDef) {
 	varName := varDef.TypedVar.(*ast.TypedVar).VarName
-	nb.NameContext.addVarName(varName)
+	nb.addVarName(&nb.NameContext, varDef.TypedVar, varName)
 }
 
 func (nb *NameContextBuilder) VisitFuncDef(funcDef *ast.FuncDef) {
+	funcContext := nb.buildFuncContext(funcDef)
+	nb.addFuncName(&nb.NameContext, funcDef, funcDef.FuncName, funcContext)
+}
+
+// VisitClassDef registers the class name in the current context together with a class context
+// that contains the names of its attributes and methods. The class body does not open up a new scope
+// for the methods, which is why the contexts of the methods use the current context as their parent scope.
+func (nb *NameContextBuilder) VisitClassDef(classDef *ast.ClassDef) {
+	classContext := NewNameContext()
+
+	for _, classBodyNode := range classDef.ClassBody {
+		switch classBodyNode := classBodyNode.(type) {
+		case *ast.VarDef:
+			attrName := classBodyNode.TypedVar.(*ast.TypedVar).VarName
+			nb.addVarName(&classContext, classBodyNode, attrName)
+		case *ast.FuncDef:
+			methodContext := nb.buildFuncContext(classBodyNode)
+			nb.addFuncName(&classContext, classBodyNode, classBodyNode.FuncName, methodContext)
+		}
+	}
+
+	nb.addFuncName(&nb.NameContext, classDef, classDef.ClassName, classContext)
+}
+
+func (nb *NameContextBuilder) buildFuncContext(funcDef *ast.FuncDef) NameContext {
 	funcContext := NameContext{
 		names:       map[string]*NameContext{},
 		parentScope: &nb.NameContext,
@@ -84,22 +138,34 @@ func (nb *NameContextBuilder) VisitFuncDef(funcDef *ast.FuncDef) {
 
 	for _, param := range funcDef.Parameters {
 		paramName := param.(*ast.TypedVar).VarName
-		funcContext.addVarName(paramName)
+		nb.addVarName(&funcContext, param, paramName)
 	}
 
 	for _, funcBodyNode := range funcDef.FuncBody {
 		switch funcBodyNode := funcBodyNode.(type) {
 		case *ast.NonLocalDecl:
-			funcContext.addVarName(funcBodyNode.DeclName)
+			nb.addVarName(&funcContext, funcBodyNode, funcBodyNode.DeclName)
 		case *ast.GlobalDecl:
-			funcContext.addVarName(funcBodyNode.DeclName)
+			nb.addVarName(&funcContext, funcBodyNode, funcBodyNode.DeclName)
 		}
 	}
 
-	funcContextBuilder := &NameContextBuilder{NameContext: funcContext}
+	funcContextBuilder := &NameContextBuilder{NameContext: funcContext, diagnostics: nb.diagnostics}
 	for _, funcBodyNode := range funcDef.FuncBody {
 		funcBodyNode.Visit(funcContextBuilder)
 	}
 
-	nb.NameContext.addFuncName(funcDef.FuncName, funcContextBuilder.NameContext)
+	return funcContextBuilder.NameContext
+}
+
+func (nb *NameContextBuilder) addVarName(nameContext *NameContext, node ast.Node, name string) {
+	if !nameContext.addVarName(name) {
+		semanticError(nb.diagnostics, IdentifierAlreadyDefined, node, name)
+	}
+}
+
+func (nb *NameContextBuilder) addFuncName(nameContext *NameContext, node ast.Node, name string, funcContext NameContext) {
+	if !nameContext.addFuncName(name, funcContext) {
+		semanticError(nb.diagnostics, IdentifierAlreadyDefined, node, name)
+	}
 }
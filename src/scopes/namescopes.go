@@ -4,6 +4,7 @@ package scopes
 
 import (
 	"chogopy/src/ast"
+	"chogopy/src/diagnostics"
 )
 
 // TODO: This analysis pass is not yet complete since we have set its traverse property to false
@@ -12,17 +13,30 @@ import (
 
 type NameScopes struct {
 	NameContext *NameContext
+	diagnostics *diagnostics.Sink
 	ast.BaseVisitor
 }
 
-func (ns *NameScopes) Analyze(program *ast.Program) {
+// Analyze checks that every name used in the program has been defined in the right scope.
+// If the NameContext has already been set, e.g. by an earlier call to Analyze, the definitions
+// of the program are added to it instead of starting out with a new one.
+// This allows a program to be analyzed piece by piece.
+func (ns *NameScopes) Analyze(program *ast.Program, sink *diagnostics.Sink) {
+	ns.diagnostics = sink
+
 	NameContextBuilder := NameContextBuilder{}
-	NameContextBuilder.Analyze(program)
+	addBuiltins := ns.NameContext == nil
+	if !addBuiltins {
+		NameContextBuilder.NameContext = *ns.NameContext
+	}
+	NameContextBuilder.Analyze(program, sink)
 
 	ns.NameContext = &NameContextBuilder.NameContext
-	ns.NameContext.addFuncName("print", NewNameContext())
-	ns.NameContext.addFuncName("len", NewNameContext())
-	ns.NameContext.addFuncName("input", NewNameContext())
+	if addBuiltins {
+		NameContextBuilder.addFuncName(ns.NameContext, nil, "print", NewNameContext())
+		NameContextBuilder.addFuncName(ns.NameContext, nil, "len", NewNameContext())
+		NameContextBuilder.addFuncName(ns.NameContext, nil, "input", NewNameContext())
+	}
 
 	for _, definition := range program.Definitions {
 		definition.Visit(ns)
@@ -41,7 +55,7 @@ func (ns *NameScopes) VisitIdentExpr(identExpr *ast.IdentExpr) {
 
 	if !ns.NameContext.contains(identName) &&
 		!ns.NameContext.parentScopeContains(identName) {
-		semanticError(IdentifierUndefined, identName)
+		semanticError(ns.diagnostics, IdentifierUndefined, identExpr, identName)
 	}
 }
 
@@ -50,15 +64,49 @@ func (ns *NameScopes) VisitCallExpr(callExpr *ast.CallExpr) {
 
 	if !ns.NameContext.contains(funcName) &&
 		!ns.NameContext.parentScopeContains(funcName) {
-		semanticError(IdentifierUndefined, funcName)
+		semanticError(ns.diagnostics, IdentifierUndefined, callExpr, funcName)
 	}
 }
 
 func (ns *NameScopes) VisitFuncDef(funcDef *ast.FuncDef) {
 	funcName := funcDef.FuncName
-	funcContext := ns.NameContext.getContext(funcName)
+	funcContext := ns.getContext(ns.NameContext, funcDef, funcName)
+	if funcContext == nil {
+		return
+	}
+	ns.analyzeFuncBody(funcDef, funcContext)
+}
+
+func (ns *NameScopes) VisitClassDef(classDef *ast.ClassDef) {
+	className := classDef.ClassName
+	classContext := ns.getContext(ns.NameContext, classDef, className)
+	if classContext == nil {
+		return
+	}
+
+	for _, classBodyNode := range classDef.ClassBody {
+		if method, ok := classBodyNode.(*ast.FuncDef); ok {
+			methodContext := ns.getContext(classContext, method, method.FuncName)
+			if methodContext == nil {
+				continue
+			}
+			ns.analyzeFuncBody(method, methodContext)
+		}
+	}
+}
+
+// getContext returns the context of the function or class with the given name.
+// If the name is not defined in the given context, an error is reported and nil is returned.
+func (ns *NameScopes) getContext(nameContext *NameContext, node ast.Node, name string) *NameContext {
+	context := nameContext.getContext(name)
+	if context == nil {
+		semanticError(ns.diagnostics, IdentifierUndefined, node, name)
+	}
+	return context
+}
 
-	funcNameScopes := &NameScopes{NameContext: funcContext}
+func (ns *NameScopes) analyzeFuncBody(funcDef *ast.FuncDef, funcContext *NameContext) {
+	funcNameScopes := &NameScopes{NameContext: funcContext, diagnostics: ns.diagnostics}
 
 	for _, bodyNode := range funcDef.FuncBody {
 		bodyNode.Visit(funcNameScopes)
@@ -69,7 +117,7 @@ func (ns *NameScopes) VisitForStmt(forStmt *ast.ForStmt) {
 	iterName := forStmt.IterName
 
 	if !ns.NameContext.contains(iterName) {
-		semanticError(IdentifierUndefined, iterName)
+		semanticError(ns.diagnostics, IdentifierUndefined, forStmt, iterName)
 	}
 
 	forStmt.Iter.Visit(ns)
@@ -86,7 +134,7 @@ func (ns *NameScopes) VisitAssignStmt(assignStmt *ast.AssignStmt) {
 		identName := assignStmt.Target.(*ast.IdentExpr).Identifier
 
 		if !ns.NameContext.contains(identName) {
-			semanticError(AssignTargetOutOfScope, identName)
+			semanticError(ns.diagnostics, AssignTargetOutOfScope, assignStmt.Target, identName)
 		}
 	}
 }
@@ -94,8 +142,8 @@ func (ns *NameScopes) VisitAssignStmt(assignStmt *ast.AssignStmt) {
 func (ns *NameScopes) VisitNonLocalDecl(nonLocalDecl *ast.NonLocalDecl) {
 	declName := nonLocalDecl.DeclName
 
-	if !ns.NameContext.parentScopeContains(declName) {
-		semanticError(IdentifierNotInParentScope, declName)
+	if !ns.NameContext.enclosingScopeContains(declName) {
+		semanticError(ns.diagnostics, IdentifierNotInParentScope, nonLocalDecl, declName)
 	}
 }
 
@@ -103,6 +151,6 @@ func (ns *NameScopes) VisitGlobalDecl(globalDecl *ast.GlobalDecl) {
 	declName := globalDecl.DeclName
 
 	if !ns.NameContext.globalScopeContains(declName) {
-		semanticError(IdentifierNotInGlobalScope, declName)
+		semanticError(ns.diagnostics, IdentifierNotInGlobalScope, globalDecl, declName)
 	}
 }
@@ -1,8 +1,8 @@
 package scopes
 
 import (
-	"fmt"
-	"os"
+	"chogopy/src/ast"
+	"chogopy/src/diagnostics"
 )
 
 type NameScopeSemanticErrorKind int
@@ -13,20 +13,35 @@ const (
 	AssignTargetOutOfScope
 	IdentifierNotInParentScope
 	IdentifierNotInGlobalScope
+	AssignTargetInvalid
 )
 
-func semanticError(errorKind NameScopeSemanticErrorKind, name string) {
+// semanticError reports a name scope error at the location of the given node.
+// The node is nil for errors concerning the predefined functions, which do not have a location.
+func semanticError(sink *diagnostics.Sink, errorKind NameScopeSemanticErrorKind, node ast.Node, name string) {
+	span := diagnostics.Span{}
+	if node != nil {
+		span = node.Span()
+	}
+
 	switch errorKind {
 	case IdentifierAlreadyDefined:
-		fmt.Printf("Semantic Error: Identifier %s already defined in the current context.\n", name)
+		sink.Errorf("E201", span, "Identifier %s already defined in the current context.", name)
 	case IdentifierUndefined:
-		fmt.Printf("Semantic Error: Identifier %s used that was not previously defined.\n", name)
+		sink.Errorf("E202", span, "Identifier %s used that was not previously defined.", name)
 	case AssignTargetOutOfScope:
-		fmt.Printf("Semantic Error: Cannot assign to variable %s that was not declared in the current scope.\n", name)
+		sink.Errorf("E203", span, "Cannot assign to variable %s that was not declared in the current scope.", name)
 	case IdentifierNotInParentScope:
-		fmt.Printf("Semantic Error: Identifier %s not declared in valid parent scope.\n", name)
+		sink.Errorf("E204", span, "Identifier %s not declared in valid parent scope.", name)
 	case IdentifierNotInGlobalScope:
-		fmt.Printf("Semantic Error: Identifier %s not declared in the global scope.\n", name)
+		sink.Errorf("E205", span, "Identifier %s not declared in the global scope.", name)
+	case AssignTargetInvalid:
+		sink.Report(diagnostics.Diagnostic{
+			Severity: diagnostics.Error,
+			Code:     "E206",
+			Message:  "Found " + name + " as the left hand side of an assignment.",
+			Span:     span,
+			Notes:    []string{"Expected variable name, index expression, or member expression."},
+		})
 	}
-	os.Exit(0)
 }
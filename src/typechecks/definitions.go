@@ -5,15 +5,47 @@ import (
 	"maps"
 )
 
+func (st *StaticTyping) VisitClassDef(classDef *ast.ClassDef) {
+	classInfo, isClass := st.check(classDef, classDef.ClassName, false).(ClassInfo)
+	if !isClass {
+		return
+	}
+
+	for _, classBodyNode := range classDef.ClassBody {
+		switch classBodyNode := classBodyNode.(type) {
+		case *ast.VarDef:
+			attrName := classBodyNode.TypedVar.(*ast.TypedVar).VarName
+			attrType := classInfo.attributes[attrName]
+
+			classBodyNode.Literal.Visit(st)
+			literalType := st.visitedType
+
+			st.checkAssignmentCompatible(classBodyNode.Literal, literalType, attrType)
+
+		case *ast.FuncDef:
+			methodInfo := classInfo.methods[classBodyNode.FuncName]
+			st.checkFuncBody(classBodyNode, methodInfo)
+		}
+	}
+}
+
 func (st *StaticTyping) VisitFuncDef(funcDef *ast.FuncDef) {
-	funcInfo := st.localEnv.check(funcDef.FuncName, false)
+	funcInfo, isFunc := st.check(funcDef, funcDef.FuncName, false).(FunctionInfo)
+	if !isFunc {
+		return
+	}
+	st.checkFuncBody(funcDef, funcInfo)
+}
 
-	paramNames := funcInfo.(FunctionInfo).paramNames
-	paramTypes := funcInfo.(FunctionInfo).funcType.paramTypes
-	returnType := funcInfo.(FunctionInfo).funcType.returnType
-	nestedDefs := funcInfo.(FunctionInfo).nestedDefs
+// checkFuncBody type checks the body of a function or method in an environment
+// that is extended by the parameters and nested definitions of the function.
+func (st *StaticTyping) checkFuncBody(funcDef *ast.FuncDef, funcInfo FunctionInfo) {
+	paramNames := funcInfo.paramNames
+	paramTypes := funcInfo.funcType.paramTypes
+	returnType := funcInfo.funcType.returnType
+	nestedDefs := funcInfo.nestedDefs
 
-	extendedEnv := maps.Clone(st.localEnv)
+	extendedEnv := maps.Clone(st.LocalEnv)
 	for i := range len(paramNames) {
 		paramName := paramNames[i]
 		paramType := paramTypes[i]
@@ -26,8 +58,9 @@ func (st *StaticTyping) VisitFuncDef(funcDef *ast.FuncDef) {
 	}
 
 	funcBodyVisitor := &StaticTyping{
-		localEnv:   extendedEnv,
-		returnType: returnType,
+		LocalEnv:    extendedEnv,
+		returnType:  returnType,
+		diagnostics: st.diagnostics,
 	}
 	for _, funcBodyNode := range funcDef.FuncBody {
 		funcBodyNode.Visit(funcBodyVisitor)
@@ -40,10 +73,10 @@ func (st *StaticTyping) VisitNonLocalDecl(nonLocalDecl *ast.NonLocalDecl) {}
 
 func (st *StaticTyping) VisitVarDef(varDef *ast.VarDef) {
 	varName := varDef.TypedVar.(*ast.TypedVar).VarName
-	varType := st.localEnv.check(varName, true)
+	varType := st.check(varDef, varName, true)
 
 	varDef.Literal.Visit(st)
 	literalType := st.visitedType
 
-	checkAssignmentCompatible(literalType, varType)
+	st.checkAssignmentCompatible(varDef.Literal, literalType, varType)
 }
@@ -2,10 +2,12 @@ package typechecks
 
 import (
 	"chogopy/src/ast"
+	"chogopy/src/diagnostics"
+	"maps"
 )
 
 type DefType interface {
-	Type | FunctionInfo
+	Type | FunctionInfo | ClassInfo
 }
 
 type Definition struct {
@@ -19,39 +21,99 @@ type FunctionInfo struct {
 	nestedDefs []Definition
 }
 
+// ClassInfo describes the attributes and methods of a class.
+// This includes every attribute and method that the class inherits from its superclasses.
+type ClassInfo struct {
+	classType  ClassType
+	attributes map[string]Type
+	methods    map[string]FunctionInfo
+}
+
+// objectInit is the __init__ method of the object class which every class inherits from.
+var objectInit = FunctionInfo{
+	funcType:   FunctionType{paramTypes: []Type{objectType}, returnType: noneType},
+	paramNames: []string{"self"},
+	nestedDefs: []Definition{},
+}
+
 // LocalEnvironment associates every declared variable and function with their type.
 // It maps the names of the variables/functions to their type.
 type LocalEnvironment map[string]DefType
 
-func (le LocalEnvironment) check(defName string, expectVarDef bool) DefType {
-	defType, defExists := le[defName]
+// check looks up the definition with the given name in the local environment of the type checker.
+// If the name is not defined or does not refer to the expected kind of definition, an error is reported
+// and the object type is returned in place of a variable type or nil in place of a function definition.
+func (st *StaticTyping) check(node ast.Node, defName string, expectVarDef bool) DefType {
+	defType, defExists := st.LocalEnv[defName]
 	if !defExists {
-		semanticError(UnknownIdentifierUsed, nil, nil, defName, 0, 0)
+		semanticError(st.diagnostics, UnknownIdentifierUsed, node, nil, nil, defName, 0, 0)
+		return st.fallbackDef(expectVarDef)
 	}
 
+	// Class names are treated like function names since they can be called to construct a new object
 	_, isFuncInfo := defType.(FunctionInfo)
-	if isFuncInfo && expectVarDef {
-		semanticError(ExpectedVariableIdentifier, nil, nil, defName, 0, 0)
+	_, isClassInfo := defType.(ClassInfo)
+	if (isFuncInfo || isClassInfo) && expectVarDef {
+		semanticError(st.diagnostics, ExpectedVariableIdentifier, node, nil, nil, defName, 0, 0)
+		return st.fallbackDef(expectVarDef)
 	}
 
-	if !isFuncInfo && !expectVarDef {
-		semanticError(ExpectedFunctionIdentifier, nil, nil, defName, 0, 0)
+	if !isFuncInfo && !isClassInfo && !expectVarDef {
+		semanticError(st.diagnostics, ExpectedFunctionIdentifier, node, nil, nil, defName, 0, 0)
+		return st.fallbackDef(expectVarDef)
 	}
 
 	return defType
 }
 
+func (st *StaticTyping) fallbackDef(expectVarDef bool) DefType {
+	if expectVarDef {
+		return objectType
+	}
+	return nil
+}
+
 // EnvironmentBuilder is responsible for traversing the AST and constructing the above-defined
 // LocalEnvironment by checking every VarDef and FuncDef AST node
 type EnvironmentBuilder struct {
-	LocalEnv LocalEnvironment
+	LocalEnv    LocalEnvironment
+	classTypes  map[string]ClassType
+	diagnostics *diagnostics.Sink
 	ast.BaseVisitor
 }
 
-func (eb *EnvironmentBuilder) Build(program *ast.Program) {
-	eb.LocalEnv = LocalEnvironment{}
+// Build adds the definitions of the program to the LocalEnv.
+// If the LocalEnv has not been set yet, it starts out with the builtin functions.
+func (eb *EnvironmentBuilder) Build(program *ast.Program, sink *diagnostics.Sink) {
+	eb.diagnostics = sink
+	eb.classTypes = map[string]ClassType{}
+
+	if eb.LocalEnv != nil {
+		for _, defType := range eb.LocalEnv {
+			if classInfo, ok := defType.(ClassInfo); ok {
+				eb.classTypes[classInfo.classType.className] = classInfo.classType
+			}
+		}
+	} else {
+		eb.LocalEnv = newLocalEnvironment()
+	}
+
+	// The types of all classes are registered up front so that attributes, parameters,
+	// and return types are able to refer to classes that are defined further down in the program.
+	for _, definition := range program.Definitions {
+		if classDef, ok := definition.(*ast.ClassDef); ok {
+			eb.registerClassType(classDef)
+		}
+	}
 
-	eb.LocalEnv = LocalEnvironment{
+	for _, definition := range program.Definitions {
+		definition.Visit(eb)
+	}
+}
+
+// newLocalEnvironment creates a LocalEnvironment that contains nothing but the builtin functions.
+func newLocalEnvironment() LocalEnvironment {
+	return LocalEnvironment{
 		"len": FunctionInfo{
 			funcType:   FunctionType{paramTypes: []Type{objectType}, returnType: intType},
 			paramNames: []string{"arg"},
@@ -68,31 +130,128 @@ func (eb *EnvironmentBuilder) Build(program *ast.Program) {
 			nestedDefs: []Definition{},
 		},
 	}
+}
+
+// Traverse is disabled for the EnvironmentBuilder because only the definitions at the current level
+// should end up in its LocalEnvironment. Definitions inside of function bodies are collected by
+// a separate EnvironmentBuilder as nested definitions and the attributes and methods of classes are
+// collected into their ClassInfo.
+func (eb *EnvironmentBuilder) Traverse() bool {
+	return false
+}
+
+func (eb *EnvironmentBuilder) registerClassType(classDef *ast.ClassDef) {
+	var superClass Type = objectType
+	if classDef.SuperClass != "object" {
+		superClassType, superClassDefined := eb.classTypes[classDef.SuperClass]
+		if superClassDefined {
+			superClass = superClassType
+		} else {
+			semanticError(eb.diagnostics, SuperClassNotDefined, classDef, nil, nil, classDef.SuperClass, 0, 0)
+		}
+	}
 
-	program.Visit(eb)
+	eb.classTypes[classDef.ClassName] = ClassType{
+		className:  classDef.ClassName,
+		superClass: superClass,
+	}
+}
+
+func (eb *EnvironmentBuilder) VisitVarDef(varDef *ast.VarDef) {
+	varDef.TypedVar.Visit(eb)
 }
 
 func (eb *EnvironmentBuilder) VisitTypedVar(typedVar *ast.TypedVar) {
 	varName := typedVar.VarName
-	varType := typeFromNode(typedVar.VarType)
+	varType := eb.typeFromNode(typedVar.VarType)
 	eb.LocalEnv[varName] = varType
 }
 
+func (eb *EnvironmentBuilder) VisitClassDef(classDef *ast.ClassDef) {
+	classType := eb.classTypes[classDef.ClassName]
+
+	attributes := map[string]Type{}
+	methods := map[string]FunctionInfo{}
+
+	superClassInfo, superClassIsClass := eb.LocalEnv[classDef.SuperClass].(ClassInfo)
+	if superClassIsClass {
+		maps.Copy(attributes, superClassInfo.attributes)
+		maps.Copy(methods, superClassInfo.methods)
+	} else {
+		methods["__init__"] = objectInit
+	}
+
+	for _, classBodyNode := range classDef.ClassBody {
+		switch classBodyNode := classBodyNode.(type) {
+		case *ast.VarDef:
+			attrName := classBodyNode.TypedVar.(*ast.TypedVar).VarName
+			_, attrDefined := attributes[attrName]
+			_, methodDefined := methods[attrName]
+			if attrDefined || methodDefined {
+				semanticError(eb.diagnostics, AttributeRedefined, classBodyNode, nil, nil, attrName, 0, 0)
+			}
+			attributes[attrName] = eb.typeFromNode(classBodyNode.TypedVar.(*ast.TypedVar).VarType)
+
+		case *ast.FuncDef:
+			methodName := classBodyNode.FuncName
+			methodInfo := eb.funcInfo(classBodyNode)
+
+			if len(methodInfo.paramNames) == 0 ||
+				methodInfo.paramNames[0] != "self" ||
+				methodInfo.funcType.paramTypes[0] != classType {
+				semanticError(eb.diagnostics, MethodMissingSelf, classBodyNode, nil, nil, methodName, 0, 0)
+			}
+			if _, attrDefined := attributes[methodName]; attrDefined {
+				semanticError(eb.diagnostics, AttributeRedefined, classBodyNode, nil, nil, methodName, 0, 0)
+			}
+			if inheritedInfo, isInherited := methods[methodName]; isInherited &&
+				!isValidOverride(inheritedInfo.funcType, methodInfo.funcType) {
+				semanticError(eb.diagnostics, MethodOverrideMismatch, classBodyNode, nil, nil, methodName, 0, 0)
+			}
+
+			methods[methodName] = methodInfo
+		}
+	}
+
+	eb.LocalEnv[classDef.ClassName] = ClassInfo{
+		classType:  classType,
+		attributes: attributes,
+		methods:    methods,
+	}
+}
+
+// isValidOverride checks whether a method may override an inherited method.
+// This is the case if both methods have the same signature, disregarding the type of their self parameter.
+func isValidOverride(inherited FunctionType, overriding FunctionType) bool {
+	if len(inherited.paramTypes) != len(overriding.paramTypes) ||
+		inherited.returnType != overriding.returnType {
+		return false
+	}
+	for i := 1; i < len(inherited.paramTypes); i++ {
+		if inherited.paramTypes[i] != overriding.paramTypes[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func (eb *EnvironmentBuilder) VisitFuncDef(funcDef *ast.FuncDef) {
-	funcName := funcDef.FuncName
+	eb.LocalEnv[funcDef.FuncName] = eb.funcInfo(funcDef)
+}
 
+func (eb *EnvironmentBuilder) funcInfo(funcDef *ast.FuncDef) FunctionInfo {
 	paramNames := []string{}
 	paramTypes := []Type{}
 	for _, param := range funcDef.Parameters {
 		paramName := param.(*ast.TypedVar).VarName
-		paramType := typeFromNode(param.(*ast.TypedVar).VarType)
+		paramType := eb.typeFromNode(param.(*ast.TypedVar).VarType)
 		paramNames = append(paramNames, paramName)
 		paramTypes = append(paramTypes, paramType)
 	}
 
-	returnType := typeFromNode(funcDef.ReturnType)
+	returnType := eb.typeFromNode(funcDef.ReturnType)
 
-	nestedDefsBuilder := &EnvironmentBuilder{LocalEnv: LocalEnvironment{}}
+	nestedDefsBuilder := &EnvironmentBuilder{LocalEnv: LocalEnvironment{}, classTypes: eb.classTypes, diagnostics: eb.diagnostics}
 	for _, bodyNode := range funcDef.FuncBody {
 		bodyNode.Visit(nestedDefsBuilder)
 	}
@@ -103,7 +262,7 @@ func (eb *EnvironmentBuilder) VisitFuncDef(funcDef *ast.FuncDef) {
 		nestedDefs = append(nestedDefs, nestedDef)
 	}
 
-	eb.LocalEnv[funcName] = FunctionInfo{
+	return FunctionInfo{
 		funcType:   FunctionType{paramTypes: paramTypes, returnType: returnType},
 		paramNames: paramNames,
 		nestedDefs: nestedDefs,
@@ -20,7 +20,7 @@ func (st *StaticTyping) VisitLiteralExpr(literalExpr *ast.LiteralExpr) {
 }
 
 func (st *StaticTyping) VisitIdentExpr(identExpr *ast.IdentExpr) {
-	varType := st.localEnv.check(identExpr.Identifier, true)
+	varType := st.check(identExpr, identExpr.Identifier, true)
 	st.visitedType = varType
 	identExpr.TypeHint = attrFromType(st.visitedType)
 }
@@ -30,10 +30,10 @@ func (st *StaticTyping) VisitUnaryExpr(unaryExpr *ast.UnaryExpr) {
 
 	switch unaryExpr.Op {
 	case "-":
-		checkType(st.visitedType, intType)
+		st.checkType(unaryExpr.Value, st.visitedType, intType)
 		st.visitedType = intType
 	case "not":
-		checkType(st.visitedType, boolType)
+		st.checkType(unaryExpr.Value, st.visitedType, boolType)
 		st.visitedType = boolType
 	}
 	unaryExpr.TypeHint = attrFromType(st.visitedType)
@@ -54,14 +54,14 @@ func (st *StaticTyping) VisitBinaryExpr(binaryExpr *ast.BinaryExpr) {
 
 	switch binaryExpr.Op {
 	case "and":
-		checkType(lhsType, boolType)
-		checkType(rhsType, boolType)
+		st.checkType(binaryExpr.Lhs, lhsType, boolType)
+		st.checkType(binaryExpr.Rhs, rhsType, boolType)
 		st.visitedType = boolType
 		binaryExpr.TypeHint = attrFromType(st.visitedType)
 
 	case "or":
-		checkType(lhsType, boolType)
-		checkType(rhsType, boolType)
+		st.checkType(binaryExpr.Lhs, lhsType, boolType)
+		st.checkType(binaryExpr.Rhs, rhsType, boolType)
 		st.visitedType = boolType
 		binaryExpr.TypeHint = attrFromType(st.visitedType)
 
@@ -69,7 +69,7 @@ func (st *StaticTyping) VisitBinaryExpr(binaryExpr *ast.BinaryExpr) {
 		nonObjectTypes := []Type{intType, boolType, strType}
 		if slices.Contains(nonObjectTypes, lhsType) ||
 			slices.Contains(nonObjectTypes, rhsType) {
-			semanticError(IsBinaryExpectedTwoObjectTypes, nil, nil, "", 0, 0)
+			semanticError(st.diagnostics, IsBinaryExpectedTwoObjectTypes, binaryExpr, nil, nil, "", 0, 0)
 		}
 		st.visitedType = boolType
 		binaryExpr.TypeHint = attrFromType(st.visitedType)
@@ -87,8 +87,9 @@ func (st *StaticTyping) VisitBinaryExpr(binaryExpr *ast.BinaryExpr) {
 			binaryExpr.TypeHint = attrFromType(st.visitedType)
 			return
 		}
-		checkType(lhsType, intType)
-		checkType(rhsType, intType)
+		// The result is an int even if the operation overflows, since integer arithmetic wraps around
+		st.checkType(binaryExpr.Lhs, lhsType, intType)
+		st.checkType(binaryExpr.Rhs, rhsType, intType)
 		st.visitedType = intType
 		binaryExpr.TypeHint = attrFromType(st.visitedType)
 
@@ -108,8 +109,8 @@ func (st *StaticTyping) VisitBinaryExpr(binaryExpr *ast.BinaryExpr) {
 			binaryExpr.TypeHint = attrFromType(st.visitedType)
 			return
 		}
-		checkType(lhsType, intType)
-		checkType(rhsType, intType)
+		st.checkType(binaryExpr.Lhs, lhsType, intType)
+		st.checkType(binaryExpr.Rhs, rhsType, intType)
 		st.visitedType = boolType
 		binaryExpr.TypeHint = attrFromType(st.visitedType)
 	}
@@ -125,7 +126,7 @@ func (st *StaticTyping) VisitIfExpr(ifExpr *ast.IfExpr) {
 	ifExpr.ElseNode.Visit(st)
 	elseNodeType := st.visitedType
 
-	checkType(condType, boolType)
+	st.checkType(ifExpr.Condition, condType, boolType)
 	st.visitedType = join(ifNodeType, elseNodeType)
 	ifExpr.TypeHint = attrFromType(st.visitedType)
 }
@@ -155,15 +156,33 @@ func (st *StaticTyping) VisitListExpr(listExpr *ast.ListExpr) {
 
 func (st *StaticTyping) VisitCallExpr(callExpr *ast.CallExpr) {
 	funcName := callExpr.FuncName
-	funcInfo := st.localEnv.check(funcName, false)
+	funcInfo := st.check(callExpr, funcName, false)
+	if funcInfo == nil {
+		st.visitArguments(callExpr.Arguments)
+		st.visitedType = objectType
+		callExpr.TypeHint = attrFromType(st.visitedType)
+		return
+	}
 
-	if len(callExpr.Arguments) != len(funcInfo.(FunctionInfo).paramNames) {
-		semanticError(FunctionCallArgumentMismatch, nil, nil, "", len(funcInfo.(FunctionInfo).paramNames), len(callExpr.Arguments))
+	// Calling a class constructs a new object of that class.
+	// Constructors do not take any arguments since __init__ may only take self as its parameter.
+	if classInfo, isClass := funcInfo.(ClassInfo); isClass {
+		if len(callExpr.Arguments) != 0 {
+			semanticError(st.diagnostics, FunctionCallArgumentMismatch, callExpr, nil, nil, "", 0, len(callExpr.Arguments))
+		}
+		st.visitedType = classInfo.classType
+		callExpr.TypeHint = attrFromType(st.visitedType)
+		return
 	}
 
-	for argIdx, argument := range callExpr.Arguments {
-		argument.Visit(st)
-		checkAssignmentCompatible(st.visitedType, funcInfo.(FunctionInfo).funcType.paramTypes[argIdx])
+	if len(callExpr.Arguments) != len(funcInfo.(FunctionInfo).paramNames) {
+		semanticError(st.diagnostics, FunctionCallArgumentMismatch, callExpr, nil, nil, "", len(funcInfo.(FunctionInfo).paramNames), len(callExpr.Arguments))
+		st.visitArguments(callExpr.Arguments)
+	} else {
+		for argIdx, argument := range callExpr.Arguments {
+			argument.Visit(st)
+			st.checkAssignmentCompatible(argument, st.visitedType, funcInfo.(FunctionInfo).funcType.paramTypes[argIdx])
+		}
 	}
 
 	st.visitedType = funcInfo.(FunctionInfo).funcType.returnType
@@ -177,14 +196,106 @@ func (st *StaticTyping) VisitIndexExpr(indexExpr *ast.IndexExpr) {
 	indexExpr.Index.Visit(st)
 	indexType := st.visitedType
 
-	checkType(indexType, intType)
+	st.checkType(indexExpr.Index, indexType, intType)
 
 	if valueType == strType {
 		st.visitedType = strType
 		indexExpr.TypeHint = attrFromType(st.visitedType)
-	} else {
-		checkListType(valueType)
+	} else if st.checkListType(indexExpr.Value, valueType) {
 		st.visitedType = valueType.(ListType).elemType
 		indexExpr.TypeHint = attrFromType(st.visitedType)
+	} else {
+		st.visitedType = objectType
+		indexExpr.TypeHint = attrFromType(st.visitedType)
+	}
+}
+
+func (st *StaticTyping) VisitMemberExpr(memberExpr *ast.MemberExpr) {
+	st.checkMember(memberExpr, false)
+}
+
+// checkMember sets the visited type to the type of the attribute that the member expression refers to.
+// Methods can only be called, so reading or assigning to them is reported as such rather than as an unknown member.
+func (st *StaticTyping) checkMember(memberExpr *ast.MemberExpr, isTarget bool) {
+	memberExpr.Object.Visit(st)
+	classInfo, isClass := st.classInfo(memberExpr.Object, st.visitedType)
+
+	attrType, attrDefined := classInfo.attributes[memberExpr.MemberName]
+	if !attrDefined {
+		_, methodDefined := classInfo.methods[memberExpr.MemberName]
+		switch {
+		case !isClass:
+		case methodDefined && isTarget:
+			semanticError(st.diagnostics, MethodAssigned, memberExpr, classInfo.classType, nil, memberExpr.MemberName, 0, 0)
+		case methodDefined:
+			semanticError(st.diagnostics, MethodNotCalled, memberExpr, classInfo.classType, nil, memberExpr.MemberName, 0, 0)
+		default:
+			semanticError(st.diagnostics, UnknownMemberUsed, memberExpr, classInfo.classType, nil, memberExpr.MemberName, 0, 0)
+		}
+		attrType = bottomType
+	}
+
+	st.visitedType = attrType
+	memberExpr.TypeHint = attrFromType(st.visitedType)
+}
+
+func (st *StaticTyping) VisitMethodCallExpr(methodCallExpr *ast.MethodCallExpr) {
+	methodCallExpr.Receiver.Visit(st)
+	classInfo, isClass := st.classInfo(methodCallExpr.Receiver, st.visitedType)
+
+	methodInfo, methodDefined := classInfo.methods[methodCallExpr.MethodName]
+	if !methodDefined {
+		if isClass {
+			semanticError(st.diagnostics, UnknownMemberUsed, methodCallExpr, classInfo.classType, nil, methodCallExpr.MethodName, 0, 0)
+		}
+		st.visitArguments(methodCallExpr.Arguments)
+		st.visitedType = bottomType
+		methodCallExpr.TypeHint = attrFromType(st.visitedType)
+		return
+	}
+
+	// The receiver is passed as the self parameter which is why it is not part of the arguments
+	paramTypes := methodInfo.funcType.paramTypes[1:]
+	if len(methodCallExpr.Arguments) != len(paramTypes) {
+		semanticError(st.diagnostics, FunctionCallArgumentMismatch, methodCallExpr, nil, nil, "", len(paramTypes), len(methodCallExpr.Arguments))
+		st.visitArguments(methodCallExpr.Arguments)
+	} else {
+		for argIdx, argument := range methodCallExpr.Arguments {
+			argument.Visit(st)
+			st.checkAssignmentCompatible(argument, st.visitedType, paramTypes[argIdx])
+		}
+	}
+
+	st.visitedType = methodInfo.funcType.returnType
+	methodCallExpr.TypeHint = attrFromType(st.visitedType)
+}
+
+// classInfo returns the attributes and methods of the class that the given object type belongs to.
+// If the type is not a class type or the class is shadowed by another definition,
+// an error is reported and an empty ClassInfo is returned.
+func (st *StaticTyping) classInfo(node ast.Node, objectType Type) (ClassInfo, bool) {
+	// The error that caused the bottom type has already been reported
+	if objectType == bottomType {
+		return ClassInfo{}, false
+	}
+
+	classType, isClass := objectType.(ClassType)
+	if !isClass {
+		semanticError(st.diagnostics, ExpectedClassType, node, objectType, nil, "", 0, 0)
+		return ClassInfo{}, false
+	}
+
+	classInfo, isClassInfo := st.LocalEnv[classType.className].(ClassInfo)
+	if !isClassInfo {
+		semanticError(st.diagnostics, ClassNameShadowed, node, nil, nil, classType.className, 0, 0)
+		return ClassInfo{}, false
+	}
+	return classInfo, true
+}
+
+// visitArguments type checks the arguments of a call whose parameter types are unknown.
+func (st *StaticTyping) visitArguments(arguments []ast.Node) {
+	for _, argument := range arguments {
+		argument.Visit(st)
 	}
 }
@@ -1,8 +1,8 @@
 package typechecks
 
 import (
-	"fmt"
-	"os"
+	"chogopy/src/ast"
+	"chogopy/src/diagnostics"
 )
 
 type TypeSemanticErrorKind int
@@ -18,30 +18,62 @@ const (
 	IsBinaryExpectedTwoObjectTypes
 	FunctionCallArgumentMismatch
 	AssignTargetInvalid
+	UnknownTypeName
+	SuperClassNotDefined
+	AttributeRedefined
+	MethodMissingSelf
+	MethodOverrideMismatch
+	ExpectedClassType
+	UnknownMemberUsed
+	ClassNameShadowed
+	MethodAssigned
+	MethodNotCalled
 )
 
-func semanticError(errorKind TypeSemanticErrorKind, t1 Type, t2 Type, defName string, funcArgs int, callArgs int) {
+// semanticError reports a type error at the location of the given node.
+func semanticError(sink *diagnostics.Sink, errorKind TypeSemanticErrorKind, node ast.Node, t1 Type, t2 Type, defName string, funcArgs int, callArgs int) {
+	span := node.Span()
+
 	switch errorKind {
 	case NotAssignmentCompatible:
-		fmt.Printf("Semantic Error: %s is not assignment compatible with %s\n", nameFromType(t1), nameFromType(t2))
+		sink.Errorf("E301", span, "%s is not assignment compatible with %s", nameFromType(t1), nameFromType(t2))
 	case UnexpectedType:
-		fmt.Printf("Semantic Error: Expected %s but found %s\n", nameFromType(t1), nameFromType(t2))
+		sink.Errorf("E302", span, "Expected %s but found %s", nameFromType(t1), nameFromType(t2))
 	case ExpectedListType:
-		fmt.Printf("Semantic Error: Expected list type but found %s\n", nameFromType(t1))
+		sink.Errorf("E303", span, "Expected list type but found %s", nameFromType(t1))
 	case UnknownIdentifierUsed:
-		fmt.Printf("Semantic Error: Unknown identifier used: %s\n", defName)
+		sink.Errorf("E304", span, "Unknown identifier used: %s", defName)
 	case ExpectedVariableIdentifier:
-		fmt.Printf("Semantic Error: Found function identifier: %s but expected variable identifier\n", defName)
+		sink.Errorf("E305", span, "Found function identifier: %s but expected variable identifier", defName)
 	case ExpectedFunctionIdentifier:
-		fmt.Printf("Semantic Error: Found variable identifier: %s but expected function identifier\n", defName)
+		sink.Errorf("E306", span, "Found variable identifier: %s but expected function identifier", defName)
 	case ExpectedNonNoneListType:
-		fmt.Printf("Semantic Error: Expected non-none list type in asssignment\n")
+		sink.Errorf("E307", span, "Expected non-none list type in asssignment")
 	case IsBinaryExpectedTwoObjectTypes:
-		fmt.Printf("Semantic Error: Expected both operands to be of object type\n")
+		sink.Errorf("E308", span, "Expected both operands to be of object type")
 	case FunctionCallArgumentMismatch:
-		fmt.Printf("Semantic Error: Expected %d arguments but got %d\n", funcArgs, callArgs)
+		sink.Errorf("E309", span, "Expected %d arguments but got %d", funcArgs, callArgs)
 	case AssignTargetInvalid:
-		fmt.Printf("Semantic Error: Cannot assign to non-identifier or index expression\n")
+		sink.Errorf("E310", span, "Cannot assign to non-identifier, index, or member expression")
+	case UnknownTypeName:
+		sink.Errorf("E311", span, "Unknown type name: %s", defName)
+	case SuperClassNotDefined:
+		sink.Errorf("E312", span, "Superclass %s is not a previously defined class", defName)
+	case AttributeRedefined:
+		sink.Errorf("E313", span, "Cannot redefine attribute: %s", defName)
+	case MethodMissingSelf:
+		sink.Errorf("E314", span, "First parameter of method %s must be of the enclosing class type", defName)
+	case MethodOverrideMismatch:
+		sink.Errorf("E315", span, "Method %s overrides an inherited method with a different signature", defName)
+	case ExpectedClassType:
+		sink.Errorf("E316", span, "Expected object of a class type but found %s", nameFromType(t1))
+	case UnknownMemberUsed:
+		sink.Errorf("E317", span, "%s has no attribute or method named %s", nameFromType(t1), defName)
+	case ClassNameShadowed:
+		sink.Errorf("E318", span, "Class %s is shadowed by a variable or function of the same name", defName)
+	case MethodAssigned:
+		sink.Errorf("E319", span, "Cannot assign to method %s of %s", defName, nameFromType(t1))
+	case MethodNotCalled:
+		sink.Errorf("E320", span, "Method %s of %s can only be called", defName, nameFromType(t1))
 	}
-	os.Exit(0)
 }
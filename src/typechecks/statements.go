@@ -4,7 +4,7 @@ import "chogopy/src/ast"
 
 func (st *StaticTyping) VisitIfStmt(ifStmt *ast.IfStmt) {
 	ifStmt.Condition.Visit(st)
-	checkType(st.visitedType, boolType)
+	st.checkType(ifStmt.Condition, st.visitedType, boolType)
 
 	for _, ifBodyNode := range ifStmt.IfBody {
 		ifBodyNode.Visit(st)
@@ -16,7 +16,7 @@ func (st *StaticTyping) VisitIfStmt(ifStmt *ast.IfStmt) {
 
 func (st *StaticTyping) VisitWhileStmt(whileStmt *ast.WhileStmt) {
 	whileStmt.Condition.Visit(st)
-	checkType(st.visitedType, boolType)
+	st.checkType(whileStmt.Condition, st.visitedType, boolType)
 
 	for _, bodyNode := range whileStmt.Body {
 		bodyNode.Visit(st)
@@ -26,14 +26,13 @@ func (st *StaticTyping) VisitWhileStmt(whileStmt *ast.WhileStmt) {
 func (st *StaticTyping) VisitForStmt(forStmt *ast.ForStmt) {
 	forStmt.Iter.Visit(st)
 	iterType := st.visitedType
-	iterNameType := st.localEnv.check(forStmt.IterName, true)
+	iterNameType := st.check(forStmt, forStmt.IterName, true)
 
 	if iterType == strType {
-		checkAssignmentCompatible(strType, iterNameType)
-	} else {
-		checkListType(iterType)
+		st.checkAssignmentCompatible(forStmt, strType, iterNameType)
+	} else if st.checkListType(forStmt.Iter, iterType) {
 		elemType := iterType.(ListType).elemType
-		checkAssignmentCompatible(elemType, iterNameType)
+		st.checkAssignmentCompatible(forStmt, elemType, iterNameType)
 	}
 
 	for _, bodyNode := range forStmt.Body {
@@ -51,7 +50,7 @@ func (st *StaticTyping) VisitReturnStmt(returnStmt *ast.ReturnStmt) {
 	} else {
 		returnType = noneType
 	}
-	checkAssignmentCompatible(returnType, st.returnType)
+	st.checkAssignmentCompatible(returnStmt, returnType, st.returnType)
 }
 
 func (st *StaticTyping) VisitAssignStmt(assignStmt *ast.AssignStmt) {
@@ -67,8 +66,9 @@ func (st *StaticTyping) VisitAssignStmt(assignStmt *ast.AssignStmt) {
 		for valueIsAssign {
 			_, targetIsIdent := currentAssign.Target.(*ast.IdentExpr)
 			_, targetIsIndex := currentAssign.Target.(*ast.IndexExpr)
-			if !targetIsIdent && !targetIsIndex {
-				semanticError(AssignTargetInvalid, nil, nil, "", 0, 0)
+			_, targetIsMember := currentAssign.Target.(*ast.MemberExpr)
+			if !targetIsIdent && !targetIsIndex && !targetIsMember {
+				semanticError(st.diagnostics, AssignTargetInvalid, currentAssign.Target, nil, nil, "", 0, 0)
 			}
 
 			currentAssign = assignStmt.Value.(*ast.AssignStmt)
@@ -86,20 +86,24 @@ func (st *StaticTyping) VisitAssignStmt(assignStmt *ast.AssignStmt) {
 
 		_, lastNodeIsList := lastNodeType.(ListType)
 		if lastNodeIsList && lastNodeType.(ListType).elemType == noneType {
-			semanticError(ExpectedNonNoneListType, nil, nil, "", 0, 0)
+			semanticError(st.diagnostics, ExpectedNonNoneListType, assignNodes[len(assignNodes)-1], nil, nil, "", 0, 0)
 		}
 
 		for _, assignNode := range assignNodes[:len(assignNodes)-1] {
-			assignNode.Visit(st)
+			if memberExpr, isMember := assignNode.(*ast.MemberExpr); isMember {
+				st.checkMember(memberExpr, true)
+			} else {
+				assignNode.Visit(st)
+			}
 			assignNodeType := st.visitedType
-			checkAssignmentCompatible(lastNodeType, assignNodeType)
+			st.checkAssignmentCompatible(assignNode, lastNodeType, assignNodeType)
 		}
 
 		// Substep 3: Type hints are added for each node in the assignment chain
 		for _, assignNode := range assignNodes {
 			switch assignNode := assignNode.(type) {
 			case *ast.IdentExpr:
-				identType := st.localEnv.check(assignNode.Identifier, true)
+				identType := st.check(assignNode, assignNode.Identifier, true)
 				assignNode.TypeHint = attrFromType(identType)
 			case *ast.IndexExpr:
 				assignNode.Value.Visit(st)
@@ -113,12 +117,12 @@ func (st *StaticTyping) VisitAssignStmt(assignStmt *ast.AssignStmt) {
 	// Case 2: Assign to an identifier like: a = 1
 	case *ast.IdentExpr:
 		identName := target.Identifier
-		identType := st.localEnv.check(identName, true)
+		identType := st.check(target, identName, true)
 
 		assignStmt.Value.Visit(st)
 		valueType := st.visitedType
 
-		checkAssignmentCompatible(valueType, identType)
+		st.checkAssignmentCompatible(assignStmt.Value, valueType, identType)
 
 		target.TypeHint = attrFromType(identType)
 
@@ -126,20 +130,32 @@ func (st *StaticTyping) VisitAssignStmt(assignStmt *ast.AssignStmt) {
 	case *ast.IndexExpr:
 		target.Value.Visit(st)
 		targetValueType := st.visitedType
-		checkListType(targetValueType)
+		targetIsList := st.checkListType(target.Value, targetValueType)
 
 		target.Index.Visit(st)
 		targetIndexType := st.visitedType
-		checkType(targetIndexType, intType)
+		st.checkType(target.Index, targetIndexType, intType)
 
 		assignStmt.Value.Visit(st)
 		valueType := st.visitedType
-		checkAssignmentCompatible(valueType, targetValueType.(ListType).elemType)
+		if targetIsList {
+			st.checkAssignmentCompatible(assignStmt.Value, valueType, targetValueType.(ListType).elemType)
+		}
 
 		target.TypeHint = attrFromType(targetValueType)
 
+	// Case 4: Assign to an attribute like: a.b = 1
+	case *ast.MemberExpr:
+		st.checkMember(target, true)
+		attrType := st.visitedType
+
+		assignStmt.Value.Visit(st)
+		valueType := st.visitedType
+
+		st.checkAssignmentCompatible(assignStmt.Value, valueType, attrType)
+
 	// Assigning to anything that doesn't represent an identifier / index expression is illegal
 	default:
-		semanticError(AssignTargetInvalid, nil, nil, "", 0, 0)
+		semanticError(st.diagnostics, AssignTargetInvalid, assignStmt.Target, nil, nil, "", 0, 0)
 	}
 }
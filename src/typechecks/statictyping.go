@@ -1,15 +1,23 @@
 // Package typechecks provides methods for ensuring
 // that a parsed program fulfills type constraints
+//
+// Arithmetic on integers always has the type int. Integers are 32 bits wide and every
+// operation wraps around on overflow at runtime, so overflow is never a type error.
 package typechecks
 
 import (
 	"chogopy/src/ast"
+	"chogopy/src/diagnostics"
 )
 
 type StaticTyping struct {
-	localEnv    LocalEnvironment
+	// LocalEnv holds the types of every definition that is visible to the type checker.
+	// If it is set before Analyze is called, e.g. by an earlier call to Analyze,
+	// the definitions of the program are added to it instead of starting out with a new one.
+	LocalEnv    LocalEnvironment
 	returnType  Type
 	visitedType Type
+	diagnostics *diagnostics.Sink
 	ast.BaseVisitor
 }
 
@@ -17,11 +25,13 @@ type StaticTyping struct {
 // chapter 5 of the chocopy language reference:
 //
 // https://chocopy.org/chocopy_language_reference.pdf
-func (st *StaticTyping) Analyze(program *ast.Program) {
-	envBuilder := EnvironmentBuilder{}
-	envBuilder.Build(program)
+func (st *StaticTyping) Analyze(program *ast.Program, sink *diagnostics.Sink) {
+	st.diagnostics = sink
+
+	envBuilder := EnvironmentBuilder{LocalEnv: st.LocalEnv}
+	envBuilder.Build(program, sink)
 
-	st.localEnv = envBuilder.LocalEnv
+	st.LocalEnv = envBuilder.LocalEnv
 	st.returnType = bottomType
 
 	for _, definition := range program.Definitions {
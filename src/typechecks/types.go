@@ -3,7 +3,6 @@ package typechecks
 import (
 	"chogopy/src/ast"
 	"fmt"
-	"log"
 )
 
 type Type any
@@ -28,6 +27,15 @@ type ObjectType struct {
 	Type
 }
 
+// ClassType represents a user-defined class.
+// Since every class knows the type of its superclass, the class hierarchy can be
+// traversed by following the chain of superclasses up until the object type.
+type ClassType struct {
+	className  string
+	superClass Type
+	Type
+}
+
 type FunctionType struct {
 	paramTypes []Type
 	returnType Type
@@ -43,7 +51,9 @@ var (
 	objectType = ObjectType{typeName: "object"}
 )
 
-func typeFromNode(node ast.Node) Type {
+// typeFromNode converts a type annotation into the type it denotes.
+// Unknown type names are reported and treated as the object type.
+func (eb *EnvironmentBuilder) typeFromNode(node ast.Node) Type {
 	switch node := node.(type) {
 	case *ast.NamedType:
 		switch node.TypeName {
@@ -60,14 +70,18 @@ func typeFromNode(node ast.Node) Type {
 		case "object":
 			return objectType
 		}
+		if classType, ok := eb.classTypes[node.TypeName]; ok {
+			return classType
+		}
+		semanticError(eb.diagnostics, UnknownTypeName, node, nil, nil, node.TypeName, 0, 0)
+		return objectType
 
 	case *ast.ListType:
-		elemType := typeFromNode(node.ElemType)
+		elemType := eb.typeFromNode(node.ElemType)
 		return ListType{elemType: elemType}
 	}
 
-	log.Fatalf("Expected Node but found %# v", node)
-	return nil
+	panic(fmt.Sprintf("Expected Node but found %# v", node))
 }
 
 func attrFromType(nodeType Type) ast.TypeAttr {
@@ -84,6 +98,10 @@ func attrFromType(nodeType Type) ast.TypeAttr {
 		return ast.Empty
 	case objectType:
 		return ast.Object
+	case bottomType:
+		// Only expressions whose type could not be determined due to an error have the bottom type.
+		// Their type hint is never used since programs with errors are not compiled.
+		return ast.Object
 	}
 
 	_, isListType := nodeType.(ListType)
@@ -92,8 +110,11 @@ func attrFromType(nodeType Type) ast.TypeAttr {
 		return ast.ListAttribute{ElemType: elemType}
 	}
 
-	log.Fatalf("Expected Type but found %# v", nodeType)
-	return nil
+	if classType, isClassType := nodeType.(ClassType); isClassType {
+		return ast.ClassAttribute{ClassName: classType.className}
+	}
+
+	panic(fmt.Sprintf("Expected Type but found %# v", nodeType))
 }
 
 func nameFromType(nodeType Type) string {
@@ -120,8 +141,11 @@ func nameFromType(nodeType Type) string {
 		return fmt.Sprintf("List[%s]", elemType)
 	}
 
-	log.Fatalf("Expected Type but found %# v", nodeType)
-	return ""
+	if classType, isClassType := nodeType.(ClassType); isClassType {
+		return classType.className
+	}
+
+	panic(fmt.Sprintf("Expected Type but found %# v", nodeType))
 }
 
 func join(t1 Type, t2 Type) Type {
@@ -131,6 +155,17 @@ func join(t1 Type, t2 Type) Type {
 	if isAssignmentCompatible(t2, t1) {
 		return t1
 	}
+
+	// The join of two classes is their least common ancestor in the class hierarchy
+	if _, t2IsClass := t2.(ClassType); t2IsClass {
+		ancestor, isClass := t1.(ClassType)
+		for isClass {
+			if isSubType(t2, ancestor) {
+				return ancestor
+			}
+			ancestor, isClass = ancestor.superClass.(ClassType)
+		}
+	}
 	return objectType
 }
 
@@ -149,6 +184,11 @@ func isSubType(t1 Type, t2 Type) bool {
 	case t1 == bottomType:
 		return true
 	}
+
+	// A class is a subtype of every class in its chain of superclasses
+	if classType, t1IsClass := t1.(ClassType); t1IsClass {
+		return isSubType(classType.superClass, t2)
+	}
 	return false
 }
 
@@ -171,21 +211,26 @@ func isAssignmentCompatible(t1 Type, t2 Type) bool {
 	return false
 }
 
-func checkAssignmentCompatible(t1 Type, t2 Type) {
-	if !isAssignmentCompatible(t1, t2) {
-		semanticError(NotAssignmentCompatible, t1, t2, "", 0, 0)
+// checkAssignmentCompatible reports an error if t1 is not assignment compatible with t2.
+// Values and targets of the bottom type are accepted, since the error that caused them has already been reported.
+func (st *StaticTyping) checkAssignmentCompatible(node ast.Node, t1 Type, t2 Type) {
+	if t1 != bottomType && t2 != bottomType && !isAssignmentCompatible(t1, t2) {
+		semanticError(st.diagnostics, NotAssignmentCompatible, node, t1, t2, "", 0, 0)
 	}
 }
 
-func checkType(found Type, expected Type) {
-	if found != expected {
-		semanticError(UnexpectedType, expected, found, "", 0, 0)
+func (st *StaticTyping) checkType(node ast.Node, found Type, expected Type) {
+	if found != expected && found != bottomType {
+		semanticError(st.diagnostics, UnexpectedType, node, expected, found, "", 0, 0)
 	}
 }
 
-func checkListType(found Type) {
+// checkListType reports an error if the found type is not a list type.
+// Its result tells the caller whether it is safe to access the element type of the found type.
+func (st *StaticTyping) checkListType(node ast.Node, found Type) bool {
 	_, foundIsList := found.(ListType)
-	if !foundIsList {
-		semanticError(ExpectedListType, found, nil, "", 0, 0)
+	if !foundIsList && found != bottomType {
+		semanticError(st.diagnostics, ExpectedListType, node, found, nil, "", 0, 0)
 	}
+	return foundIsList
 }